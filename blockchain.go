@@ -1,13 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"github.com/liangalv/goChain/core"
-	"github.com/liangalv/goChain/core/services"
-	"google.golang.org/grpc"
+	"github.com/liangalv/goChain/structures"
 	"log"
-	"net"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 func init() {
@@ -15,47 +18,868 @@ func init() {
 }
 
 func main() {
-	//Set up the server
-	lis, err := net.Listen("tcp", "9000")
+	//Subcommands work on the store of a stopped node
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			migrate(os.Args[2:])
+			return
+		case "export":
+			exportChain(os.Args[2:])
+			return
+		case "import":
+			importChain(os.Args[2:])
+			return
+		}
+	}
+	cfg := parseFlags()
+	store, err := core.OpenBlockStore(cfg.DataDir)
 	if err != nil {
-		log.Fatalf("Failed to open port 9000: %v", err)
+		log.Fatalf("Failed to open block store: %v", err)
 	}
-	//Instantiate services
-	//TODO: separate networking concerns to network.go
-	ts := services.TransactionService{}
-
-	grpcServer := grpc.NewServer()
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve grpcServer over port 9000: %v", err)
+	defer store.Close()
+	events := core.NewEventBus()
+	//A light node keeps headers only and answers queries with proofs from full peers
+	if cfg.SyncMode == "light" {
+		if err := checkStateMode(store, core.StateConfig{Mode: core.HeadersOnly}); err != nil {
+			log.Fatalf("Failed to open light chain: %v", err)
+		}
+		lc := NewLightChain(store, events)
+		node, err := startP2P(cfg, lc, lightProtocols(lc, events))
+		if err != nil {
+			log.Fatalf("Failed to start p2p server on %s: %v", cfg.P2PAddr, err)
+		}
+		defer node.Stop()
+		if err := serveAPI(cfg, lc, events, node); err != nil {
+			log.Fatalf("Failed to serve API: %v", err)
+		}
+		return
 	}
-	//check the network for any blockchain that being broadcasted, if so sync node's embedded db
-	//else read from db and spin up bc state
-	// bc := NewBlockChain()
+	if err := checkStateMode(store, cfg.State); err != nil {
+		log.Fatalf("Failed to open state: %v", err)
+	}
+	//Read from db and spin up bc state, a fresh node starts from genesis
+	bc, err := NewBlockChain(store, events)
+	if err != nil {
+		log.Fatalf("Failed to open chain: %v", err)
+	}
+	defer bc.Close()
 
+	//The p2p server's Syncer catches the node up with the network from whatever head it starts at
+	node, err := startP2P(cfg, bc, fullProtocols(cfg, bc, events))
+	if err != nil {
+		log.Fatalf("Failed to start p2p server on %s: %v", cfg.P2PAddr, err)
+	}
+	defer node.Stop()
+	if cfg.BlockTime > 0 {
+		go bc.produceBlocks(cfg.BlockTime)
+	}
+	if err := serveAPI(cfg, bc, events, node); err != nil {
+		log.Fatalf("Failed to serve API: %v", err)
+	}
 }
 
+const (
+	//ChainID distinguishes this network from any other running goChain
+	ChainID = 1337
+	//Most transactions that fit under the block gas limit
+	maxBlockTransactions = core.GASLIMIT / core.TxGas
+	//Number of recent canonical states kept around to serve snap syncing peers unless -statehistory says otherwise. A
+	//pruned chain keeps the state trees of as many blocks and prunes the rest every as many blocks
+	defaultStateHistory = 128
+	//A pruned chain also keeps the state tree of every defaultStateCheckpoint'th block, unless -checkpoint says otherwise
+	defaultStateCheckpoint = 1024
+	//Most logs or transactions a single search of the chain returns
+	maxFilterResults = 10000
+	//How deep the last block of a section has to be before the section's blooms are indexed, so that reorgs seldom
+	//have to rewind the index
+	bloomConfirmations = 256
+	//Deepest below the head a side chain may leave the canonical chain. Every side chain block is executed on a state
+	//replayed from genesis and stored for good, so peers can't have that done for forks of long settled blocks
+	maxForkDepth = 128
+)
+
+// GenesisAlloc funds accounts in the state the genesis block is executed against, devnets can seed balances here
+var GenesisAlloc = map[[core.AddressLength]byte]uint64{}
+
 type BlockChain struct {
+	mux      sync.RWMutex
+	chainID  uint64
 	accounts []*core.Account
-	memPool  []*core.Transaction
+	memPool  *core.MemPool
 	chain    []*core.Block
+	state    *core.State //state as of the canonical head
+	store    *core.BlockStore
+	events   *core.EventBus
+
+	//States as of the most recent canonical blocks, oldest first and ending with state. States are never modified
+	//once they are a block's state, the head's state is copied before the next block is executed
+	states []*core.State
+	//Block a snap synced chain's state was downloaded at and the state as of it, nil if every block was imported.
+	//Blocks up to the snapshot are headers only
+	snapshot      *core.Block
+	snapshotState *core.State
+	//Keep the state tree of every block rather than pruning old ones, as recorded in the store
+	archive bool
+	//Recent states kept and how far apart the checkpoints of a pruned chain are, as recorded in the store
+	stateHistory    int
+	stateCheckpoint int
+	//Set while a prune runs, so a second one doesn't start alongside it
+	pruning atomic.Bool
+	prunes  sync.WaitGroup
+
+	//MountainRange of the canonical blocks' hashes, the next block commits to its root. It's rebuilt from the chain on
+	//start and after a reorg, at one append per block, so its nodes are only kept in memory
+	history      *structures.MountainRange
+	historyNodes structures.NodeStore
 }
 
 // Todo: we are only taking a slice of the underlying blockchain, this genesis block should only be called once
-func NewBlockChain() *BlockChain {
-	bc := new(BlockChain)
-	//Generates an Empty Block as the prevHash of the Genesis Block
-	bc.CreateBlock((&core.Block{}).Hash(), make([]*core.Transaction, 0, 1000))
-	return bc
+// Errors loading the chain are returned rather than fatal, so callers can close what they opened first
+func NewBlockChain(store *core.BlockStore, events *core.EventBus) (*BlockChain, error) {
+	bc := &BlockChain{
+		chainID: ChainID,
+		memPool: core.NewMemPool(nil, events),
+		state:   core.NewStateWithNodes(store.Nodes(), GenesisAlloc),
+		store:   store,
+		events:  events,
+	}
+	bc.historyNodes = structures.NewMemNodeStore()
+	bc.history = structures.NewMountainRange(bc.historyNodes)
+	stateCfg, err := store.ReadStateConfig()
+	if err != nil && err != core.ErrNotFound {
+		return nil, fmt.Errorf("failed to read state mode: %w", err)
+	}
+	stateCfg = withStateDefaults(stateCfg)
+	bc.archive = stateCfg.Mode == core.ArchiveState
+	bc.stateHistory, bc.stateCheckpoint = int(stateCfg.History), int(stateCfg.Checkpoint)
+	//Spin up from the canonical chain in the store if there is one
+	if err := bc.loadChain(); err == nil {
+		return bc, nil
+	} else if err != core.ErrNotFound {
+		return nil, fmt.Errorf("failed to load chain from block store: %w", err)
+	}
+	bc.mux.Lock()
+	defer bc.mux.Unlock()
+	if err := bc.commitBlock(core.NewGenesisBlock()); err != nil {
+		return nil, fmt.Errorf("failed to commit the genesis block: %w", err)
+	}
+	//The genesis block has no transactions to say which accounts the alloc funded
+	bc.writeFlatState(nil)
+	return bc, nil
+}
+
+// CreateBlock seals trans into a new block on top of the head, prevHash must be the current head
+func (bc *BlockChain) CreateBlock(prevHash [32]byte, trans []*core.Transaction) (*core.Block, error) {
+	bc.mux.Lock()
+	defer bc.mux.Unlock()
+	head := bc.chain[len(bc.chain)-1]
+	if prevHash != head.ID {
+		return nil, errors.New("blocks can only be created on top of the head")
+	}
+	b := core.NewBlock(prevHash, head.Height()+1, trans)
+	if err := bc.commitBlock(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// InsertSnapshot makes the last of headers the head with state as its state, instead of importing every block up to
+// it. headers must follow on from the genesis block of a chain that has nothing else yet
+func (bc *BlockChain) InsertSnapshot(headers []*core.Block, state *core.State) error {
+	bc.mux.Lock()
+	defer bc.mux.Unlock()
+	if len(bc.chain) != 1 {
+		return errors.New("snapshots can only be inserted into a fresh chain")
+	}
+	if len(headers) == 0 || headers[0].ParentHash() != bc.chain[0].ID {
+		return errors.New("snapshot headers do not follow the genesis block")
+	}
+	pivot := headers[len(headers)-1]
+	if root := state.Root(); root != pivot.StateRoot() {
+		return fmt.Errorf("snapshot state root %x does not match block %d", root[:4], pivot.Height())
+	}
+	history, err := bc.replayHistory(append(bc.chain[:1:1], headers...))
+	if err != nil {
+		return err
+	}
+	if err := bc.store.CommitSnapshot(headers, state); err != nil {
+		return err
+	}
+	state = state.CopyTo(bc.store.Nodes())
+	bc.chain = append(bc.chain, headers...)
+	bc.snapshot, bc.snapshotState = pivot, state
+	bc.state, bc.states = state, []*core.State{state}
+	bc.history = history
+	bc.writeFlatState(nil)
+	bc.indexBlooms()
+	bc.events.Publish(core.Event{Type: core.NewHeadEvent, Block: pivot})
+	return nil
+}
+
+// InsertBlock validates a block received from another node and adds it to the chain.
+// A block extending a side chain is stored and its branch becomes canonical once it has more total difficulty
+func (bc *BlockChain) InsertBlock(b *core.Block) error {
+	bc.mux.Lock()
+	defer bc.mux.Unlock()
+	if _, err := bc.store.ReadBlock(b.ID); err == nil {
+		return core.ErrKnownBlock
+	}
+	parent, err := bc.store.ReadBlock(b.ParentHash())
+	if err != nil {
+		return core.ErrUnknownParent
+	}
+	if err := b.VerifyHeader(parent); err != nil {
+		return err
+	}
+	//The fork is at most as high as the parent, which saves walking a side chain that can only be too deep
+	if parent.Height()+1+maxForkDepth < uint64(len(bc.chain)) {
+		return core.ErrStaleFork
+	}
+	if parent.ID == bc.chain[len(bc.chain)-1].ID {
+		if err := bc.verifyNewTransactions(b, b.Height(), nil); err != nil {
+			return err
+		}
+		if err := b.VerifyHistory(bc.history.Root()); err != nil {
+			return err
+		}
+		state := bc.state.Copy()
+		receipts, err := b.VerifyBody(state)
+		if err != nil {
+			return err
+		}
+		return bc.appendBlock(b, state, receipts)
+	}
+	branch, err := bc.sideBranch(parent)
+	if err != nil {
+		return err
+	}
+	//Heights are contiguous so the branch leaves the canonical chain just below its first block
+	fork := b.Height() - uint64(len(branch))
+	if uint64(len(bc.chain))-fork > maxForkDepth {
+		return core.ErrStaleFork
+	}
+	if err := bc.verifyNewTransactions(b, fork, branch); err != nil {
+		return err
+	}
+	//TODO: replaying from genesis is O(chain), keep state per block so side chains can start from the fork
+	base := bc.chain[:fork:fork]
+	ancestors := append(base, branch...)
+	history, err := bc.replayHistory(ancestors)
+	if err != nil {
+		return err
+	}
+	if err := b.VerifyHistory(history.Root()); err != nil {
+		return err
+	}
+	state, err := bc.replayState(ancestors)
+	if err != nil {
+		return err
+	}
+	receipts, err := b.VerifyBody(state)
+	if err != nil {
+		return err
+	}
+	if err := bc.store.WriteBlock(b, receipts); err != nil {
+		return err
+	}
+	branch = append(branch, b)
+	if totalDifficulty(base)+totalDifficulty(branch) <= totalDifficulty(bc.chain) {
+		return nil
+	}
+	return bc.reorg(branch)
+}
+
+// Reject a block that includes a transaction twice or one an ancestor already includes, which would execute it again.
+// The ancestors are the canonical blocks below fork followed by branch, caller must hold bc.mux
+func (bc *BlockChain) verifyNewTransactions(b *core.Block, fork uint64, branch []*core.Block) error {
+	seen := map[[32]byte]bool{}
+	for _, anc := range branch {
+		for _, t := range anc.Transactions() {
+			seen[t.ID] = true
+		}
+	}
+	for _, t := range b.Transactions() {
+		if !seen[t.ID] {
+			seen[t.ID] = true
+			//Lookups only index the canonical chain, and only the part of it below fork is an ancestor
+			lookup, err := bc.store.ReadTxLookup(t.ID)
+			if err == core.ErrNotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			included, err := bc.store.ReadBlock(lookup.BlockHash)
+			if err != nil {
+				return err
+			}
+			if included.Height() >= fork {
+				continue
+			}
+		}
+		return &core.BlockError{Block: b.ID, Reason: fmt.Sprintf("transaction %x is already in the chain", t.ID[:4])}
+	}
+	return nil
+}
+
+// Seal the highest gas pending transactions into a block every interval
+// TODO: a stand-in for block production until there is a consensus engine, every producer builds on its own head
+func (bc *BlockChain) produceBlocks(interval time.Duration) {
+	for range time.Tick(interval) {
+		var trans []*core.Transaction
+		for len(trans) < maxBlockTransactions {
+			t, err := bc.memPool.RemoveHighestGasTransaction()
+			if err != nil {
+				break
+			}
+			trans = append(trans, t)
+		}
+		b, err := bc.CreateBlock(bc.LastBlock().ID, trans)
+		if err != nil {
+			//The head moved under us, the transactions go back to wait for the next block
+			for _, t := range trans {
+				bc.memPool.AddTransactionToPool(t)
+			}
+			continue
+		}
+		log.Printf("Sealed block %d %x with %d transactions", b.Height(), b.ID[:4], len(trans))
+	}
+}
+
+// Execute a new block on top of the head and make it the new head, caller must hold bc.mux
+func (bc *BlockChain) commitBlock(b *core.Block) error {
+	b.SetHistoryRoot(bc.history.Root())
+	state := bc.state.Copy()
+	receipts := b.Finalize(state)
+	return bc.appendBlock(b, state, receipts)
 }
 
-func (bc *BlockChain) CreateBlock(prevHash [32]byte, trans []*core.Transaction) *core.Block {
-	b := core.NewBlock(prevHash, trans)
+// Make a block the new head, state is bc.state with the block applied. Nothing changes unless the block is in the store
+// and the history, so memory never runs ahead of what a restart would load. Caller must hold bc.mux
+func (bc *BlockChain) appendBlock(b *core.Block, state *core.State, receipts []*core.Receipt) error {
+	history := bc.history.Copy()
+	if err := history.Append(b.ID); err != nil {
+		return fmt.Errorf("failed to add block %x to the history: %w", b.ID[:4], err)
+	}
+	if err := bc.store.CommitBlock(b, receipts); err != nil {
+		return fmt.Errorf("failed to commit block %x: %w", b.ID[:4], err)
+	}
+	bc.history = history
+	bc.state = state
 	bc.chain = append(bc.chain, b)
-	return b
+	bc.states = append(bc.states, bc.state)
+	if len(bc.states) > bc.stateHistory {
+		bc.states = bc.states[1:]
+	}
+	bc.writeFlatState([]*core.Block{b})
+	//Pruning sweeps the whole store, so it runs alongside the blocks imported after this one
+	if !bc.archive && b.Height()%uint64(bc.stateHistory) == 0 && bc.pruning.CompareAndSwap(false, true) {
+		bc.prunes.Add(1)
+		go func() {
+			defer bc.prunes.Done()
+			defer bc.pruning.Store(false)
+			bc.pruneState()
+		}()
+	}
+	bc.indexBlooms()
+	bc.memPool.RemoveIncluded(b.Transactions())
+	bc.events.Publish(core.Event{Type: core.NewHeadEvent, Block: b})
+	return nil
+}
+
+// Reorg switches the canonical chain to branch, whose first block must extend a block already in the chain
+// Every block in branch must already have been persisted with BlockStore.WriteBlock
+func (bc *BlockChain) Reorg(branch []*core.Block) error {
+	bc.mux.Lock()
+	defer bc.mux.Unlock()
+	return bc.reorg(branch)
+}
+
+// Helper for Reorg, caller must hold bc.mux
+func (bc *BlockChain) reorg(branch []*core.Block) error {
+	if len(branch) == 0 {
+		return errors.New("reorg branch is empty")
+	}
+	fork := branch[0].Height()
+	if fork == 0 || fork > uint64(len(bc.chain)) || bc.chain[fork-1].ID != branch[0].ParentHash() {
+		return errors.New("reorg branch does not extend the canonical chain")
+	}
+	//Blocks are rolled back head first
+	removed := make([]*core.Block, 0, uint64(len(bc.chain))-fork)
+	for i := len(bc.chain) - 1; i >= int(fork); i-- {
+		removed = append(removed, bc.chain[i])
+	}
+	chain := append(bc.chain[:fork:fork], branch...)
+	//TODO: replaying from genesis is O(chain), keep state per block so we can rewind to the fork instead
+	//The recent states are rebuilt along the way so snap serving and pruning keep as many as before the reorg
+	states, err := bc.replayStates(chain, bc.stateHistory)
+	if err != nil {
+		return err
+	}
+	state := states[len(states)-1]
+	history, err := bc.replayHistory(chain)
+	if err != nil {
+		return err
+	}
+	if err := bc.store.Reorg(removed, branch); err != nil {
+		return err
+	}
+	bc.chain = chain
+	bc.state, bc.states = state, states
+	bc.history = history
+	//Accounts touched by the removed blocks are rewritten too, to undo them
+	bc.writeFlatState(append(append([]*core.Block{}, removed...), branch...))
+	bc.indexBlooms()
+	for _, b := range branch {
+		bc.memPool.RemoveIncluded(b.Transactions())
+	}
+	bc.events.Publish(core.Event{Type: core.ReorgEvent, Removed: removed, Added: branch})
+	return nil
+}
+
+// Query methods
+func (bc *BlockChain) GetReceipt(txID [32]byte) (*core.Receipt, error) {
+	//Held across the lookup and the reads it points at so a reorg can't swap the block out in between
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	lookup, err := bc.store.ReadTxLookup(txID)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := bc.store.ReadReceipts(lookup.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	//A stale or corrupt lookup can point past the block's receipts
+	if int(lookup.Index) >= len(receipts) {
+		return nil, core.ErrNotFound
+	}
+	return receipts[lookup.Index], nil
+}
+
+func (bc *BlockChain) GetTransaction(txID [32]byte) (*core.Transaction, *core.TxLookup, error) {
+	//Held across the lookup and the reads it points at so a reorg can't swap the block out in between
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	lookup, err := bc.store.ReadTxLookup(txID)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := bc.store.ReadBlock(lookup.BlockHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if int(lookup.Index) >= len(b.Transactions()) {
+		return nil, nil, core.ErrNotFound
+	}
+	return b.Transactions()[lookup.Index], lookup, nil
+}
+
+func (bc *BlockChain) GetBlockByHash(hash [32]byte) (*core.Block, error) {
+	return bc.store.ReadBlock(hash)
+}
+
+func (bc *BlockChain) GetBlockByHeight(height uint64) (*core.Block, error) {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	if height >= uint64(len(bc.chain)) {
+		return nil, core.ErrNotFound
+	}
+	return bc.chain[height], nil
+}
+
+// A range of the accounts of one of the recent canonical states, core.ErrNotFound once the state is too old
+func (bc *BlockChain) AccountRange(root [32]byte, origin [32]byte, max int) (*core.AccountRange, error) {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	state := bc.recentState(root)
+	if state == nil {
+		return nil, core.ErrNotFound
+	}
+	return state.AccountRange(origin, max)
+}
+
+// Proof of the balance of address in one of the recent canonical states, core.ErrNotFound once the state is too old
+func (bc *BlockChain) AccountProof(root [32]byte, address [core.AddressLength]byte) (*core.AccountProof, error) {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	state := bc.recentState(root)
+	if state == nil {
+		//Older states can still be proven from their state trees while the store keeps them
+		return core.ProveAccountAt(bc.store.Nodes(), root, address)
+	}
+	return state.ProveAccount(address)
+}
+
+// Proof a canonical transaction was executed, by its receipt
+func (bc *BlockChain) TxProof(txID [32]byte) (*core.TxProof, error) {
+	//Held across the lookup and the reads it points at so a reorg can't swap the block out in between
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	lookup, err := bc.store.ReadTxLookup(txID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := bc.store.ReadBlock(lookup.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := bc.store.ReadReceipts(lookup.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	return core.NewTxProof(b, receipts, lookup.Index)
+}
+
+// Root of the MountainRange of every canonical block, which the next block commits to
+func (bc *BlockChain) HistoryRoot() [32]byte {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	return bc.history.Root()
+}
+
+// Proof the canonical block at height is in the chain under HistoryRoot
+func (bc *BlockChain) HistoryProof(height uint64) (*structures.MountainProof, error) {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	if height >= uint64(len(bc.chain)) {
+		return nil, core.ErrNotFound
+	}
+	return bc.history.ConstructProof(height)
+}
+
+// Height of the block the chain was snap synced at, blocks up to it have no bodies. 0 if every block was imported
+func (bc *BlockChain) SnapshotHeight() uint64 {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	if bc.snapshot == nil {
+		return 0
+	}
+	return bc.snapshot.Height()
+}
+
+func (bc *BlockChain) GetBalance(address [core.AddressLength]byte) (uint64, error) {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	return bc.state.Balance(address), nil
+}
+
+// Validate and add a transaction to the MemPool
+func (bc *BlockChain) AddTransaction(t *core.Transaction) error {
+	//Held so the transaction can't be sealed into a block between the lookup and the pool taking it
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	if bc.TransactionIncluded(t.ID) {
+		return core.ErrKnownTransaction
+	}
+	return bc.memPool.AddTransactionToPool(t)
+}
+
+// Whether a transaction is already in a canonical block
+func (bc *BlockChain) TransactionIncluded(id [32]byte) bool {
+	_, err := bc.store.ReadTxLookup(id)
+	return err == nil
+}
+
+// A transaction still waiting in the mempool, nil if it isn't there
+func (bc *BlockChain) PoolTransaction(id [32]byte) *core.Transaction {
+	t, _ := bc.memPool.GetTransaction(id)
+	return t
+}
+
+// Every transaction waiting in the mempool
+func (bc *BlockChain) PoolTransactions() []*core.Transaction {
+	return bc.memPool.Pending()
+}
+
+// Number of transactions waiting in the MemPool
+func (bc *BlockChain) PendingTransactions() int {
+	return bc.memPool.Size()
+}
+
+// IDs of every canonical transaction an address sent or received, FindTransactions narrows a history too long for this
+func (bc *BlockChain) GetAddressHistory(address [core.AddressLength]byte) ([][32]byte, error) {
+	return bc.store.ReadAddressHistory(address, maxFilterResults)
+}
+
+// Logs of the canonical chain up to the head matching filter
+func (bc *BlockChain) GetLogs(filter *core.LogFilter) ([]*core.FilteredLog, error) {
+	//Held across the query so a reorg can't swap the canonical blocks out from under it
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	f := *filter
+	if head := bc.chain[len(bc.chain)-1].Height(); f.To > head {
+		f.To = head
+	}
+	if f.From > f.To {
+		return []*core.FilteredLog{}, nil
+	}
+	return bc.store.GetLogs(&f, maxFilterResults)
+}
+
+// IDs of the canonical transactions from height from to to an address sent or received, up to the head
+func (bc *BlockChain) FindTransactions(address [core.AddressLength]byte, from, to uint64) ([][32]byte, error) {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	if head := bc.chain[len(bc.chain)-1].Height(); to > head {
+		to = head
+	}
+	if from > to {
+		return [][32]byte{}, nil
+	}
+	return bc.store.FindTransactions(address, from, to, maxFilterResults)
+}
+
+func (bc *BlockChain) GenesisHash() [32]byte {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	return bc.chain[0].ID
+}
+
+func (bc *BlockChain) ChainID() uint64 {
+	return bc.chainID
+}
+
+// Sum of the difficulty of every canonical block
+func (bc *BlockChain) TotalDifficulty() uint64 {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
+	return totalDifficulty(bc.chain)
 }
 
 // Helper Methods
+// Read the canonical chain back out of the store, returns core.ErrNotFound on an empty store
+func (bc *BlockChain) loadChain() error {
+	headHash, err := bc.store.ReadHeadHash()
+	if err != nil {
+		return err
+	}
+	head, err := bc.store.ReadBlock(headHash)
+	if err != nil {
+		return err
+	}
+	chain := make([]*core.Block, 0, head.Height()+1)
+	for h := uint64(0); h <= head.Height(); h++ {
+		hash, err := bc.store.ReadCanonicalHash(h)
+		if err != nil {
+			return err
+		}
+		b, err := bc.store.ReadBlock(hash)
+		if err != nil {
+			return err
+		}
+		chain = append(chain, b)
+	}
+	if hash, state, err := bc.store.ReadSnapshot(); err == nil {
+		snapshot, err := bc.store.ReadBlock(hash)
+		if err != nil {
+			return err
+		}
+		if snapshot.Height() >= uint64(len(chain)) || chain[snapshot.Height()].ID != hash {
+			return errors.New("snap synced block is not canonical")
+		}
+		bc.snapshot, bc.snapshotState = snapshot, state
+	} else if err != core.ErrNotFound {
+		return err
+	}
+	state, flat, err := bc.loadState(chain)
+	if err != nil {
+		return err
+	}
+	history, err := bc.replayHistory(chain)
+	if err != nil {
+		return err
+	}
+	bc.chain = chain
+	bc.state, bc.states = state, []*core.State{state}
+	bc.history = history
+	if !flat {
+		bc.writeFlatState(nil)
+	}
+	bc.indexBlooms()
+	return nil
+}
+
+// The state as of the last block of chain, read from the flat accounts if they're as of it and replayed otherwise.
+// Reports whether the flat accounts were used
+func (bc *BlockChain) loadState(chain []*core.Block) (*core.State, bool, error) {
+	head := chain[len(chain)-1]
+	hash, state, err := bc.store.ReadFlatState()
+	if err != nil && err != core.ErrNotFound {
+		return nil, false, err
+	}
+	if err == nil && hash == head.ID {
+		if root := state.Root(); root == head.StateRoot() {
+			return state, true, nil
+		}
+		log.Printf("Flat state does not match state root of block %d, replaying the chain", head.Height())
+	}
+	state, err = bc.replayState(chain)
+	return state, false, err
+}
+
+// Walk back from a stored block to where it joins the canonical chain, returns the non-canonical blocks parent first
+// Caller must hold bc.mux
+func (bc *BlockChain) sideBranch(from *core.Block) ([]*core.Block, error) {
+	var branch []*core.Block
+	b := from
+	for b.Height() >= uint64(len(bc.chain)) || bc.chain[b.Height()].ID != b.ID {
+		if b.Height() == 0 {
+			return nil, errors.New("side chain does not share our genesis block")
+		}
+		branch = append(branch, b)
+		parent, err := bc.store.ReadBlock(b.ParentHash())
+		if err != nil {
+			return nil, err
+		}
+		b = parent
+	}
+	for i, j := 0, len(branch)-1; i < j; i, j = i+1, j-1 {
+		branch[i], branch[j] = branch[j], branch[i]
+	}
+	return branch, nil
+}
+
+func totalDifficulty(chain []*core.Block) uint64 {
+	var td uint64
+	for _, b := range chain {
+		td += b.Difficulty()
+	}
+	return td
+}
+
+// Rebuild the state as of the last block of chain, which starts at genesis, by executing every block.
+// A snap synced chain starts from its snapshot instead, so chain can't leave the canonical chain below it
+func (bc *BlockChain) replayState(chain []*core.Block) (*core.State, error) {
+	states, err := bc.replayStates(chain, 1)
+	if err != nil {
+		return nil, err
+	}
+	return states[0], nil
+}
+
+// replayState keeping the states as of the last n blocks of chain, oldest first and ending with the head's state.
+// There are fewer when the replay starts above the first of them, as a snap synced chain's does
+func (bc *BlockChain) replayStates(chain []*core.Block, n int) ([]*core.State, error) {
+	state, rest, err := bc.replayBase(chain)
+	if err != nil {
+		return nil, err
+	}
+	var states []*core.State
+	//The state being replayed keeps changing, so every state but the head's is a copy
+	if len(rest) < n && len(rest) > 0 {
+		states = append(states, state.Copy())
+	}
+	for i, b := range rest {
+		b.Execute(state)
+		if last := len(rest) - 1; i == last {
+			states = append(states, state)
+		} else if last-i < n {
+			states = append(states, state.Copy())
+		}
+	}
+	if len(rest) == 0 {
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// The state replaying chain, which starts at genesis, starts from and the blocks left to execute on it
+func (bc *BlockChain) replayBase(chain []*core.Block) (*core.State, []*core.Block, error) {
+	if bc.snapshot == nil {
+		return core.NewStateWithNodes(bc.store.Nodes(), GenesisAlloc), chain, nil
+	}
+	h := bc.snapshot.Height()
+	if uint64(len(chain)) <= h || chain[h].ID != bc.snapshot.ID {
+		return nil, nil, errors.New("chain forks below the snap synced block")
+	}
+	return bc.snapshotState.Copy(), chain[h+1:], nil
+}
+
+// The recent canonical state with the given root, nil if it isn't kept anymore. Caller must hold bc.mux
+func (bc *BlockChain) recentState(root [32]byte) *core.State {
+	//states lines up with the tail of the chain
+	offset := len(bc.chain) - len(bc.states)
+	for i := len(bc.states) - 1; i >= 0; i-- {
+		if bc.chain[offset+i].StateRoot() == root {
+			return bc.states[i]
+		}
+	}
+	return nil
+}
+
+// Rebuild the MountainRange of chain, which starts at genesis, checking every block commits to the blocks before it
+func (bc *BlockChain) replayHistory(chain []*core.Block) (*structures.MountainRange, error) {
+	history := structures.NewMountainRange(bc.historyNodes)
+	for _, b := range chain {
+		if err := b.VerifyHistory(history.Root()); err != nil {
+			return nil, err
+		}
+		if err := history.Append(b.ID); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+// Index the blooms of every complete section buried bloomConfirmations deep, caller must hold bc.mux
+func (bc *BlockChain) indexBlooms() {
+	indexed, err := bc.store.ReadBloomSections()
+	if err != nil {
+		log.Printf("Failed to read bloom index: %v", err)
+		return
+	}
+	for ; (indexed+1)*core.BloomSectionSize+bloomConfirmations <= uint64(len(bc.chain)); indexed++ {
+		start := indexed * core.BloomSectionSize
+		if err := bc.store.WriteBloomSection(indexed, bc.chain[start:start+core.BloomSectionSize]); err != nil {
+			log.Printf("Failed to index bloom section %d: %v", indexed, err)
+			return
+		}
+	}
+}
+
+// Keep the flat accounts in the store up to date with the head after blocks, nil rewrites every account. Caller must
+// hold bc.mux
+func (bc *BlockChain) writeFlatState(blocks []*core.Block) {
+	if err := bc.store.WriteFlatState(bc.chain[len(bc.chain)-1], bc.state, blocks); err != nil {
+		log.Printf("Failed to write flat state: %v", err)
+	}
+}
+
+// Close waits for a prune running in the background, the store has to stay open until it returns
+func (bc *BlockChain) Close() {
+	bc.prunes.Wait()
+}
+
+// Delete the state trees of every state but the recent ones, the checkpoints and the snap synced one. Blocks are
+// imported while it runs, only taking bc.mux to delete nodes, so the caller must not hold it
+func (bc *BlockChain) pruneState() {
+	pruned, err := bc.store.PruneState(bc.keptStateRoots, bc.mux.RLocker())
+	if err != nil {
+		log.Printf("Failed to prune state: %v", err)
+		return
+	}
+	log.Printf("Pruned %d state tree nodes", pruned)
+}
+
+// Roots of the states a pruned chain keeps, caller must hold bc.mux
+func (bc *BlockChain) keptStateRoots() [][32]byte {
+	roots := [][32]byte{bc.state.Root()}
+	if bc.snapshot != nil {
+		roots = append(roots, bc.snapshot.StateRoot())
+	}
+	for h := 0; h < len(bc.chain); h += bc.stateCheckpoint {
+		roots = append(roots, bc.chain[h].StateRoot())
+	}
+	for h := max(len(bc.chain)-bc.stateHistory, 0); h < len(bc.chain); h++ {
+		roots = append(roots, bc.chain[h].StateRoot())
+	}
+	return roots
+}
+
 func (bc *BlockChain) LastBlock() *core.Block {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
 	return bc.chain[len(bc.chain)-1]
 }
 
@@ -65,6 +889,8 @@ func (bc *BlockChain) hashPrevBlock() [32]byte {
 
 // Formatting methods
 func (bc *BlockChain) String() string {
+	bc.mux.RLock()
+	defer bc.mux.RUnlock()
 	var sb strings.Builder
 	div := strings.Repeat("=", 25)
 	for i, block := range bc.chain {
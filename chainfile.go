@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+)
+
+// How often an import reports its progress
+const importLogInterval = 5 * time.Second
+
+/*
+gochain export writes canonical blocks from the store of a stopped full node to a chain file. Blocks up to a snap
+synced block have no bodies so can't be exported, nor can any block of a light node's store
+*/
+func exportChain(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dataDir := fs.String("datadir", "chaindata", "directory of the block store")
+	from := fs.Uint64("from", 0, "height of the first block to export")
+	to := fs.Int64("to", -1, "height of the last block to export, -1 exports up to the head")
+	compress := fs.Bool("gzip", false, "gzip the file")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf("Usage: gochain export [-datadir dir] [-from height] [-to height] [-gzip] file")
+	}
+	if *to < -1 {
+		log.Fatalf("Invalid -to %d, expected a height or -1 for the head", *to)
+	}
+	if err := export(*dataDir, fs.Arg(0), *from, *to, *compress); err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+}
+
+// Export blocks from to to from the store in dataDir to path, a negative to exports up to the head. Everything opened
+// is closed before it returns, and a file left incomplete is removed
+func export(dataDir, path string, from uint64, to int64, compress bool) (err error) {
+	store, err := core.OpenBlockStore(dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to open block store: %w", err)
+	}
+	defer store.Close()
+	if cfg, err := store.ReadStateConfig(); err == nil && cfg.Mode == core.HeadersOnly {
+		return fmt.Errorf("%s is a light node's store, its blocks have no bodies to export", dataDir)
+	}
+	headHash, err := store.ReadHeadHash()
+	if err != nil {
+		return fmt.Errorf("no chain to export in %s: %w", dataDir, err)
+	}
+	head, err := store.ReadBlock(headHash)
+	if err != nil {
+		return fmt.Errorf("failed to read head block: %w", err)
+	}
+	last := head.Height()
+	if to >= 0 && uint64(to) < last {
+		last = uint64(to)
+	}
+	if from > last {
+		return fmt.Errorf("nothing to export, -from %d is past block %d", from, last)
+	}
+	if hash, _, err := store.ReadSnapshot(); err == nil {
+		snapshot, err := store.ReadBlock(hash)
+		if err != nil {
+			return fmt.Errorf("failed to read snap synced block: %w", err)
+		}
+		if from <= snapshot.Height() {
+			return fmt.Errorf("blocks up to %d were snap synced without their bodies, export from %d on", snapshot.Height(), snapshot.Height()+1)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+	cw := core.NewChainWriter(f, compress)
+	for h := from; h <= last; h++ {
+		hash, err := store.ReadCanonicalHash(h)
+		if err != nil {
+			return fmt.Errorf("failed to read canonical block %d: %w", h, err)
+		}
+		b, err := store.ReadBlock(hash)
+		if err != nil {
+			return fmt.Errorf("failed to read block %d %x: %w", h, hash[:4], err)
+		}
+		if err := cw.WriteBlock(b); err != nil {
+			return fmt.Errorf("failed to write block %d: %w", h, err)
+		}
+	}
+	if err := cw.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Printf("Exported blocks %d to %d to %s", from, last, path)
+	return nil
+}
+
+/*
+gochain import adds the blocks of a chain file to the store of a stopped full node, each validated and executed the
+same as a block from a peer. Blocks the store already has are skipped, so an interrupted import can be run again. The
+import stops at the first invalid block, the blocks before it stay imported
+*/
+func importChain(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dataDir := fs.String("datadir", "chaindata", "directory of the block store")
+	//The store has to keep the same states
+	stateCfg := stateFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf("Usage: gochain import [-datadir dir] [-archive] [-statehistory n] [-checkpoint n] file")
+	}
+	if err := importFile(*dataDir, fs.Arg(0), stateCfg()); err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+}
+
+// Import the chain file at path into the store in dataDir, everything opened is closed before it returns
+func importFile(dataDir, path string, stateCfg core.StateConfig) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cr, err := core.NewChainReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	store, err := core.OpenBlockStore(dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to open block store: %w", err)
+	}
+	defer store.Close()
+	if err := checkStateMode(store, stateCfg); err != nil {
+		return fmt.Errorf("failed to open state: %w", err)
+	}
+	bc, err := NewBlockChain(store, core.NewEventBus())
+	if err != nil {
+		return err
+	}
+	defer bc.Close()
+
+	start := time.Now()
+	lastLog := start
+	var imported, known, side, transactions int
+	for {
+		b, err := cr.ReadBlock()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			logImport(imported, transactions, start)
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if b.Height() == 0 {
+			if b.ID != bc.GenesisHash() {
+				return fmt.Errorf("%s is of a different chain, its genesis block is %x", path, b.ID[:4])
+			}
+			continue
+		}
+		if err := bc.InsertBlock(b); errors.Is(err, core.ErrKnownBlock) {
+			known++
+			continue
+		} else if err != nil {
+			logImport(imported, transactions, start)
+			return fmt.Errorf("stopped importing at block %d %x: %w", b.Height(), b.ID[:4], err)
+		}
+		//A block of a side chain with less work is only stored, it isn't on the imported chain
+		if bc.LastBlock().ID != b.ID {
+			side++
+			continue
+		}
+		imported++
+		transactions += len(b.Transactions())
+		if time.Since(lastLog) >= importLogInterval {
+			logImport(imported, transactions, start)
+			lastLog = time.Now()
+		}
+	}
+	logImport(imported, transactions, start)
+	log.Printf("Skipped %d known blocks, stored %d side chain blocks, head is block %d", known, side,
+		bc.LastBlock().Height())
+	return nil
+}
+
+func logImport(blocks, transactions int, start time.Time) {
+	elapsed := time.Since(start)
+	seconds := elapsed.Seconds()
+	log.Printf("Imported %d blocks with %d transactions in %s, %.1f blocks/s, %.1f tx/s", blocks, transactions,
+		elapsed.Round(time.Millisecond), float64(blocks)/seconds, float64(transactions)/seconds)
+}
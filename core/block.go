@@ -4,7 +4,10 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/structures"
 	"golang.org/x/crypto/sha3"
+	"google.golang.org/protobuf/proto"
 	"strings"
 	"time"
 )
@@ -12,45 +15,215 @@ import (
 const (
 	//TODO: make the gaslimit dynamic based on load
 	GASLIMIT = 30 * 1000000 //30 million
+	//TODO: difficulty should be set by the consensus engine
+	DIFFICULTY = 1
+	//BlockVersion is the format every block after genesis is built in, version 0 is the genesis block's format
+	//Version 1: receipts are committed to in a SeparatedTree
+	//Version 2: the header commits to the MountainRange of every earlier block
+	//Version 3: the header carries a Bloom of the addresses and log topics in the block
+	BlockVersion = 3
 )
 
 type Block struct {
 	//Header
 	ID           [32]byte
+	version      uint32
 	timestamp    int64
 	parentHash   [32]byte
 	trieRootHash [32]byte
+	receiptsRoot [32]byte
+	historyRoot  [32]byte
+	bloom        Bloom
 	gasLimit     uint32
+	height       uint64
+	difficulty   uint64
 	//Body
 	transactions []*Transaction
 }
 
-// TODO: write proto file, change marshalling type for actual encoding
+// Hash of the block header, the ID and body are left out so the hash is stable once the block is finalized
 func (b *Block) Hash() [32]byte {
-	m, _ := json.Marshal(b)
-	return sha3.Sum256(m)
+	header := b.ConvertToBlockPbMsg()
+	header.ID = nil
+	header.Transactions = nil
+	data, _ := proto.Marshal(header)
+	return sha3.Sum256(data)
 }
 
-// TODO: we need to provide a trieRootHash
-// TODO: we need to hash the headers of the block
-func NewBlock(parentHash [32]byte, trans []*Transaction) *Block {
+func NewBlock(parentHash [32]byte, height uint64, trans []*Transaction) *Block {
 	return &Block{
 		timestamp:    time.Now().UnixNano(),
 		parentHash:   parentHash,
+		height:       height,
 		transactions: trans,
+		version:      BlockVersion,
 		gasLimit:     GASLIMIT,
+		difficulty:   DIFFICULTY,
 	}
 }
 
+// Execute applies the block's transactions to state in order and returns their receipts
+func (b *Block) Execute(state *State) []*Receipt {
+	receipts := make([]*Receipt, 0, len(b.transactions))
+	var cumulativeGasUsed uint64
+	for i, t := range b.transactions {
+		r := ApplyTransaction(state, t, uint32(i), cumulativeGasUsed)
+		r.BlockHash = b.ID
+		cumulativeGasUsed = r.CumulativeGasUsed
+		receipts = append(receipts, r)
+	}
+	return receipts
+}
+
+// NewGenesisBlock creates the first block, its timestamp is fixed so every node derives the same genesis hash
+func NewGenesisBlock() *Block {
+	//Generates an Empty Block as the prevHash of the Genesis Block
+	b := NewBlock((&Block{}).Hash(), 0, []*Transaction{})
+	b.timestamp = 0
+	//The genesis block keeps the original format so its hash, which peers check in the handshake, never changes
+	b.version = 0
+	return b
+}
+
+// Finalize executes the block's transactions, commits to their receipts and the resulting state and sets the block ID
+func (b *Block) Finalize(state *State) []*Receipt {
+	receipts := b.Execute(state)
+	b.receiptsRoot = receiptsRoot(b.TreeMode(), receipts)
+	if b.HasBloom() {
+		b.bloom = CreateBloom(b.transactions, receipts)
+	}
+	b.trieRootHash = state.Root()
+	b.ID = b.Hash()
+	for _, r := range receipts {
+		r.BlockHash = b.ID
+	}
+	return receipts
+}
+
+// Convert from the pb message back into a Block, the ID is recomputed from the header rather than trusted
+func NewBlockFromPbMsg(msg *types.BlockMsg) *Block {
+	b := &Block{
+		version:    msg.GetVersion(),
+		timestamp:  msg.GetTimestamp(),
+		gasLimit:   uint32(msg.GetGasLimit()),
+		height:     msg.GetHeight(),
+		difficulty: msg.GetDifficulty(),
+	}
+	copy(b.parentHash[:], msg.GetParentHash())
+	copy(b.trieRootHash[:], msg.GetTrieRootHash())
+	copy(b.receiptsRoot[:], msg.GetReceiptsRoot())
+	copy(b.historyRoot[:], msg.GetHistoryRoot())
+	copy(b.bloom[:], msg.GetBloom())
+	for _, tm := range msg.GetTransactions() {
+		b.transactions = append(b.transactions, NewTransactionFromPbMsg(tm))
+	}
+	b.ID = b.Hash()
+	return b
+}
+
+// Getters
+func (b *Block) Transactions() []*Transaction {
+	return b.transactions
+}
+
+func (b *Block) ParentHash() [32]byte {
+	return b.parentHash
+}
+
+func (b *Block) Height() uint64 {
+	return b.height
+}
+
+func (b *Block) Difficulty() uint64 {
+	return b.difficulty
+}
+
+func (b *Block) Version() uint32 {
+	return b.version
+}
+
+// How the block's receipts tree is hashed, which depends on the block's format version
+func (b *Block) TreeMode() structures.TreeMode {
+	if b.version == 0 {
+		return structures.LegacyTree
+	}
+	return structures.SeparatedTree
+}
+
+func (b *Block) ReceiptsRoot() [32]byte {
+	return b.receiptsRoot
+}
+
+// Root of the MountainRange of the hashes of every block before this one, zero before version 2
+func (b *Block) HistoryRoot() [32]byte {
+	return b.historyRoot
+}
+
+// Commit to the blocks before this one, it has to be set before the block is finalized
+func (b *Block) SetHistoryRoot(root [32]byte) {
+	b.historyRoot = root
+}
+
+// Bloom of the addresses and log topics in the block, zero before version 3
+func (b *Block) Bloom() Bloom {
+	return b.bloom
+}
+
+// Whether the block carries a Bloom, a block without one may contain anything
+func (b *Block) HasBloom() bool {
+	return b.version >= 3
+}
+
+// Root of the state after the block's transactions were applied
+func (b *Block) StateRoot() [32]byte {
+	return b.trieRootHash
+}
+
+func (b *Block) Timestamp() int64 {
+	return b.timestamp
+}
+
+// Convert to protoreflect.Protomessage type for pb marshalling
+func (b *Block) ConvertToBlockPbMsg() *types.BlockMsg {
+	msg := &types.BlockMsg{
+		ID:           b.ID[:],
+		Timestamp:    b.timestamp,
+		ParentHash:   b.parentHash[:],
+		TrieRootHash: b.trieRootHash[:],
+		GasLimit:     int32(b.gasLimit),
+		ReceiptsRoot: b.receiptsRoot[:],
+		Height:       b.height,
+		Difficulty:   b.difficulty,
+		Version:      b.version,
+	}
+	//Left out of older blocks, whose hashes have to stay as they were
+	if b.version >= 2 {
+		msg.HistoryRoot = b.historyRoot[:]
+	}
+	if b.HasBloom() {
+		msg.Bloom = b.bloom[:]
+	}
+	for _, t := range b.transactions {
+		msg.Transactions = append(msg.Transactions, t.ConvertToTransactionPbMsg())
+	}
+	return msg
+}
+
 // Stringify: Stringer interface implementation
 func (b Block) String() string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("ID: %v\n", b.ID))
+	sb.WriteString(fmt.Sprintf("Version: %d\n", b.version))
 	sb.WriteString(fmt.Sprintf("ParentHash: %s\n", hex.EncodeToString(b.parentHash[:])))
+	sb.WriteString(fmt.Sprintf("Height: %d\n", b.height))
 	sb.WriteString(fmt.Sprintf("Timestamp: %d\n", b.timestamp))
 	sb.WriteString(fmt.Sprintf("Root Hash: %v\n", b.trieRootHash))
+	sb.WriteString(fmt.Sprintf("Receipts Root: %s\n", hex.EncodeToString(b.receiptsRoot[:])))
+	sb.WriteString(fmt.Sprintf("History Root: %s\n", hex.EncodeToString(b.historyRoot[:])))
+	sb.WriteString(fmt.Sprintf("Bloom: %s\n", hex.EncodeToString(b.bloom[:])))
 	sb.WriteString(fmt.Sprintf("Gas Limit: %d\n", b.gasLimit))
+	sb.WriteString(fmt.Sprintf("Difficulty: %d\n", b.difficulty))
 	sb.WriteString("Transactions:\n")
 
 	for i, transaction := range b.transactions {
@@ -66,18 +239,30 @@ func (b Block) String() string {
 func (b *Block) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		ID           [32]byte       `json:"ID"`
+		Version      uint32         `json:"version"`
 		ParentHash   [32]byte       `json:"parent_hash"`
 		TrieRootHash [32]byte       `json:"trie_root_hash"`
+		ReceiptsRoot [32]byte       `json:"receipts_root"`
+		HistoryRoot  [32]byte       `json:"history_root"`
+		Bloom        Bloom          `json:"bloom"`
 		Timestamp    int64          `json:"timestamp"`
 		Gaslimit     uint32         `json:"gas_limit"`
+		Height       uint64         `json:"height"`
+		Difficulty   uint64         `json:"difficulty"`
 		Transactions []*Transaction `json:"transactions"`
 	}{
 
 		ID:           b.ID,
+		Version:      b.version,
 		ParentHash:   b.parentHash,
 		TrieRootHash: b.trieRootHash,
+		ReceiptsRoot: b.receiptsRoot,
+		HistoryRoot:  b.historyRoot,
+		Bloom:        b.bloom,
 		Timestamp:    b.timestamp,
 		Gaslimit:     b.gasLimit,
+		Height:       b.height,
+		Difficulty:   b.difficulty,
 		Transactions: b.transactions,
 	})
 }
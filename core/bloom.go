@@ -0,0 +1,66 @@
+package core
+
+import (
+	"golang.org/x/crypto/sha3"
+)
+
+/*
+Bloom filters
+From version 3 every block header carries a bloom filter over the addresses its transactions are sent from and to and
+the addresses and topics of the logs they emit. A search for an address or event skips any block whose bloom rules it
+out without reading the block's body or receipts, only false positives cost a read.
+Each item sets bloomHashes of the 2048 bits, picked by pairs of bytes of its hash.
+*/
+
+const (
+	BloomLength = 256
+	BloomBits   = BloomLength * 8
+	bloomHashes = 3
+)
+
+type Bloom [BloomLength]byte
+
+// Add sets the bits of data
+func (bl *Bloom) Add(data []byte) {
+	for _, bit := range BloomPositions(data) {
+		bl[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Test reports whether data may have been added, false means it definitely wasn't
+func (bl *Bloom) Test(data []byte) bool {
+	for _, bit := range BloomPositions(data) {
+		if bl[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// BloomPositions are the bits data sets in a Bloom
+func BloomPositions(data []byte) [bloomHashes]uint {
+	hash := sha3.Sum256(data)
+	var positions [bloomHashes]uint
+	for i := range positions {
+		positions[i] = (uint(hash[2*i])<<8 | uint(hash[2*i+1])) % BloomBits
+	}
+	return positions
+}
+
+// CreateBloom builds the bloom of a block with the given transactions and receipts
+func CreateBloom(trans []*Transaction, receipts []*Receipt) Bloom {
+	var bl Bloom
+	for _, t := range trans {
+		bl.Add(t.senderAddress[:])
+		bl.Add(t.receiverAddress[:])
+	}
+	for _, r := range receipts {
+		for _, l := range r.Logs {
+			bl.Add(l.Address[:])
+			for i := range l.Topics {
+				bl.Add(l.Topics[i][:])
+			}
+		}
+	}
+	return bl
+}
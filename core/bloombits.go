@@ -0,0 +1,140 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+/*
+Sectioned bloom bits
+Testing a range of blocks against their blooms still reads a header per block. Once a section of BloomSectionSize
+canonical blocks is complete, its blooms are transposed and stored by bit: for each of the BloomBits bits, a row of one
+bit per block in the section saying which blocks have it set. Looking an item up in a section reads the rows of its
+bloomHashes bits and ANDs them, which leaves only the blocks whose blooms may hold it, so a range query reads a few
+short rows per section rather than thousands of headers.
+
+Design considerations:
+-Rows of bits no block in the section sets aren't stored, a missing row reads as all zero
+-A block without a bloom may contain anything so it's indexed as if every bit were set
+-Sections are indexed in order and a reorg that reaches into indexed sections rewinds the count in the same batch, so
+the index never describes blocks that aren't canonical. Rows are overwritten when a section is indexed again
+*/
+
+const (
+	BloomSectionSize = 4096
+	bloomRowLength   = BloomSectionSize / 8
+)
+
+// WriteBloomSection indexes the blooms of headers, the canonical blocks of section. Sections are indexed in order
+func (bs *BlockStore) WriteBloomSection(section uint64, headers []*Block) error {
+	indexed, err := bs.ReadBloomSections()
+	if err != nil {
+		return err
+	}
+	if section != indexed {
+		return fmt.Errorf("section %d can't be indexed after %d sections", section, indexed)
+	}
+	if len(headers) != BloomSectionSize {
+		return fmt.Errorf("section of %d blocks, expected %d", len(headers), BloomSectionSize)
+	}
+	rows := make([][bloomRowLength]byte, BloomBits)
+	for i, h := range headers {
+		if h.height != section*BloomSectionSize+uint64(i) {
+			return fmt.Errorf("block at height %d is not block %d of section %d", h.height, i, section)
+		}
+		for bit := range rows {
+			if !h.HasBloom() || h.bloom[bit/8]&(1<<(bit%8)) != 0 {
+				rows[bit][i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+	batch := new(leveldb.Batch)
+	for bit := range rows {
+		key := bloomBitsKey(section, uint(bit))
+		if rows[bit] == ([bloomRowLength]byte{}) {
+			batch.Delete(key)
+			continue
+		}
+		batch.Put(key, rows[bit][:])
+	}
+	batch.Put(bloomSectionsKey, encodeUint64(section+1))
+	return bs.db.Write(batch, nil)
+}
+
+// Number of sections of bloom bits indexed, from section 0
+func (bs *BlockStore) ReadBloomSections() (uint64, error) {
+	data, err := bs.get(bloomSectionsKey)
+	if err == ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// Row of bit for the blocks of an indexed section, block i of the section is bit i%8 of byte i/8
+func (bs *BlockStore) ReadBloomBits(section uint64, bit uint) ([]byte, error) {
+	data, err := bs.get(bloomBitsKey(section, bit))
+	if err == ErrNotFound {
+		return make([]byte, bloomRowLength), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != bloomRowLength {
+		return nil, errors.New("corrupt bloom bits row")
+	}
+	return data, nil
+}
+
+// Helper methods
+// Rewind the index to the sections below height, for a reorg making blocks from height on canonical
+func (bs *BlockStore) rewindBloomSections(batch *leveldb.Batch, height uint64) error {
+	indexed, err := bs.ReadBloomSections()
+	if err != nil {
+		return err
+	}
+	if section := height / BloomSectionSize; section < indexed {
+		batch.Put(bloomSectionsKey, encodeUint64(section))
+	}
+	return nil
+}
+
+// Blocks of an indexed section whose blooms may satisfy q, as a row like those of ReadBloomBits
+func (bs *BlockStore) matchSection(section uint64, q bloomQuery) ([]byte, error) {
+	match := make([]byte, bloomRowLength)
+	for i := range match {
+		match[i] = 0xff
+	}
+	for _, group := range q {
+		//A block satisfies the group if its bloom may hold any one of the group's items
+		groupMatch := make([]byte, bloomRowLength)
+		for _, item := range group {
+			itemMatch := make([]byte, bloomRowLength)
+			copy(itemMatch, match)
+			for _, bit := range BloomPositions(item) {
+				row, err := bs.ReadBloomBits(section, bit)
+				if err != nil {
+					return nil, err
+				}
+				for i := range itemMatch {
+					itemMatch[i] &= row[i]
+				}
+			}
+			for i := range groupMatch {
+				groupMatch[i] |= itemMatch[i]
+			}
+		}
+		match = groupMatch
+	}
+	return match, nil
+}
+
+func bloomBitsKey(section uint64, bit uint) []byte {
+	key := storeKey(bloomBitsPrefix, encodeUint64(section))
+	return binary.BigEndian.AppendUint16(key, uint16(bit))
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/liangalv/goChain/core/types"
+	"google.golang.org/protobuf/proto"
+)
+
+/*
+Chain files
+A chain file holds a run of blocks, parent first, for moving a chain between machines or seeding a test network
+without syncing it from peers. Each block is a BlockMsg prefixed with its length as a uvarint, and the whole file may
+be gzipped. A compressed file is recognised by the gzip header, no length prefix starts with it since no block encodes
+to as few as 31 bytes.
+*/
+
+// Longest record a chain file may claim, so a corrupt length can't make the reader allocate without bound
+const maxBlockRecord = 16 << 20
+
+var gzipHeader = []byte{0x1f, 0x8b}
+
+// A ChainWriter writes blocks to a chain file
+type ChainWriter struct {
+	w  *bufio.Writer
+	gz *gzip.Writer
+}
+
+// Write a chain file to w, gzipped if compress is set
+func NewChainWriter(w io.Writer, compress bool) *ChainWriter {
+	cw := &ChainWriter{}
+	if compress {
+		cw.gz = gzip.NewWriter(w)
+		w = cw.gz
+	}
+	cw.w = bufio.NewWriter(w)
+	return cw
+}
+
+func (cw *ChainWriter) WriteBlock(b *Block) error {
+	data, err := proto.Marshal(b.ConvertToBlockPbMsg())
+	if err != nil {
+		return err
+	}
+	if _, err := cw.w.Write(binary.AppendUvarint(nil, uint64(len(data)))); err != nil {
+		return err
+	}
+	_, err = cw.w.Write(data)
+	return err
+}
+
+// Close flushes every block written, it doesn't close the underlying writer
+func (cw *ChainWriter) Close() error {
+	if err := cw.w.Flush(); err != nil {
+		return err
+	}
+	if cw.gz != nil {
+		return cw.gz.Close()
+	}
+	return nil
+}
+
+// A ChainReader reads blocks back out of a chain file
+type ChainReader struct {
+	r *bufio.Reader
+	//Records read so far, to say where a file is corrupt
+	records int
+}
+
+// Read a chain file from r, compressed or not
+func NewChainReader(r io.Reader) (*ChainReader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(gzipHeader))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(header, gzipHeader) {
+		return &ChainReader{r: br}, nil
+	}
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainReader{r: bufio.NewReader(gz)}, nil
+}
+
+// ReadBlock reads the next block, io.EOF once every block has been read
+func (cr *ChainReader) ReadBlock() (*Block, error) {
+	length, err := binary.ReadUvarint(cr.r)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("record %d: %w", cr.records, err)
+	}
+	if length > maxBlockRecord {
+		return nil, fmt.Errorf("record %d claims %d bytes, more than any block", cr.records, length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(cr.r, data); err != nil {
+		return nil, fmt.Errorf("record %d is truncated: %w", cr.records, err)
+	}
+	msg := &types.BlockMsg{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("record %d is not a block: %w", cr.records, err)
+	}
+	cr.records++
+	return NewBlockFromPbMsg(msg), nil
+}
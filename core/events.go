@@ -0,0 +1,103 @@
+package core
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSubscriberLagged is the reason a subscription is dropped when it can't keep up with the bus
+var ErrSubscriberLagged = errors.New("subscriber fell too far behind and was dropped")
+
+type EventType uint8
+
+const (
+	//Block is the new canonical head
+	NewHeadEvent EventType = iota
+	//Removed blocks (head first) were replaced by Added blocks (parent first)
+	ReorgEvent
+	//Transaction entered the MemPool
+	TxAddedEvent
+	//Transaction left the MemPool without being included in a block
+	TxEvictedEvent
+)
+
+type Event struct {
+	Type        EventType
+	Block       *Block
+	Removed     []*Block
+	Added       []*Block
+	Transaction *Transaction
+}
+
+/*
+The EventBus fans chain and MemPool events out to subscribers.
+Publishers never block: every subscriber gets a buffered channel and a subscriber whose buffer is full is dropped
+rather than stalling block production, it can tell why through Err() and resubscribe.
+*/
+type EventBus struct {
+	mux  sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	bus *EventBus
+	ch  chan Event
+	err error //guarded by bus.mux
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: map[*Subscription]struct{}{}}
+}
+
+// Subscribe to every event published from now on, buffer bounds how far behind the subscriber may fall
+func (eb *EventBus) Subscribe(buffer int) *Subscription {
+	eb.mux.Lock()
+	defer eb.mux.Unlock()
+	s := &Subscription{bus: eb, ch: make(chan Event, buffer)}
+	eb.subs[s] = struct{}{}
+	return s
+}
+
+// Publish is a no-op on a nil bus so components can run without one
+func (eb *EventBus) Publish(e Event) {
+	if eb == nil {
+		return
+	}
+	eb.mux.Lock()
+	defer eb.mux.Unlock()
+	for s := range eb.subs {
+		select {
+		case s.ch <- e:
+		default:
+			s.err = ErrSubscriberLagged
+			eb.remove(s)
+		}
+	}
+}
+
+// Events is closed once the subscription ends
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+func (s *Subscription) Unsubscribe() {
+	s.bus.mux.Lock()
+	defer s.bus.mux.Unlock()
+	s.bus.remove(s)
+}
+
+// Err reports why the subscription ended, nil if it was unsubscribed
+func (s *Subscription) Err() error {
+	s.bus.mux.Lock()
+	defer s.bus.mux.Unlock()
+	return s.err
+}
+
+// Helper, caller must hold eb.mux
+func (eb *EventBus) remove(s *Subscription) {
+	if _, ok := eb.subs[s]; !ok {
+		return
+	}
+	delete(eb.subs, s)
+	close(s.ch)
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/liangalv/goChain/core/types"
+)
+
+// ErrTooManyResults is returned when a search matches more than it may return, the range should be narrowed
+var ErrTooManyResults = errors.New("search matched too many results")
+
+// A LogFilter picks logs out of the canonical blocks from height From to To inclusive
+type LogFilter struct {
+	From uint64
+	To   uint64
+	//Logs emitted by any of Addresses, any address if empty
+	Addresses [][AddressLength]byte
+	//Topics by position, a log matches if its topic at each position is one of those listed. An empty position
+	//matches any topic
+	Topics [][][32]byte
+}
+
+// A FilteredLog is a log a LogFilter matched along with where it was emitted
+type FilteredLog struct {
+	Log       *Log
+	BlockHash [32]byte
+	Height    uint64
+	TxID      [32]byte
+	TxIndex   uint32
+}
+
+// A bloomQuery is satisfied by a bloom which may hold at least one item of every group
+type bloomQuery [][][]byte
+
+// Matches reports whether l satisfies the filter
+func (f *LogFilter) Matches(l *Log) bool {
+	if len(f.Addresses) > 0 && !containsAddress(f.Addresses, l.Address) {
+		return false
+	}
+	for i, topics := range f.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		if i >= len(l.Topics) || !containsTopic(topics, l.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetLogs returns the logs of the canonical chain matching filter in chain order, ErrTooManyResults past max
+func (bs *BlockStore) GetLogs(filter *LogFilter, max int) ([]*FilteredLog, error) {
+	heights, err := bs.candidates(filter.From, filter.To, filter.bloomQuery())
+	if err != nil {
+		return nil, err
+	}
+	logs := []*FilteredLog{}
+	for _, h := range heights {
+		hash, err := bs.ReadCanonicalHash(h)
+		if err != nil {
+			return nil, err
+		}
+		receipts, err := bs.ReadReceipts(hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range receipts {
+			for _, l := range r.Logs {
+				if !filter.Matches(l) {
+					continue
+				}
+				if len(logs) == max {
+					return nil, ErrTooManyResults
+				}
+				logs = append(logs, &FilteredLog{Log: l, BlockHash: hash, Height: h, TxID: r.TxID, TxIndex: r.Index})
+			}
+		}
+	}
+	return logs, nil
+}
+
+// FindTransactions returns the IDs of the canonical transactions from height from to to inclusive sent or received by
+// address in chain order, ErrTooManyResults past max
+func (bs *BlockStore) FindTransactions(address [AddressLength]byte, from, to uint64, max int) ([][32]byte, error) {
+	heights, err := bs.candidates(from, to, bloomQuery{{address[:]}})
+	if err != nil {
+		return nil, err
+	}
+	txIDs := [][32]byte{}
+	for _, h := range heights {
+		b, err := bs.readCanonicalBlock(h)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range b.transactions {
+			if t.senderAddress != address && t.receiverAddress != address {
+				continue
+			}
+			if len(txIDs) == max {
+				return nil, ErrTooManyResults
+			}
+			txIDs = append(txIDs, t.ID)
+		}
+	}
+	return txIDs, nil
+}
+
+// Convert to protoreflect.Protomessage type for pb marshalling
+func (fl *FilteredLog) ConvertToFilteredLogPbMsg() *types.FilteredLogMsg {
+	return &types.FilteredLogMsg{
+		Log:       fl.Log.ConvertToLogPbMsg(),
+		BlockHash: fl.BlockHash[:],
+		Height:    fl.Height,
+		TxID:      fl.TxID[:],
+		TxIndex:   fl.TxIndex,
+	}
+}
+
+// Helper methods
+func (f *LogFilter) bloomQuery() bloomQuery {
+	var q bloomQuery
+	if len(f.Addresses) > 0 {
+		var group [][]byte
+		for i := range f.Addresses {
+			group = append(group, f.Addresses[i][:])
+		}
+		q = append(q, group)
+	}
+	for _, topics := range f.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		var group [][]byte
+		for i := range topics {
+			group = append(group, topics[i][:])
+		}
+		q = append(q, group)
+	}
+	return q
+}
+
+func (q bloomQuery) matches(bl *Bloom) bool {
+	for _, group := range q {
+		found := false
+		for _, item := range group {
+			if bl.Test(item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Canonical heights from from to to inclusive whose blocks may satisfy q. Indexed sections are matched by their bloom
+// bits, the blocks above them by the blooms in their headers
+func (bs *BlockStore) candidates(from, to uint64, q bloomQuery) ([]uint64, error) {
+	indexed, err := bs.ReadBloomSections()
+	if err != nil {
+		return nil, err
+	}
+	var heights []uint64
+	for h := from; h <= to; {
+		section := h / BloomSectionSize
+		if section >= indexed {
+			b, err := bs.readCanonicalBlock(h)
+			if err != nil {
+				return nil, err
+			}
+			if bl := b.bloom; !b.HasBloom() || q.matches(&bl) {
+				heights = append(heights, h)
+			}
+			h++
+			continue
+		}
+		match, err := bs.matchSection(section, q)
+		if err != nil {
+			return nil, err
+		}
+		end := (section+1)*BloomSectionSize - 1
+		if end > to {
+			end = to
+		}
+		for ; h <= end; h++ {
+			if i := h % BloomSectionSize; match[i/8]&(1<<(i%8)) != 0 {
+				heights = append(heights, h)
+			}
+		}
+	}
+	return heights, nil
+}
+
+func (bs *BlockStore) readCanonicalBlock(height uint64) (*Block, error) {
+	hash, err := bs.ReadCanonicalHash(height)
+	if err != nil {
+		return nil, err
+	}
+	return bs.ReadBlock(hash)
+}
+
+func containsAddress(addresses [][AddressLength]byte, address [AddressLength]byte) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTopic(topics [][32]byte, topic [32]byte) bool {
+	for _, t := range topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
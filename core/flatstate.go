@@ -0,0 +1,70 @@
+package core
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+/*
+Flat state
+The state tree keeps accounts under the hashes of their addresses, listing a state's accounts out of it means walking
+the whole tree, and a pruned store no longer has the trees of old states to rebuild from. The store keeps a second copy
+of the state as of the head as a flat run of address -> balance entries and each block rewrites the accounts its
+transactions touched, so a node restarts from the flat accounts rather than executing the whole chain again.
+
+Design considerations:
+-The flat accounts are written after the block they're as of is committed. Flat accounts as of any block but the head
+are stale and ignored, the state is then replayed and the flat accounts rewritten in full
+-An account in the state with nothing in it is still stored, only accounts missing from the state are deleted
+*/
+
+// WriteFlatState stores the accounts of state, the state as of head. Only the accounts touched by the transactions of
+// blocks are written, or every account if blocks is nil
+func (bs *BlockStore) WriteFlatState(head *Block, state *State, blocks []*Block) error {
+	batch := new(leveldb.Batch)
+	write := func(address [AddressLength]byte) {
+		if balance, ok := state.balances[address]; ok {
+			batch.Put(storeKey(flatPrefix, address[:]), encodeUint64(balance))
+		} else {
+			batch.Delete(storeKey(flatPrefix, address[:]))
+		}
+	}
+	if blocks == nil {
+		iter := bs.db.NewIterator(util.BytesPrefix(flatPrefix), nil)
+		for iter.Next() {
+			var address [AddressLength]byte
+			copy(address[:], iter.Key()[len(flatPrefix):])
+			write(address)
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+		for address := range state.balances {
+			write(address)
+		}
+	}
+	for _, b := range blocks {
+		for _, t := range b.transactions {
+			write(t.senderAddress)
+			write(t.receiverAddress)
+		}
+	}
+	batch.Put(flatHeadKey, head.ID[:])
+	return bs.db.Write(batch, nil)
+}
+
+// Read the hash of the block the flat accounts are as of and the state they hold, core.ErrNotFound if none were written
+func (bs *BlockStore) ReadFlatState() ([32]byte, *State, error) {
+	var hash [32]byte
+	data, err := bs.get(flatHeadKey)
+	if err != nil {
+		return hash, nil, err
+	}
+	copy(hash[:], data)
+	balances, err := bs.readBalances(flatPrefix)
+	if err != nil {
+		return hash, nil, err
+	}
+	return hash, NewStateWithNodes(bs.Nodes(), balances), nil
+}
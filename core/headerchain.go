@@ -0,0 +1,226 @@
+package core
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/liangalv/goChain/structures"
+)
+
+/*
+A HeaderChain follows the canonical chain of a store that keeps headers only, the chain of a light node.
+Headers are checked against the header rules and the history root as they come in and the branch with the most total
+difficulty is canonical, as on a full chain. Headers are persisted as they are inserted and the canonical ones are read
+back on start, so a restarted light node carries on from its head.
+*/
+type HeaderChain struct {
+	mux    sync.RWMutex
+	chain  []*Block //canonical headers
+	store  *BlockStore
+	events *EventBus
+
+	history      *structures.MountainRange
+	historyNodes structures.NodeStore
+}
+
+// Load the canonical headers in store, an empty store starts from genesis
+func NewHeaderChain(store *BlockStore, events *EventBus, genesis *Block) (*HeaderChain, error) {
+	hc := &HeaderChain{store: store, events: events, historyNodes: structures.NewMemNodeStore()}
+	hc.history = structures.NewMountainRange(hc.historyNodes)
+	if err := hc.loadChain(); err == nil {
+		return hc, nil
+	} else if err != ErrNotFound {
+		return nil, err
+	}
+	if err := store.CommitBlock(genesis, nil); err != nil {
+		return nil, err
+	}
+	hc.chain = []*Block{genesis}
+	if err := hc.history.Append(genesis.ID); err != nil {
+		return nil, err
+	}
+	return hc, nil
+}
+
+// InsertHeaders stores headers following on from a stored header and makes their branch canonical if it has more
+// total difficulty than the canonical chain
+func (hc *HeaderChain) InsertHeaders(headers []*Block) error {
+	hc.mux.Lock()
+	defer hc.mux.Unlock()
+	//Headers we already have may lead the batch, e.g. when a peer served the last of a previous one again
+	for len(headers) > 0 {
+		if _, err := hc.store.ReadBlock(headers[0].ID); err != nil {
+			break
+		}
+		headers = headers[1:]
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	parent, err := hc.store.ReadBlock(headers[0].ParentHash())
+	if err != nil {
+		return ErrUnknownParent
+	}
+	branch, err := hc.sideBranch(parent)
+	if err != nil {
+		return err
+	}
+	for _, h := range headers {
+		if err := h.VerifyHeader(parent); err != nil {
+			return err
+		}
+		parent = h
+	}
+	branch = append(branch, headers...)
+	fork := branch[0].Height()
+	base := hc.chain[:fork:fork]
+	//Only a branch off the head extends the history we already have
+	history := hc.history.Copy()
+	if fork != uint64(len(hc.chain)) {
+		if history, err = hc.replayHistory(base); err != nil {
+			return err
+		}
+	}
+	for _, b := range branch {
+		if err := b.VerifyHistory(history.Root()); err != nil {
+			return err
+		}
+		if err := history.Append(b.ID); err != nil {
+			return err
+		}
+	}
+	for _, h := range headers {
+		if err := hc.store.WriteBlock(h, nil); err != nil {
+			return err
+		}
+	}
+	if totalDifficulty(base)+totalDifficulty(branch) <= totalDifficulty(hc.chain) {
+		return nil
+	}
+	removed := make([]*Block, 0, uint64(len(hc.chain))-fork)
+	for i := len(hc.chain) - 1; i >= int(fork); i-- {
+		removed = append(removed, hc.chain[i])
+	}
+	if err := hc.store.Reorg(removed, branch); err != nil {
+		return err
+	}
+	hc.chain = append(base, branch...)
+	hc.history = history
+	if len(removed) > 0 {
+		hc.events.Publish(Event{Type: ReorgEvent, Removed: removed, Added: branch})
+		return nil
+	}
+	for _, h := range branch {
+		hc.events.Publish(Event{Type: NewHeadEvent, Block: h})
+	}
+	return nil
+}
+
+// Canonical header at height
+func (hc *HeaderChain) GetBlockByHeight(height uint64) (*Block, error) {
+	hc.mux.RLock()
+	defer hc.mux.RUnlock()
+	if height >= uint64(len(hc.chain)) {
+		return nil, ErrNotFound
+	}
+	return hc.chain[height], nil
+}
+
+func (hc *HeaderChain) GetBlockByHash(hash [32]byte) (*Block, error) {
+	return hc.store.ReadBlock(hash)
+}
+
+func (hc *HeaderChain) GenesisHash() [32]byte {
+	hc.mux.RLock()
+	defer hc.mux.RUnlock()
+	return hc.chain[0].ID
+}
+
+// Sum of the difficulty of every canonical header
+func (hc *HeaderChain) TotalDifficulty() uint64 {
+	hc.mux.RLock()
+	defer hc.mux.RUnlock()
+	return totalDifficulty(hc.chain)
+}
+
+func (hc *HeaderChain) LastBlock() *Block {
+	hc.mux.RLock()
+	defer hc.mux.RUnlock()
+	return hc.chain[len(hc.chain)-1]
+}
+
+// Helper Methods
+// Read the canonical headers back out of the store, returns ErrNotFound on an empty store
+func (hc *HeaderChain) loadChain() error {
+	headHash, err := hc.store.ReadHeadHash()
+	if err != nil {
+		return err
+	}
+	head, err := hc.store.ReadBlock(headHash)
+	if err != nil {
+		return err
+	}
+	chain := make([]*Block, 0, head.Height()+1)
+	for h := uint64(0); h <= head.Height(); h++ {
+		hash, err := hc.store.ReadCanonicalHash(h)
+		if err != nil {
+			return err
+		}
+		b, err := hc.store.ReadBlock(hash)
+		if err != nil {
+			return err
+		}
+		chain = append(chain, b)
+	}
+	history, err := hc.replayHistory(chain)
+	if err != nil {
+		return err
+	}
+	hc.chain = chain
+	hc.history = history
+	return nil
+}
+
+// Walk back from a stored header to where it joins the canonical chain, returns the non-canonical headers parent
+// first. Caller must hold hc.mux
+func (hc *HeaderChain) sideBranch(from *Block) ([]*Block, error) {
+	var branch []*Block
+	b := from
+	for b.Height() >= uint64(len(hc.chain)) || hc.chain[b.Height()].ID != b.ID {
+		if b.Height() == 0 {
+			return nil, errors.New("side chain does not share our genesis block")
+		}
+		branch = append(branch, b)
+		parent, err := hc.store.ReadBlock(b.ParentHash())
+		if err != nil {
+			return nil, err
+		}
+		b = parent
+	}
+	for i, j := 0, len(branch)-1; i < j; i, j = i+1, j-1 {
+		branch[i], branch[j] = branch[j], branch[i]
+	}
+	return branch, nil
+}
+
+// Rebuild the MountainRange of chain, which starts at genesis, checking every header commits to the ones before it
+func (hc *HeaderChain) replayHistory(chain []*Block) (*structures.MountainRange, error) {
+	history := structures.NewMountainRange(hc.historyNodes)
+	for _, b := range chain {
+		if err := b.VerifyHistory(history.Root()); err != nil {
+			return nil, err
+		}
+		if err := history.Append(b.ID); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+func totalDifficulty(chain []*Block) uint64 {
+	var td uint64
+	for _, b := range chain {
+		td += b.Difficulty()
+	}
+	return td
+}
@@ -0,0 +1,320 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	. "github.com/liangalv/goChain/core/types"
+)
+
+// JSON shapes returned by the gateway, every address and hash is 0x prefixed hex
+type blockJSON struct {
+	Hash         string            `json:"hash"`
+	Version      uint32            `json:"version"`
+	ParentHash   string            `json:"parentHash"`
+	Height       uint64            `json:"height"`
+	Timestamp    int64             `json:"timestamp"`
+	GasLimit     int32             `json:"gasLimit"`
+	Difficulty   uint64            `json:"difficulty"`
+	TrieRootHash string            `json:"trieRootHash"`
+	ReceiptsRoot string            `json:"receiptsRoot"`
+	HistoryRoot  string            `json:"historyRoot"`
+	Bloom        string            `json:"bloom"`
+	Transactions []transactionJSON `json:"transactions"`
+}
+
+type transactionJSON struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Value     uint32 `json:"value"`
+	Gas       uint32 `json:"gas"`
+}
+
+type includedTransactionJSON struct {
+	transactionJSON
+	BlockHash string `json:"blockHash"`
+	Index     uint32 `json:"index"`
+}
+
+type sendTransactionJSON struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value uint32 `json:"value"`
+	Gas   uint32 `json:"gas"`
+}
+
+// Topics are by position, each position lists the topics a log may have there and an empty one matches any topic
+type logFilterJSON struct {
+	From      uint64     `json:"fromBlock"`
+	To        uint64     `json:"toBlock"`
+	Addresses []string   `json:"addresses"`
+	Topics    [][]string `json:"topics"`
+}
+
+type logJSON struct {
+	Address   string   `json:"address"`
+	Topics    []string `json:"topics"`
+	Data      string   `json:"data"`
+	BlockHash string   `json:"blockHash"`
+	Height    uint64   `json:"height"`
+	TxID      string   `json:"txID"`
+	TxIndex   uint32   `json:"txIndex"`
+}
+
+type mempoolStatusJSON struct {
+	Pending  uint32 `json:"pending"`
+	Capacity uint32 `json:"capacity"`
+}
+
+// chain_getBlockByNumber [height | "latest"]
+func (s *Server) chainGetBlockByNumber(ctx context.Context, params json.RawMessage) (any, *Error) {
+	args, rpcErr := parseParams[json.RawMessage](params, 1)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	var res *BlockResponse
+	var err error
+	var height uint64
+	if string(args[0]) == `"latest"` {
+		res, err = s.bs.GetLatestBlock(ctx, &GetLatestBlockRequest{})
+	} else if json.Unmarshal(args[0], &height) == nil {
+		res, err = s.bs.GetBlockByHeight(ctx, &GetBlockByHeightRequest{Height: height})
+	} else {
+		return nil, invalidParams("block number must be a height or \"latest\"")
+	}
+	if rpcErr := checkStatus(res.GetStatus(), err); rpcErr != nil {
+		return nil, rpcErr
+	}
+	return encodeBlock(res.GetBlock()), nil
+}
+
+// tx_send [{from, to, value, gas}]
+func (s *Server) txSend(ctx context.Context, params json.RawMessage) (any, *Error) {
+	args, rpcErr := parseParams[sendTransactionJSON](params, 1)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	from, err := decodeHex(args[0].From)
+	if err != nil {
+		return nil, invalidParams("from: " + err.Error())
+	}
+	to, err := decodeHex(args[0].To)
+	if err != nil {
+		return nil, invalidParams("to: " + err.Error())
+	}
+	res, err := s.ts.CreateTransaction(ctx, &CreateTransactionRequest{
+		SenderAddress:   from,
+		ReceiverAddress: to,
+		Value:           args[0].Value,
+		MaxGas:          args[0].Gas,
+	})
+	if rpcErr := checkStatus(res.GetStatus(), err); rpcErr != nil {
+		return nil, rpcErr
+	}
+	return encodeHex(res.GetTxID()), nil
+}
+
+// tx_get [txID]
+func (s *Server) txGet(ctx context.Context, params json.RawMessage) (any, *Error) {
+	txID, rpcErr := parseHexParam(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	res, err := s.ts.GetTransaction(ctx, &GetTransactionRequest{TxID: txID})
+	if rpcErr := checkStatus(res.GetStatus(), err); rpcErr != nil {
+		return nil, rpcErr
+	}
+	return includedTransactionJSON{
+		transactionJSON: encodeTransaction(res.GetTransaction()),
+		BlockHash:       encodeHex(res.GetBlockHash()),
+		Index:           res.GetIndex(),
+	}, nil
+}
+
+// chain_getLogs [{fromBlock, toBlock, addresses, topics}]
+func (s *Server) chainGetLogs(ctx context.Context, params json.RawMessage) (any, *Error) {
+	args, rpcErr := parseParams[logFilterJSON](params, 1)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	req := &GetLogsRequest{From: args[0].From, To: args[0].To}
+	for _, a := range args[0].Addresses {
+		address, err := decodeHex(a)
+		if err != nil {
+			return nil, invalidParams("addresses: " + err.Error())
+		}
+		req.Addresses = append(req.Addresses, address)
+	}
+	for _, position := range args[0].Topics {
+		set := &TopicSet{}
+		for _, t := range position {
+			topic, err := decodeHex(t)
+			if err != nil {
+				return nil, invalidParams("topics: " + err.Error())
+			}
+			set.Topics = append(set.Topics, topic)
+		}
+		req.Topics = append(req.Topics, set)
+	}
+	res, err := s.bs.GetLogs(ctx, req)
+	if rpcErr := checkStatus(res.GetStatus(), err); rpcErr != nil {
+		return nil, rpcErr
+	}
+	logs := []logJSON{}
+	for _, l := range res.GetLogs() {
+		lj := logJSON{
+			Address:   encodeHex(l.GetLog().GetAddress()),
+			Topics:    []string{},
+			Data:      encodeHex(l.GetLog().GetData()),
+			BlockHash: encodeHex(l.GetBlockHash()),
+			Height:    l.GetHeight(),
+			TxID:      encodeHex(l.GetTxID()),
+			TxIndex:   l.GetTxIndex(),
+		}
+		for _, t := range l.GetLog().GetTopics() {
+			lj.Topics = append(lj.Topics, encodeHex(t))
+		}
+		logs = append(logs, lj)
+	}
+	return logs, nil
+}
+
+// account_findTransactions [address, fromBlock, toBlock]
+func (s *Server) accountFindTransactions(ctx context.Context, params json.RawMessage) (any, *Error) {
+	args, rpcErr := parseParams[json.RawMessage](params, 3)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	var hexAddress string
+	var from, to uint64
+	if json.Unmarshal(args[0], &hexAddress) != nil || json.Unmarshal(args[1], &from) != nil || json.Unmarshal(args[2], &to) != nil {
+		return nil, invalidParams("params must be an address and two heights")
+	}
+	address, err := decodeHex(hexAddress)
+	if err != nil {
+		return nil, invalidParams(err.Error())
+	}
+	res, err := s.as.FindTransactions(ctx, &FindTransactionsRequest{Address: address, From: from, To: to})
+	if rpcErr := checkStatus(res.GetStatus(), err); rpcErr != nil {
+		return nil, rpcErr
+	}
+	txIDs := []string{}
+	for _, id := range res.GetTxIDs() {
+		txIDs = append(txIDs, encodeHex(id))
+	}
+	return txIDs, nil
+}
+
+// account_getBalance [address]
+func (s *Server) accountGetBalance(ctx context.Context, params json.RawMessage) (any, *Error) {
+	address, rpcErr := parseHexParam(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	res, err := s.as.GetBalance(ctx, &GetBalanceRequest{Address: address})
+	if rpcErr := checkStatus(res.GetStatus(), err); rpcErr != nil {
+		return nil, rpcErr
+	}
+	return res.GetBalance(), nil
+}
+
+// mempool_status []
+func (s *Server) mempoolStatus(ctx context.Context, params json.RawMessage) (any, *Error) {
+	res, err := s.ts.GetMemPoolStatus(ctx, &MemPoolStatusRequest{})
+	if rpcErr := checkStatus(res.GetStatus(), err); rpcErr != nil {
+		return nil, rpcErr
+	}
+	return mempoolStatusJSON{Pending: res.GetPending(), Capacity: res.GetCapacity()}, nil
+}
+
+// Helper methods
+// Params are positional, at least n of them must be present
+func parseParams[T any](params json.RawMessage, n int) ([]T, *Error) {
+	var args []T
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, invalidParams("params must be a positional array")
+	}
+	if len(args) < n {
+		return nil, invalidParams("missing params")
+	}
+	return args, nil
+}
+
+func parseHexParam(params json.RawMessage) ([]byte, *Error) {
+	args, rpcErr := parseParams[string](params, 1)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	b, err := decodeHex(args[0])
+	if err != nil {
+		return nil, invalidParams(err.Error())
+	}
+	return b, nil
+}
+
+// Map a service response status onto a JSON-RPC error
+func checkStatus(status Status, err error) *Error {
+	if err != nil {
+		return &Error{Code: codeInternalError, Message: err.Error()}
+	}
+	switch status {
+	case Status_SUCCESS:
+		return nil
+	case Status_INVALID_REQUEST:
+		return invalidParams("invalid request")
+	default:
+		return &Error{Code: codeRequestFailed, Message: "request failed"}
+	}
+}
+
+func invalidParams(message string) *Error {
+	return &Error{Code: codeInvalidParams, Message: message}
+}
+
+func encodeHex(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+func decodeHex(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return nil, errors.New("hex string must be 0x prefixed")
+	}
+	return hex.DecodeString(s[2:])
+}
+
+func encodeBlock(msg *BlockMsg) blockJSON {
+	b := blockJSON{
+		Hash:         encodeHex(msg.GetID()),
+		Version:      msg.GetVersion(),
+		ParentHash:   encodeHex(msg.GetParentHash()),
+		Height:       msg.GetHeight(),
+		Timestamp:    msg.GetTimestamp(),
+		GasLimit:     msg.GetGasLimit(),
+		Difficulty:   msg.GetDifficulty(),
+		TrieRootHash: encodeHex(msg.GetTrieRootHash()),
+		ReceiptsRoot: encodeHex(msg.GetReceiptsRoot()),
+		HistoryRoot:  encodeHex(msg.GetHistoryRoot()),
+		Bloom:        encodeHex(msg.GetBloom()),
+		Transactions: []transactionJSON{},
+	}
+	for _, t := range msg.GetTransactions() {
+		b.Transactions = append(b.Transactions, encodeTransaction(t))
+	}
+	return b
+}
+
+func encodeTransaction(msg *TransactionMsg) transactionJSON {
+	return transactionJSON{
+		ID:        encodeHex(msg.GetID()),
+		Timestamp: msg.GetTimestamp(),
+		From:      encodeHex(msg.GetSenderAddress()),
+		To:        encodeHex(msg.GetReceiverAddress()),
+		Value:     msg.GetValue(),
+		Gas:       msg.GetGas(),
+	}
+}
@@ -0,0 +1,174 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/liangalv/goChain/core/services"
+)
+
+/*
+A JSON-RPC 2.0 gateway in front of the gRPC services.
+Methods call straight into the same service handlers the gRPC server uses, so both APIs always agree,
+the gateway only translates params into pb requests and pb responses into hex encoded JSON.
+*/
+
+const (
+	version = "2.0"
+	//Largest request body (single or batch) the gateway will read
+	maxRequestSize = 1 << 20
+)
+
+// Standard JSON-RPC 2.0 error codes, -32000 is the start of the server defined range
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+	codeRequestFailed  = -32000
+)
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type request struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type handler func(ctx context.Context, params json.RawMessage) (any, *Error)
+
+type Server struct {
+	ts      *services.TransactionService
+	as      *services.AccountService
+	bs      *services.BlockService
+	methods map[string]handler
+}
+
+func NewServer(ts *services.TransactionService, as *services.AccountService, bs *services.BlockService) *Server {
+	s := &Server{ts: ts, as: as, bs: bs}
+	s.methods = map[string]handler{
+		"chain_getBlockByNumber":   s.chainGetBlockByNumber,
+		"chain_getLogs":            s.chainGetLogs,
+		"tx_send":                  s.txSend,
+		"tx_get":                   s.txGet,
+		"account_getBalance":       s.accountGetBalance,
+		"account_findTransactions": s.accountFindTransactions,
+		"mempool_status":           s.mempoolStatus,
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "JSON-RPC requests must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	var res any
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		res = s.handleBatch(r.Context(), body)
+	} else {
+		res = s.handleSingle(r.Context(), body)
+	}
+	//Nothing is written back when every request was a notification
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// Helper methods
+// Returns nil (not a nil *response) when there is nothing to respond with
+func (s *Server) handleSingle(ctx context.Context, body []byte) any {
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return errorResponse(nil, codeParseError, "parse error")
+	}
+	if res := s.call(ctx, &req); res != nil {
+		return res
+	}
+	return nil
+}
+
+func (s *Server) handleBatch(ctx context.Context, body []byte) any {
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return errorResponse(nil, codeParseError, "parse error")
+	}
+	if len(batch) == 0 {
+		return errorResponse(nil, codeInvalidRequest, "empty batch")
+	}
+	responses := make([]*response, 0, len(batch))
+	for _, raw := range batch {
+		var req request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			responses = append(responses, errorResponse(nil, codeInvalidRequest, "invalid request"))
+			continue
+		}
+		if res := s.call(ctx, &req); res != nil {
+			responses = append(responses, res)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
+
+// Dispatch a request, notifications (no id) are executed but get no response
+func (s *Server) call(ctx context.Context, req *request) *response {
+	if req.Version != version || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "invalid request")
+	}
+	h, ok := s.methods[req.Method]
+	if !ok {
+		if req.ID == nil {
+			return nil
+		}
+		return errorResponse(req.ID, codeMethodNotFound, "method not found: "+req.Method)
+	}
+	result, rpcErr := h(ctx, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	if rpcErr != nil {
+		return &response{Version: version, Error: rpcErr, ID: req.ID}
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, codeInternalError, err.Error())
+	}
+	return &response{Version: version, Result: data, ID: req.ID}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{Version: version, Error: &Error{Code: code, Message: message}, ID: id}
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/structures"
+)
+
+/*
+A light client keeps nothing but headers, so whatever it's asked about is fetched from a full node along with a proof
+against one of the roots in its headers. A transaction is proven by its receipt, which names the transaction and sits
+under the block's receiptsRoot, and a balance by the account's path in the state tree under a block's state root.
+*/
+
+// A TxProof shows a transaction was executed in a block
+type TxProof struct {
+	Transaction *Transaction
+	Receipt     *Receipt
+	BlockHash   [32]byte
+	//Proof of the receipt's hash in the block's receipts tree
+	Proof *structures.MultiProof
+}
+
+// An AccountProof shows the balance of an account in the state with the given root, an absent account has none
+type AccountProof struct {
+	Root    [32]byte
+	Account StateAccount
+	Proof   *structures.SparseProof
+}
+
+// Proof of the transaction at index of b, whose receipts are receipts
+func NewTxProof(b *Block, receipts []*Receipt, index uint32) (*TxProof, error) {
+	if int(index) >= len(b.transactions) || len(receipts) != len(b.transactions) {
+		return nil, fmt.Errorf("block %x has no transaction %d", b.ID[:4], index)
+	}
+	tt := structures.NewTransactionTree(b.TreeMode())
+	for _, r := range receipts {
+		if err := tt.Add(r.Hash()); err != nil {
+			return nil, err
+		}
+	}
+	if err := tt.Construct(); err != nil {
+		return nil, err
+	}
+	proof, err := tt.ConstructMultiProof([][32]byte{receipts[index].Hash()})
+	if err != nil {
+		return nil, err
+	}
+	return &TxProof{Transaction: b.transactions[index], Receipt: receipts[index], BlockHash: b.ID, Proof: proof}, nil
+}
+
+// Verify checks the proof against header, the block the transaction is claimed to be in
+func (tp *TxProof) Verify(header *Block) error {
+	if tp.BlockHash != header.ID {
+		return fmt.Errorf("proof is for block %x, not %x", tp.BlockHash[:4], header.ID[:4])
+	}
+	if tp.Receipt.TxID != tp.Transaction.ID {
+		return errors.New("receipt is for a different transaction")
+	}
+	if len(tp.Proof.Indices) != 1 || tp.Proof.Indices[0] != int(tp.Receipt.Index) {
+		return errors.New("receipt is proven at a different index")
+	}
+	if !structures.VerifyMultiProof(header.TreeMode(), header.receiptsRoot, [][32]byte{tp.Receipt.Hash()}, tp.Proof) {
+		return fmt.Errorf("receipt is not in block %x", header.ID[:4])
+	}
+	return nil
+}
+
+// Proof of the balance of address in the state
+func (s *State) ProveAccount(address [AddressLength]byte) (*AccountProof, error) {
+	proof, err := s.AccountProof(address)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountProof{Root: s.Root(), Account: StateAccount{Address: address, Balance: s.Balance(address)}, Proof: proof}, nil
+}
+
+// ProveAccountAt proves the balance of address in the state with the given root from its state tree in nodes alone,
+// for states no longer held in memory. core.ErrNotFound if the tree isn't kept
+func ProveAccountAt(nodes structures.NodeStore, root [32]byte, address [AddressLength]byte) (*AccountProof, error) {
+	if _, err := nodes.Get(root); err != nil {
+		return nil, ErrNotFound
+	}
+	tree := structures.NewSparseMerkleTree(nodes, root)
+	leaf, err := tree.Get(AccountKey(address))
+	if err != nil {
+		return nil, err
+	}
+	proof, err := tree.ConstructProof(AccountKey(address))
+	if err != nil {
+		return nil, err
+	}
+	account := StateAccount{Address: address}
+	if leaf != ([32]byte{}) {
+		account = accountFromLeaf(leaf)
+	}
+	return &AccountProof{Root: root, Account: account, Proof: proof}, nil
+}
+
+// Verify checks the proof against root, the state root of a block
+func (ap *AccountProof) Verify(root [32]byte) error {
+	if ap.Root != root {
+		return fmt.Errorf("proof is for state %x, not %x", ap.Root[:4], root[:4])
+	}
+	//The account may be in the state with nothing in it, or not be in it at all
+	if VerifyAccountProof(root, ap.Account, ap.Proof) {
+		return nil
+	}
+	if ap.Account.Balance == 0 && VerifyAbsenceProof(root, ap.Account.Address, ap.Proof) {
+		return nil
+	}
+	return fmt.Errorf("balance of %x is not proven by state %x", ap.Account.Address[:4], root[:4])
+}
+
+// Convert from the pb message, a message with no transaction means the peer didn't have it
+func NewTxProofFromPbMsg(msg *types.TxProof) (*TxProof, error) {
+	if msg.GetTransaction() == nil || msg.GetReceipt() == nil || msg.GetProof() == nil {
+		return nil, ErrNotFound
+	}
+	tp := &TxProof{
+		Transaction: NewTransactionFromPbMsg(msg.GetTransaction()),
+		Receipt:     NewReceiptFromPbMsg(msg.GetReceipt()),
+		Proof:       &structures.MultiProof{LeafCount: int(msg.GetProof().GetLeafCount())},
+	}
+	if len(msg.GetBlockHash()) != 32 {
+		return nil, errors.New("malformed block hash")
+	}
+	copy(tp.BlockHash[:], msg.GetBlockHash())
+	//The receipt's block hash isn't part of what it commits to, so it's set from the proven block
+	tp.Receipt.BlockHash = tp.BlockHash
+	for _, ind := range msg.GetProof().GetIndices() {
+		tp.Proof.Indices = append(tp.Proof.Indices, int(ind))
+	}
+	for _, h := range msg.GetProof().GetHashes() {
+		if len(h) != 32 {
+			return nil, errors.New("malformed proof hash")
+		}
+		var hash [32]byte
+		copy(hash[:], h)
+		tp.Proof.Hashes = append(tp.Proof.Hashes, hash)
+	}
+	return tp, nil
+}
+
+// Convert to protoreflect.Protomessage type for pb marshalling
+func (tp *TxProof) ConvertToTxProofPbMsg() *types.TxProof {
+	proof := &types.MultiProofMsg{LeafCount: uint64(tp.Proof.LeafCount)}
+	for _, ind := range tp.Proof.Indices {
+		proof.Indices = append(proof.Indices, uint64(ind))
+	}
+	//Index into Hashes, slicing the range variable would alias the same array each iteration
+	for i := range tp.Proof.Hashes {
+		proof.Hashes = append(proof.Hashes, tp.Proof.Hashes[i][:])
+	}
+	return &types.TxProof{
+		Transaction: tp.Transaction.ConvertToTransactionPbMsg(),
+		Receipt:     tp.Receipt.ConvertToReceiptPbMsg(),
+		BlockHash:   tp.BlockHash[:],
+		Proof:       proof,
+	}
+}
+
+// Convert from the pb message, a message marked unavailable means the peer doesn't have the state anymore
+func NewAccountProofFromPbMsg(msg *types.AccountProof) (*AccountProof, error) {
+	if msg.GetUnavailable() {
+		return nil, ErrNotFound
+	}
+	if len(msg.GetRoot()) != 32 || len(msg.GetAddress()) != AddressLength {
+		return nil, errors.New("malformed account proof")
+	}
+	ap := &AccountProof{Account: StateAccount{Balance: msg.GetBalance()}, Proof: &structures.SparseProof{}}
+	copy(ap.Root[:], msg.GetRoot())
+	copy(ap.Account.Address[:], msg.GetAddress())
+	if len(msg.GetLeafKey()) != 0 || len(msg.GetLeafValue()) != 0 {
+		if len(msg.GetLeafKey()) != 32 || len(msg.GetLeafValue()) != 32 {
+			return nil, errors.New("malformed proof leaf")
+		}
+		copy(ap.Proof.LeafKey[:], msg.GetLeafKey())
+		copy(ap.Proof.LeafValue[:], msg.GetLeafValue())
+	}
+	for _, s := range msg.GetSiblings() {
+		if len(s) != 32 {
+			return nil, errors.New("malformed proof sibling")
+		}
+		var sibling [32]byte
+		copy(sibling[:], s)
+		ap.Proof.Siblings = append(ap.Proof.Siblings, sibling)
+	}
+	return ap, nil
+}
+
+// Convert to protoreflect.Protomessage type for pb marshalling
+func (ap *AccountProof) ConvertToAccountProofPbMsg() *types.AccountProof {
+	msg := &types.AccountProof{Root: ap.Root[:], Address: ap.Account.Address[:], Balance: ap.Account.Balance}
+	for i := range ap.Proof.Siblings {
+		msg.Siblings = append(msg.Siblings, ap.Proof.Siblings[i][:])
+	}
+	if ap.Proof.LeafValue != ([32]byte{}) {
+		msg.LeafKey, msg.LeafValue = ap.Proof.LeafKey[:], ap.Proof.LeafValue[:]
+	}
+	return msg
+}
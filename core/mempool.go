@@ -2,8 +2,10 @@ package core
 
 import (
 	"container/heap"
+	"errors"
 	"log"
 	"sync"
+	"time"
 )
 
 /*
@@ -17,34 +19,147 @@ we have to find a way to see if a transaction has already been seen, but not in
 -is there a way to do this with minmal overhead is LevelDb the only solution?
 */
 
+const (
+	//MaxPoolSize caps the number of pending transactions, the lowest gas transactions are evicted past it
+	MaxPoolSize = 10000
+)
+
 // A memPool implements a priority queue using gas as the priority to determine which transactions are added to the creation of a block
+// mux guards the exported methods, the heap.Interface methods assume it is already held
 type MemPool struct {
 	mux          sync.Mutex
 	transactions []*Transaction //each pointer is 8 bytes
 	validator    *Account
 	idToTransMap map[[32]byte]*Transaction //each entry has 32 byte key and 8 byte pointer
+	addedAt      map[[32]byte]time.Time    //when each transaction entered the pool
+	events       *EventBus
 }
 
-func NewMemPool(acc *Account) *MemPool {
+// events may be nil if nothing needs to observe the pool
+func NewMemPool(acc *Account, events *EventBus) *MemPool {
 	return &MemPool{
 		validator:    acc,
 		transactions: []*Transaction{},
 		idToTransMap: map[[32]byte]*Transaction{},
+		addedAt:      map[[32]byte]time.Time{},
+		events:       events,
 	}
 }
 
-func (mp *MemPool) AddTransactionToPool(t *Transaction) {
+func (mp *MemPool) AddTransactionToPool(t *Transaction) error {
+	mp.mux.Lock()
+	defer mp.mux.Unlock()
+	if _, ok := mp.idToTransMap[t.ID]; ok {
+		return errors.New("transaction is already in the mempool")
+	}
+	//Make room by evicting the cheapest transaction, unless the new one is cheaper still
+	if len(mp.transactions) >= MaxPoolSize {
+		lowest := mp.lowestGasIndex()
+		if mp.transactions[lowest].gas >= t.gas {
+			return errors.New("mempool is full")
+		}
+		mp.evict(lowest)
+	}
 	heap.Push(mp, t)
+	mp.events.Publish(Event{Type: TxAddedEvent, Transaction: t})
+	return nil
 }
 
 func (mp *MemPool) RemoveHighestGasTransaction() (*Transaction, error) {
+	mp.mux.Lock()
+	defer mp.mux.Unlock()
+	if len(mp.transactions) == 0 {
+		return nil, errors.New("no transactions found in mempool")
+	}
 	return heap.Pop(mp).(*Transaction), nil
 }
 
-// Implement Heap Interface
-func (mp *MemPool) Pop() any {
+// Number of pending transactions, safe to call concurrently unlike Len
+func (mp *MemPool) Size() int {
+	mp.mux.Lock()
+	defer mp.mux.Unlock()
+	return len(mp.transactions)
+}
+
+// Look up a pending transaction by ID
+func (mp *MemPool) GetTransaction(id [32]byte) (*Transaction, bool) {
 	mp.mux.Lock()
 	defer mp.mux.Unlock()
+	t, ok := mp.idToTransMap[id]
+	return t, ok
+}
+
+// Snapshot of every pending transaction, in no particular order
+func (mp *MemPool) Pending() []*Transaction {
+	mp.mux.Lock()
+	defer mp.mux.Unlock()
+	trans := make([]*Transaction, 0, len(mp.idToTransMap))
+	for _, t := range mp.idToTransMap {
+		trans = append(trans, t)
+	}
+	return trans
+}
+
+// Drop a transaction from the pool without it being included in a block
+func (mp *MemPool) EvictTransaction(id [32]byte) error {
+	mp.mux.Lock()
+	defer mp.mux.Unlock()
+	t, ok := mp.idToTransMap[id]
+	if !ok {
+		return errors.New("transaction was not found in the mempool")
+	}
+	mp.evict(t.index)
+	return nil
+}
+
+// Drop transactions that made it into a block, unlike EvictTransaction no TxEvictedEvent is published
+func (mp *MemPool) RemoveIncluded(trans []*Transaction) {
+	mp.mux.Lock()
+	defer mp.mux.Unlock()
+	for _, t := range trans {
+		if pooled, ok := mp.idToTransMap[t.ID]; ok {
+			heap.Remove(mp, pooled.index)
+		}
+	}
+}
+
+// Drop transactions that have waited in the pool for longer than maxAge, publishing a TxEvictedEvent for each as
+// EvictTransaction does. Returns how many were dropped
+func (mp *MemPool) Expire(maxAge time.Duration) int {
+	mp.mux.Lock()
+	defer mp.mux.Unlock()
+	cutoff := time.Now().Add(-maxAge)
+	var expired []*Transaction
+	for id, at := range mp.addedAt {
+		if at.Before(cutoff) {
+			expired = append(expired, mp.idToTransMap[id])
+		}
+	}
+	for _, t := range expired {
+		mp.evict(t.index)
+	}
+	return len(expired)
+}
+
+// Helper methods, caller must hold mp.mux
+func (mp *MemPool) evict(i int) {
+	t := heap.Remove(mp, i).(*Transaction)
+	mp.events.Publish(Event{Type: TxEvictedEvent, Transaction: t})
+}
+
+// The heap only orders the highest gas so finding the lowest is a linear scan over the leaves
+func (mp *MemPool) lowestGasIndex() int {
+	lowest := len(mp.transactions) / 2
+	for i := lowest + 1; i < len(mp.transactions); i++ {
+		if mp.transactions[i].gas < mp.transactions[lowest].gas {
+			lowest = i
+		}
+	}
+	return lowest
+}
+
+// Implement Heap Interface
+func (mp *MemPool) Pop() any {
 	if len(mp.transactions) == 0 {
 		log.Printf("No transactions found in mempool")
 		return nil
@@ -58,15 +173,14 @@ func (mp *MemPool) Pop() any {
 	//Configure new Slice
 	mp.transactions = mp.transactions[0:lastIndex]
 
-	//Delete map entry
+	//Delete map entries
 	delete(mp.idToTransMap, trans.ID)
+	delete(mp.addedAt, trans.ID)
 
 	return trans
 }
 
 func (mp *MemPool) Push(x any) {
-	mp.mux.Lock()
-	defer mp.mux.Unlock()
 	trans, ok := x.(*Transaction)
 	if !ok {
 		log.Println("Attempted to add non-transaction object to mempool")
@@ -77,6 +191,7 @@ func (mp *MemPool) Push(x any) {
 	trans.index = n
 	mp.transactions = append(mp.transactions, trans)
 	mp.idToTransMap[trans.ID] = trans
+	mp.addedAt[trans.ID] = time.Now()
 }
 
 //Implement Sort Interface for heap.Interface
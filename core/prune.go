@@ -0,0 +1,166 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/liangalv/goChain/structures"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+/*
+State pruning
+Every executed block writes a new path of state tree nodes up to its state root and nodes are never modified, so a
+store that keeps every node keeps the state as of every block, and grows with every block. An archive store does keep
+them all. A pruned store only keeps the states of the most recent blocks, which peers snap sync and prove balances
+against, and of periodic checkpoints, sweeping the nodes of every other state away. How many recent states and how far
+apart the checkpoints are is up to the node, and recorded along with the mode.
+
+Design considerations:
+-Nodes are stored by hash and shared between states, an unchanged subtree is the same nodes in every state after it,
+so a node can't be deleted because a state it was written for is gone. Pruning marks every node reachable from the
+roots kept and deletes the rest
+-A root whose nodes were never written, such as the state of a header a snap synced chain skipped, is passed over
+-The mode is recorded in the store so a node can't unknowingly start on a store kept the other way
+-Pruning sweeps every node in the store so blocks keep being imported while it runs. Nodes are only deleted while the
+caller keeps new states from being written, after marking every state kept at that point, so a state written during
+the sweep whose nodes it had already passed over as unreachable isn't swept away with them
+*/
+
+// StateMode is which states a store keeps the state trees of
+type StateMode byte
+
+const (
+	//PrunedState keeps the states of recent blocks and periodic checkpoints
+	PrunedState StateMode = iota + 1
+	//ArchiveState keeps the state as of every block
+	ArchiveState
+	//HeadersOnly keeps no state, the store of a light chain
+	HeadersOnly
+)
+
+// StateConfig is which states a store keeps
+type StateConfig struct {
+	Mode StateMode
+	//A pruned store keeps the states of the History most recent blocks and of every Checkpoint'th block
+	History    uint64
+	Checkpoint uint64
+}
+
+// Most deletes written in one batch while pruning
+const pruneBatchSize = 4096
+
+func (m StateMode) String() string {
+	switch m {
+	case PrunedState:
+		return "pruned"
+	case ArchiveState:
+		return "archive"
+	case HeadersOnly:
+		return "headers only"
+	default:
+		return "unknown"
+	}
+}
+
+// Record which states the store keeps
+func (bs *BlockStore) WriteStateConfig(cfg StateConfig) error {
+	data := []byte{byte(cfg.Mode)}
+	data = binary.BigEndian.AppendUint64(data, cfg.History)
+	data = binary.BigEndian.AppendUint64(data, cfg.Checkpoint)
+	return bs.db.Put(stateModeKey, data, nil)
+}
+
+// Read which states the store keeps, core.ErrNotFound if it was never recorded. A store that only recorded its mode
+// reads with no History or Checkpoint
+func (bs *BlockStore) ReadStateConfig() (StateConfig, error) {
+	data, err := bs.get(stateModeKey)
+	if err != nil {
+		return StateConfig{}, err
+	}
+	switch len(data) {
+	case 1:
+		return StateConfig{Mode: StateMode(data[0])}, nil
+	case 17:
+		return StateConfig{
+			Mode:       StateMode(data[0]),
+			History:    binary.BigEndian.Uint64(data[1:9]),
+			Checkpoint: binary.BigEndian.Uint64(data[9:]),
+		}, nil
+	default:
+		return StateConfig{}, errors.New("corrupt state mode")
+	}
+}
+
+/*
+PruneState deletes every state tree node that isn't part of the state of one of the roots keep returns, returns how many
+were deleted. keep is only called with guard held, and guard is held while nodes are deleted, so the caller has to
+keep new states from being written while it is held
+*/
+func (bs *BlockStore) PruneState(keep func() [][32]byte, guard sync.Locker) (int, error) {
+	nodes := bs.Nodes()
+	marked := map[[32]byte]bool{}
+	//Subtrees already marked are skipped, so marking again only walks the nodes written since
+	mark := func() error {
+		for _, root := range keep() {
+			if _, err := nodes.Get(root); err == ErrNotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if err := structures.NewSparseMerkleTree(nodes, root).MarkNodes(marked); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	guard.Lock()
+	err := mark()
+	guard.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	//Delete the unmarked nodes of candidates, once the states written since the last mark are marked as well
+	sweep := func(candidates [][32]byte) error {
+		guard.Lock()
+		defer guard.Unlock()
+		if err := mark(); err != nil {
+			return err
+		}
+		batch := new(leveldb.Batch)
+		for _, hash := range candidates {
+			if !marked[hash] {
+				batch.Delete(storeKey(nodePrefix, hash[:]))
+			}
+		}
+		if err := bs.db.Write(batch, nil); err != nil {
+			return err
+		}
+		pruned += batch.Len()
+		return nil
+	}
+	iter := bs.db.NewIterator(util.BytesPrefix(nodePrefix), nil)
+	defer iter.Release()
+	candidates := make([][32]byte, 0, pruneBatchSize)
+	for iter.Next() {
+		var hash [32]byte
+		copy(hash[:], iter.Key()[len(nodePrefix):])
+		if marked[hash] {
+			continue
+		}
+		candidates = append(candidates, hash)
+		if len(candidates) == pruneBatchSize {
+			if err := sweep(candidates); err != nil {
+				return pruned, err
+			}
+			candidates = candidates[:0]
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return pruned, err
+	}
+	return pruned, sweep(candidates)
+}
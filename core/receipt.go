@@ -0,0 +1,164 @@
+package core
+
+import (
+	"encoding/binary"
+
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/structures"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	//TxGas is the flat amount of gas charged to execute a transfer
+	TxGas = 21000
+)
+
+// TransferTopic is the first topic of the log emitted by every successful transfer
+var TransferTopic = sha3.Sum256([]byte("Transfer(address,address,uint32)"))
+
+type ReceiptStatus uint8
+
+const (
+	ReceiptFailed ReceiptStatus = iota
+	ReceiptSucceeded
+)
+
+// A Log is an event emitted while executing a transaction
+type Log struct {
+	Address [AddressLength]byte
+	Topics  [][32]byte
+	Data    []byte
+}
+
+// A Receipt records the outcome of a transaction once it has been included in a block
+type Receipt struct {
+	TxID              [32]byte
+	BlockHash         [32]byte
+	Index             uint32
+	Status            ReceiptStatus
+	GasUsed           uint64
+	CumulativeGasUsed uint64
+	Logs              []*Log
+}
+
+// ApplyTransaction executes the transaction at position index of a block against state and returns its receipt
+// TODO: charge gas against the sender once there is a gas price
+func ApplyTransaction(state *State, t *Transaction, index uint32, cumulativeGasUsed uint64) *Receipt {
+	r := &Receipt{
+		TxID:  t.ID,
+		Index: index,
+	}
+	//A transaction that can't cover the base cost runs out of gas and consumes everything it offered
+	if uint64(t.gas) < TxGas {
+		r.Status = ReceiptFailed
+		r.GasUsed = uint64(t.gas)
+	} else if !state.transfer(t.senderAddress, t.receiverAddress, uint64(t.value)) {
+		r.Status = ReceiptFailed
+		r.GasUsed = TxGas
+	} else {
+		r.Status = ReceiptSucceeded
+		r.GasUsed = TxGas
+		r.Logs = []*Log{transferLog(t)}
+	}
+	r.CumulativeGasUsed = cumulativeGasUsed + r.GasUsed
+	return r
+}
+
+// Hash of the receipt committed to by the receiptsRoot, the block hash is omitted as it depends on the root
+func (r *Receipt) Hash() [32]byte {
+	msg := r.ConvertToReceiptPbMsg()
+	msg.BlockHash = nil
+	data, _ := proto.Marshal(msg)
+	return sha3.Sum256(data)
+}
+
+// ReceiptsRoot computes the MerkleRoot of the receipts as a block of the current version commits to it, an empty block
+// has an empty root
+func ReceiptsRoot(receipts []*Receipt) [32]byte {
+	return receiptsRoot((&Block{version: BlockVersion}).TreeMode(), receipts)
+}
+
+func receiptsRoot(mode structures.TreeMode, receipts []*Receipt) [32]byte {
+	if len(receipts) == 0 {
+		return [32]byte{}
+	}
+	tt := structures.NewTransactionTree(mode)
+	for _, r := range receipts {
+		h := r.Hash()
+		//Receipts are unique per transaction so Add can only fail on a duplicate transaction
+		if err := tt.Add(h); err != nil {
+			continue
+		}
+	}
+	if err := tt.Construct(); err != nil {
+		return [32]byte{}
+	}
+	return tt.Root()
+}
+
+// Helper methods
+func transferLog(t *Transaction) *Log {
+	var sender, receiver [32]byte
+	copy(sender[12:], t.senderAddress[:])
+	copy(receiver[12:], t.receiverAddress[:])
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, t.value)
+	return &Log{
+		Address: t.senderAddress,
+		Topics:  [][32]byte{TransferTopic, sender, receiver},
+		Data:    data,
+	}
+}
+
+// Convert to protoreflect.Protomessage type for pb marshalling
+func (r *Receipt) ConvertToReceiptPbMsg() *types.ReceiptMsg {
+	msg := &types.ReceiptMsg{
+		TxID:              r.TxID[:],
+		BlockHash:         r.BlockHash[:],
+		Index:             r.Index,
+		Status:            types.ReceiptStatus(r.Status),
+		GasUsed:           r.GasUsed,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+	}
+	for _, l := range r.Logs {
+		msg.Logs = append(msg.Logs, l.ConvertToLogPbMsg())
+	}
+	return msg
+}
+
+// Convert to protoreflect.Protomessage type for pb marshalling
+func (l *Log) ConvertToLogPbMsg() *types.LogMsg {
+	msg := &types.LogMsg{
+		Address: l.Address[:],
+		Data:    l.Data,
+	}
+	//Index into Topics, slicing the range variable would alias the same array each iteration
+	for i := range l.Topics {
+		msg.Topics = append(msg.Topics, l.Topics[i][:])
+	}
+	return msg
+}
+
+// Convert from the pb message back into a Receipt
+func NewReceiptFromPbMsg(msg *types.ReceiptMsg) *Receipt {
+	r := &Receipt{
+		Index:             msg.GetIndex(),
+		Status:            ReceiptStatus(msg.GetStatus()),
+		GasUsed:           msg.GetGasUsed(),
+		CumulativeGasUsed: msg.GetCumulativeGasUsed(),
+	}
+	copy(r.TxID[:], msg.GetTxID())
+	copy(r.BlockHash[:], msg.GetBlockHash())
+	for _, lm := range msg.GetLogs() {
+		l := &Log{Data: lm.GetData()}
+		copy(l.Address[:], lm.GetAddress())
+		for _, topic := range lm.GetTopics() {
+			var t [32]byte
+			copy(t[:], topic)
+			l.Topics = append(l.Topics, t)
+		}
+		r.Logs = append(r.Logs, l)
+	}
+	return r
+}
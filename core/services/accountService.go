@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/liangalv/goChain/core"
+	. "github.com/liangalv/goChain/core/types"
+)
+
+type AccountService struct {
+	UnimplementedAccountServiceServer
+	chain Chain
+}
+
+func NewAccountService(chain Chain) *AccountService {
+	return &AccountService{chain: chain}
+}
+
+func (as *AccountService) GetAddressHistory(ctx context.Context, req *GetAddressHistoryRequest) (*AddressHistoryResponse, error) {
+	address, ok := toAddress(req.GetAddress())
+	if !ok {
+		return &AddressHistoryResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	history, err := as.chain.GetAddressHistory(address)
+	//Too long a history has to be searched for by block range instead
+	if errors.Is(err, core.ErrTooManyResults) {
+		return &AddressHistoryResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	if err != nil {
+		return &AddressHistoryResponse{Status: Status_FAILURE}, nil
+	}
+	res := &AddressHistoryResponse{Status: Status_SUCCESS}
+	for i := range history {
+		res.TxIDs = append(res.TxIDs, history[i][:])
+	}
+	return res, nil
+}
+
+func (as *AccountService) FindTransactions(ctx context.Context, req *FindTransactionsRequest) (*AddressHistoryResponse, error) {
+	address, ok := toAddress(req.GetAddress())
+	if !ok || req.GetFrom() > req.GetTo() {
+		return &AddressHistoryResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	txIDs, err := as.chain.FindTransactions(address, req.GetFrom(), req.GetTo())
+	if errors.Is(err, core.ErrTooManyResults) {
+		return &AddressHistoryResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	if err != nil {
+		return &AddressHistoryResponse{Status: Status_FAILURE}, nil
+	}
+	res := &AddressHistoryResponse{Status: Status_SUCCESS}
+	for i := range txIDs {
+		res.TxIDs = append(res.TxIDs, txIDs[i][:])
+	}
+	return res, nil
+}
+
+func (as *AccountService) GetBalance(ctx context.Context, req *GetBalanceRequest) (*BalanceResponse, error) {
+	address, ok := toAddress(req.GetAddress())
+	if !ok {
+		return &BalanceResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	balance, err := as.chain.GetBalance(address)
+	if err != nil {
+		return &BalanceResponse{Status: Status_FAILURE}, nil
+	}
+	return &BalanceResponse{Balance: balance, Status: Status_SUCCESS}, nil
+}
+
+// Helper
+// Converts a pb bytes field into an address, rejecting anything that isn't exactly AddressLength bytes
+func toAddress(b []byte) ([core.AddressLength]byte, bool) {
+	var address [core.AddressLength]byte
+	if len(b) != len(address) {
+		return address, false
+	}
+	copy(address[:], b)
+	return address, true
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+
+	. "github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/p2p"
+)
+
+// Network is the view of the p2p server the admin service manages
+type Network interface {
+	Peers() []*p2p.Peer
+	PeerBook() *p2p.PeerBook
+}
+
+type AdminService struct {
+	UnimplementedAdminServiceServer
+	network Network
+}
+
+func NewAdminService(network Network) *AdminService {
+	return &AdminService{network: network}
+}
+
+func (as *AdminService) ListPeers(ctx context.Context, req *ListPeersRequest) (*ListPeersResponse, error) {
+	res := &ListPeersResponse{Status: Status_SUCCESS}
+	for _, p := range as.network.Peers() {
+		id := p.ID()
+		height, _ := p.Head()
+		var caps []string
+		for _, c := range p.Capabilities() {
+			caps = append(caps, c.String())
+		}
+		res.Peers = append(res.Peers, &PeerInfo{
+			NodeID:          id[:],
+			Addr:            p.Addr(),
+			ListenAddr:      p.ListenAddr(),
+			Inbound:         p.Inbound(),
+			Score:           p.Score(),
+			HeadHeight:      height,
+			TotalDifficulty: p.TotalDifficulty(),
+			Capabilities:    caps,
+		})
+	}
+	for _, r := range as.network.PeerBook().BannedRecords() {
+		//Records are only written by the book itself so the node ID is always valid hex
+		nodeID, _ := hex.DecodeString(r.NodeID)
+		res.Banned = append(res.Banned, &BanInfo{
+			Addr:        r.Addr,
+			NodeID:      nodeID,
+			BannedUntil: r.BannedUntil.Unix(),
+			Bans:        uint32(r.Bans),
+		})
+	}
+	return res, nil
+}
+
+func (as *AdminService) Unban(ctx context.Context, req *UnbanRequest) (*UnbanResponse, error) {
+	if req.GetAddr() == "" {
+		return &UnbanResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	if !as.network.PeerBook().Unban(req.GetAddr()) {
+		return &UnbanResponse{Status: Status_FAILURE}, nil
+	}
+	return &UnbanResponse{Status: Status_SUCCESS}, nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/liangalv/goChain/core"
+	. "github.com/liangalv/goChain/core/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxBlockRange is the most blocks a single GetBlockRange streams, longer ranges are requested in parts
+const MaxBlockRange = 1000
+
+type BlockService struct {
+	UnimplementedBlockServiceServer
+	chain Chain
+}
+
+func NewBlockService(chain Chain) *BlockService {
+	return &BlockService{chain: chain}
+}
+
+func (bs *BlockService) GetBlockByHash(ctx context.Context, req *GetBlockByHashRequest) (*BlockResponse, error) {
+	hash, ok := toHash(req.GetHash())
+	if !ok {
+		return &BlockResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	b, err := bs.chain.GetBlockByHash(hash)
+	if err != nil {
+		return &BlockResponse{Status: Status_FAILURE}, nil
+	}
+	return &BlockResponse{Block: b.ConvertToBlockPbMsg(), Status: Status_SUCCESS}, nil
+}
+
+func (bs *BlockService) GetBlockByHeight(ctx context.Context, req *GetBlockByHeightRequest) (*BlockResponse, error) {
+	b, err := bs.chain.GetBlockByHeight(req.GetHeight())
+	if err != nil {
+		return &BlockResponse{Status: Status_FAILURE}, nil
+	}
+	return &BlockResponse{Block: b.ConvertToBlockPbMsg(), Status: Status_SUCCESS}, nil
+}
+
+func (bs *BlockService) GetLatestBlock(ctx context.Context, req *GetLatestBlockRequest) (*BlockResponse, error) {
+	return &BlockResponse{Block: bs.chain.LastBlock().ConvertToBlockPbMsg(), Status: Status_SUCCESS}, nil
+}
+
+// Streams have no response status to fill in, so failures are reported as grpc status errors
+func (bs *BlockService) GetBlockRange(req *GetBlockRangeRequest, stream BlockService_GetBlockRangeServer) error {
+	if req.GetFrom() > req.GetTo() {
+		return status.Errorf(codes.InvalidArgument, "invalid range: from %d is after to %d", req.GetFrom(), req.GetTo())
+	}
+	if head := bs.chain.LastBlock().Height(); req.GetTo() > head {
+		return status.Errorf(codes.OutOfRange, "invalid range: to %d is past the head at %d", req.GetTo(), head)
+	}
+	if req.GetTo()-req.GetFrom() >= MaxBlockRange {
+		return status.Errorf(codes.InvalidArgument, "invalid range: more than %d blocks", MaxBlockRange)
+	}
+	for h := req.GetFrom(); h <= req.GetTo(); h++ {
+		//Stop as soon as the client goes away rather than reading blocks nobody will receive
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		b, err := bs.chain.GetBlockByHeight(h)
+		if err != nil {
+			//The chain may have been reorged shorter while streaming
+			return status.Errorf(codes.NotFound, "block at height %d: %v", h, err)
+		}
+		if err := stream.Send(b.ConvertToBlockPbMsg()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bs *BlockService) GetChainInfo(ctx context.Context, req *GetChainInfoRequest) (*ChainInfoResponse, error) {
+	head := bs.chain.LastBlock()
+	return &ChainInfoResponse{
+		Height:          head.Height(),
+		HeadHash:        head.ID[:],
+		ChainID:         bs.chain.ChainID(),
+		TotalDifficulty: bs.chain.TotalDifficulty(),
+		Status:          Status_SUCCESS,
+	}, nil
+}
+
+func (bs *BlockService) GetLogs(ctx context.Context, req *GetLogsRequest) (*LogsResponse, error) {
+	if req.GetFrom() > req.GetTo() {
+		return &LogsResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	filter := &core.LogFilter{From: req.GetFrom(), To: req.GetTo()}
+	for _, raw := range req.GetAddresses() {
+		address, ok := toAddress(raw)
+		if !ok {
+			return &LogsResponse{Status: Status_INVALID_REQUEST}, nil
+		}
+		filter.Addresses = append(filter.Addresses, address)
+	}
+	for _, set := range req.GetTopics() {
+		var topics [][32]byte
+		for _, raw := range set.GetTopics() {
+			topic, ok := toHash(raw)
+			if !ok {
+				return &LogsResponse{Status: Status_INVALID_REQUEST}, nil
+			}
+			topics = append(topics, topic)
+		}
+		filter.Topics = append(filter.Topics, topics)
+	}
+	logs, err := bs.chain.GetLogs(filter)
+	if errors.Is(err, core.ErrTooManyResults) {
+		return &LogsResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	if err != nil {
+		return &LogsResponse{Status: Status_FAILURE}, nil
+	}
+	res := &LogsResponse{Status: Status_SUCCESS}
+	for _, l := range logs {
+		res.Logs = append(res.Logs, l.ConvertToFilteredLogPbMsg())
+	}
+	return res, nil
+}
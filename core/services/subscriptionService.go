@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"github.com/liangalv/goChain/core"
+	. "github.com/liangalv/goChain/core/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	//Number of events a subscriber may fall behind before it is dropped
+	subscriptionBuffer = 256
+)
+
+type SubscriptionService struct {
+	UnimplementedSubscriptionServiceServer
+	events *core.EventBus
+}
+
+func NewSubscriptionService(events *core.EventBus) *SubscriptionService {
+	return &SubscriptionService{events: events}
+}
+
+func (ss *SubscriptionService) SubscribeNewHeads(req *SubscribeNewHeadsRequest, stream SubscriptionService_SubscribeNewHeadsServer) error {
+	return ss.subscribe(stream.Context(), func(e core.Event) error {
+		switch e.Type {
+		case core.NewHeadEvent:
+			return stream.Send(&HeadEvent{Block: e.Block.ConvertToBlockPbMsg()})
+		case core.ReorgEvent:
+			for i, b := range e.Added {
+				head := &HeadEvent{Block: b.ConvertToBlockPbMsg()}
+				if i == 0 {
+					head.RemovedHashes = blockHashes(e.Removed)
+				}
+				if err := stream.Send(head); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (ss *SubscriptionService) SubscribePendingTransactions(req *SubscribePendingTransactionsRequest, stream SubscriptionService_SubscribePendingTransactionsServer) error {
+	return ss.subscribe(stream.Context(), func(e core.Event) error {
+		if e.Type != core.TxAddedEvent {
+			return nil
+		}
+		return stream.Send(e.Transaction.ConvertToTransactionPbMsg())
+	})
+}
+
+func (ss *SubscriptionService) SubscribeAddress(req *SubscribeAddressRequest, stream SubscriptionService_SubscribeAddressServer) error {
+	address, ok := toAddress(req.GetAddress())
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "address must be %d bytes", core.AddressLength)
+	}
+	//Sends an event for every transaction in the block that touches the address
+	sendBlock := func(b *core.Block, removed bool) error {
+		for _, t := range b.Transactions() {
+			if !involves(t, address) {
+				continue
+			}
+			if err := stream.Send(&AddressEvent{Transaction: t.ConvertToTransactionPbMsg(), BlockHash: b.ID[:], Removed: removed}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return ss.subscribe(stream.Context(), func(e core.Event) error {
+		switch e.Type {
+		case core.NewHeadEvent:
+			return sendBlock(e.Block, false)
+		case core.ReorgEvent:
+			for _, b := range e.Removed {
+				if err := sendBlock(b, true); err != nil {
+					return err
+				}
+			}
+			for _, b := range e.Added {
+				if err := sendBlock(b, false); err != nil {
+					return err
+				}
+			}
+		case core.TxAddedEvent, core.TxEvictedEvent:
+			if involves(e.Transaction, address) {
+				return stream.Send(&AddressEvent{Transaction: e.Transaction.ConvertToTransactionPbMsg(), Removed: e.Type == core.TxEvictedEvent})
+			}
+		}
+		return nil
+	})
+}
+
+// Helper methods
+// Feeds bus events to handle until the client goes away, the handler fails or the subscriber lags behind
+func (ss *SubscriptionService) subscribe(ctx context.Context, handle func(core.Event) error) error {
+	sub := ss.events.Subscribe(subscriptionBuffer)
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-sub.Events():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, sub.Err().Error())
+			}
+			if err := handle(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func involves(t *core.Transaction, address [core.AddressLength]byte) bool {
+	return t.Sender() == address || t.Receiver() == address
+}
+
+func blockHashes(blocks []*core.Block) [][]byte {
+	hashes := make([][]byte, 0, len(blocks))
+	for _, b := range blocks {
+		hashes = append(hashes, b.ID[:])
+	}
+	return hashes
+}
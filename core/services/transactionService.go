@@ -6,16 +6,120 @@ import (
 	. "github.com/liangalv/goChain/core/types"
 )
 
-type TransactionService struct{}
+// Chain is the view of the BlockChain the services are backed by
+type Chain interface {
+	GetReceipt(txID [32]byte) (*core.Receipt, error)
+	GetTransaction(txID [32]byte) (*core.Transaction, *core.TxLookup, error)
+	GetBlockByHash(hash [32]byte) (*core.Block, error)
+	GetBlockByHeight(height uint64) (*core.Block, error)
+	LastBlock() *core.Block
+	ChainID() uint64
+	TotalDifficulty() uint64
+	//Every transaction of an address, core.ErrTooManyResults if there are more than fit one response
+	GetAddressHistory(address [core.AddressLength]byte) ([][32]byte, error)
+	//Balance as of the head, a light chain fetches it with a proof and may fail to
+	GetBalance(address [core.AddressLength]byte) (uint64, error)
+	//Searches of a range of canonical blocks, core.ErrTooManyResults if the range has to be narrowed
+	GetLogs(filter *core.LogFilter) ([]*core.FilteredLog, error)
+	FindTransactions(address [core.AddressLength]byte, from, to uint64) ([][32]byte, error)
+	AddTransaction(t *core.Transaction) error
+	PendingTransactions() int
+}
+
+type TransactionService struct {
+	UnimplementedTransactionServiceServer
+	chain Chain
+}
+
+func NewTransactionService(chain Chain) *TransactionService {
+	return &TransactionService{chain: chain}
+}
 
 func (ts *TransactionService) CreateTransaction(ctx context.Context, req *CreateTransactionRequest) (*TransactionResponse, error) {
-	//validate transaction and throw it into the channel that sucks it up in the mempool
-	//the channel that sends to the mempool should not be buffered, it's already inherently a buffer
+	sender, receiver, ok := basicValidate(req)
+	if !ok {
+		return &TransactionResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	t := core.NewTransaction(0, req.GetValue(), req.GetMaxGas(), sender, receiver)
+	if err := ts.chain.AddTransaction(t); err != nil {
+		return &TransactionResponse{Status: Status_FAILURE}, nil
+	}
+	return &TransactionResponse{Status: Status_SUCCESS, TxID: t.ID[:]}, nil
+}
 
+// Accepts transactions relayed by another node, the batch is rejected outright if any of them could never execute.
+// Transactions the pool already holds or has no room for are skipped, they aren't the sender's fault
+func (ts *TransactionService) SendTransactions(ctx context.Context, req *TransactionBatch) (*TransactionResponse, error) {
+	trans := make([]*core.Transaction, 0, len(req.GetBatch()))
+	for _, msg := range req.GetBatch() {
+		if core.ValidateTransactionMsg(msg) != nil {
+			return &TransactionResponse{Status: Status_INVALID_REQUEST}, nil
+		}
+		trans = append(trans, core.NewTransactionFromPbMsg(msg))
+	}
+	for _, t := range trans {
+		ts.chain.AddTransaction(t)
+	}
 	return &TransactionResponse{Status: Status_SUCCESS}, nil
 }
 
-// TODO: Basic Validation for incoming transactions
-func basicValidate(req *CreateAccountRequest) {
+func (ts *TransactionService) GetReceipt(ctx context.Context, req *GetReceiptRequest) (*ReceiptResponse, error) {
+	txID, ok := toHash(req.GetTxID())
+	if !ok {
+		return &ReceiptResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	r, err := ts.chain.GetReceipt(txID)
+	if err != nil {
+		return &ReceiptResponse{Status: Status_FAILURE}, nil
+	}
+	return &ReceiptResponse{Receipt: r.ConvertToReceiptPbMsg(), Status: Status_SUCCESS}, nil
+}
+
+func (ts *TransactionService) GetTransaction(ctx context.Context, req *GetTransactionRequest) (*GetTransactionResponse, error) {
+	txID, ok := toHash(req.GetTxID())
+	if !ok {
+		return &GetTransactionResponse{Status: Status_INVALID_REQUEST}, nil
+	}
+	t, lookup, err := ts.chain.GetTransaction(txID)
+	if err != nil {
+		return &GetTransactionResponse{Status: Status_FAILURE}, nil
+	}
+	return &GetTransactionResponse{
+		Transaction: t.ConvertToTransactionPbMsg(),
+		BlockHash:   lookup.BlockHash[:],
+		Index:       lookup.Index,
+		Status:      Status_SUCCESS,
+	}, nil
+}
+
+func (ts *TransactionService) GetMemPoolStatus(ctx context.Context, req *MemPoolStatusRequest) (*MemPoolStatusResponse, error) {
+	return &MemPoolStatusResponse{
+		Pending:  uint32(ts.chain.PendingTransactions()),
+		Capacity: core.MaxPoolSize,
+		Status:   Status_SUCCESS,
+	}, nil
+}
+
+// Basic Validation for incoming transactions, anything that can never execute is rejected before the mempool
+func basicValidate(req *CreateTransactionRequest) (sender, receiver [core.AddressLength]byte, ok bool) {
+	sender, ok = toAddress(req.GetSenderAddress())
+	if !ok {
+		return sender, receiver, false
+	}
+	receiver, ok = toAddress(req.GetReceiverAddress())
+	if !ok {
+		return sender, receiver, false
+	}
+	return sender, receiver, req.GetMaxGas() >= core.TxGas
+}
 
+// Helper
+// Converts a pb bytes field into a hash, rejecting anything that isn't exactly 32 bytes
+func toHash(b []byte) ([32]byte, bool) {
+	var h [32]byte
+	if len(b) != len(h) {
+		return h, false
+	}
+	copy(h[:], b)
+	return h, true
 }
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/liangalv/goChain/structures"
+)
+
+// State holds the balance of every account as of some block
+// Balances live in memory, the store keeps the state tree and a flat copy of the head's accounts to load them back from
+type State struct {
+	balances map[[AddressLength]byte]uint64
+
+	//The state tree commits to every account, it is brought up to date with the accounts changed since the last
+	//update whenever the root is read. A state's root may be read from several goroutines so mux guards both
+	mux   sync.Mutex
+	tree  *structures.SparseMerkleTree
+	dirty map[[AddressLength]byte]bool
+}
+
+// Creates the state before the genesis block, alloc funds the initial accounts. The state tree is held in memory
+func NewState(alloc map[[AddressLength]byte]uint64) *State {
+	return NewStateWithNodes(structures.NewMemNodeStore(), alloc)
+}
+
+// Creates the state before the genesis block with its state tree stored in nodes
+func NewStateWithNodes(nodes structures.NodeStore, alloc map[[AddressLength]byte]uint64) *State {
+	s := &State{
+		balances: make(map[[AddressLength]byte]uint64, len(alloc)),
+		tree:     structures.NewSparseMerkleTree(nodes, [32]byte{}),
+		dirty:    make(map[[AddressLength]byte]bool, len(alloc)),
+	}
+	for address, balance := range alloc {
+		s.balances[address] = balance
+		s.dirty[address] = true
+	}
+	return s
+}
+
+func (s *State) Balance(address [AddressLength]byte) uint64 {
+	return s.balances[address]
+}
+
+func (s *State) Copy() *State {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	c := &State{
+		balances: make(map[[AddressLength]byte]uint64, len(s.balances)),
+		tree:     s.tree.Copy(),
+		dirty:    make(map[[AddressLength]byte]bool, len(s.dirty)),
+	}
+	for address, balance := range s.balances {
+		c.balances[address] = balance
+	}
+	for address := range s.dirty {
+		c.dirty[address] = true
+	}
+	return c
+}
+
+// A copy of the state with its state tree stored in nodes
+func (s *State) CopyTo(nodes structures.NodeStore) *State {
+	return NewStateWithNodes(nodes, s.balances)
+}
+
+// Root of the state tree, which commits to every account
+// Panics if the tree's nodes can't be read, without them there is no way to commit to the state
+func (s *State) Root() [32]byte {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err := s.updateTree(); err != nil {
+		panic(fmt.Sprintf("state tree is unreadable: %v", err))
+	}
+	return s.tree.Root()
+}
+
+// Proof of the account at address against the state root, for an address without an account it proves there is none
+func (s *State) AccountProof(address [AddressLength]byte) (*structures.SparseProof, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err := s.updateTree(); err != nil {
+		return nil, err
+	}
+	return s.tree.ConstructProof(AccountKey(address))
+}
+
+// VerifyAccountProof checks proof shows the account is in the state with the given root
+func VerifyAccountProof(root [32]byte, account StateAccount, proof *structures.SparseProof) bool {
+	return structures.VerifySparseProof(root, AccountKey(account.Address), account.leaf(), proof)
+}
+
+// VerifyAbsenceProof checks proof shows address has no account in the state with the given root
+func VerifyAbsenceProof(root [32]byte, address [AddressLength]byte, proof *structures.SparseProof) bool {
+	return structures.VerifySparseProof(root, AccountKey(address), [32]byte{}, proof)
+}
+
+// Helper
+// Moves value between accounts, fails without touching either balance if the sender can't cover it
+func (s *State) transfer(from, to [AddressLength]byte, value uint64) bool {
+	if s.balances[from] < value {
+		return false
+	}
+	s.balances[from] -= value
+	s.balances[to] += value
+	s.mux.Lock()
+	s.dirty[from], s.dirty[to] = true, true
+	s.mux.Unlock()
+	return true
+}
+
+// Write the accounts changed since the last update to the tree, caller must hold s.mux
+func (s *State) updateTree() error {
+	for address := range s.dirty {
+		account := StateAccount{Address: address, Balance: s.balances[address]}
+		if err := s.tree.Update(AccountKey(address), account.leaf()); err != nil {
+			return err
+		}
+		delete(s.dirty, address)
+	}
+	return nil
+}
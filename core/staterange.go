@@ -0,0 +1,137 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/structures"
+	"golang.org/x/crypto/sha3"
+)
+
+/*
+The state root is the root of a sparse Merkle tree holding every account at the hash of its address. A leaf holds the
+whole account, its address, its balance and a trailing 1 so an account with nothing in it still differs from an empty
+leaf. Since the tree's shape only depends on the keys, a run of accounts in key order can be proven to be every account
+between two keys with a range proof, and a syncing node can download the state in ranges, verifying each against the
+root in a block header.
+*/
+
+// A StateAccount is one entry of the state
+type StateAccount struct {
+	Address [AddressLength]byte
+	Balance uint64
+}
+
+// An AccountRange is a run of consecutive accounts of a state in key order and the proof they belong to its root
+type AccountRange struct {
+	//Key the range starts from
+	Origin   [32]byte
+	Accounts []StateAccount
+	//The range runs to the end of the state
+	Last bool
+	//Number of accounts in the whole state. It isn't covered by the proof, so it's only good for showing progress
+	Total uint64
+	Proof [][]byte
+}
+
+// AccountKey is where the account at address sits in the state tree
+func AccountKey(address [AddressLength]byte) [32]byte {
+	return sha3.Sum256(address[:])
+}
+
+// Up to max accounts in key order from origin, with the proof they belong to the state's root
+func (s *State) AccountRange(origin [32]byte, max int) (*AccountRange, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err := s.updateTree(); err != nil {
+		return nil, err
+	}
+	sr, err := s.tree.ConstructRangeProof(origin, max)
+	if err != nil {
+		return nil, err
+	}
+	r := &AccountRange{Origin: origin, Last: sr.Last, Total: uint64(len(s.balances)), Proof: sr.Proof}
+	for _, leaf := range sr.Values {
+		r.Accounts = append(r.Accounts, accountFromLeaf(leaf))
+	}
+	return r, nil
+}
+
+// Verify the range belongs to the state with the given root
+func (r *AccountRange) Verify(root [32]byte) error {
+	sr := &structures.SparseRange{Origin: r.Origin, Last: r.Last, Proof: r.Proof}
+	for _, a := range r.Accounts {
+		sr.Keys, sr.Values = append(sr.Keys, AccountKey(a.Address)), append(sr.Values, a.leaf())
+	}
+	got, err := sr.Root()
+	if err != nil {
+		return err
+	}
+	if got != root {
+		return fmt.Errorf("account range proves root %x, expected %x", got[:4], root[:4])
+	}
+	return nil
+}
+
+// Origin of the range that follows on from this one, false if this range runs to the end of the state
+func (r *AccountRange) Next() ([32]byte, bool) {
+	if r.Last || len(r.Accounts) == 0 {
+		return [32]byte{}, false
+	}
+	next := AccountKey(r.Accounts[len(r.Accounts)-1].Address)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next, true
+		}
+	}
+	//The last account had the highest possible key
+	return [32]byte{}, false
+}
+
+// Convert from the pb message back into an AccountRange, malformed origins and addresses are rejected
+func NewAccountRangeFromPbMsg(msg *types.AccountRange) (*AccountRange, error) {
+	r := &AccountRange{Last: msg.GetLast(), Total: msg.GetTotal()}
+	if len(msg.GetOrigin()) != 32 {
+		return nil, errors.New("malformed range origin")
+	}
+	copy(r.Origin[:], msg.GetOrigin())
+	for _, am := range msg.GetAccounts() {
+		if len(am.GetAddress()) != AddressLength {
+			return nil, errors.New("malformed account address")
+		}
+		a := StateAccount{Balance: am.GetBalance()}
+		copy(a.Address[:], am.GetAddress())
+		r.Accounts = append(r.Accounts, a)
+	}
+	//Proof nodes are checked as the range is verified
+	r.Proof = msg.GetProof()
+	return r, nil
+}
+
+// Convert to protoreflect.Protomessage type for pb marshalling
+func (r *AccountRange) ConvertToAccountRangePbMsg() *types.AccountRange {
+	msg := &types.AccountRange{Origin: r.Origin[:], Last: r.Last, Total: r.Total, Proof: r.Proof}
+	for i := range r.Accounts {
+		msg.Accounts = append(msg.Accounts, &types.AccountMsg{Address: r.Accounts[i].Address[:], Balance: r.Accounts[i].Balance})
+	}
+	return msg
+}
+
+// Helper functions
+// The state tree leaf holding the account
+func (a StateAccount) leaf() [32]byte {
+	var leaf [32]byte
+	copy(leaf[:], a.Address[:])
+	binary.BigEndian.PutUint64(leaf[AddressLength:], a.Balance)
+	leaf[31] = 1
+	return leaf
+}
+
+func accountFromLeaf(leaf [32]byte) StateAccount {
+	a := StateAccount{Balance: binary.BigEndian.Uint64(leaf[AddressLength:])}
+	copy(a.Address[:], leaf[:AddressLength])
+	return a
+}
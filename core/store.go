@@ -0,0 +1,338 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/structures"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNotFound is returned when a lookup has no entry in the store
+var ErrNotFound = errors.New("not found")
+
+// Key prefixes, every key is prefix + identifier
+var (
+	blockPrefix    = []byte("b") //blockPrefix + blockHash -> BlockMsg
+	receiptsPrefix = []byte("r") //receiptsPrefix + blockHash -> ReceiptList
+
+	//Secondary indexes, these only ever describe the canonical chain
+	txLookupPrefix = []byte("t") //txLookupPrefix + txID -> blockHash + index
+	heightPrefix   = []byte("n") //heightPrefix + height -> blockHash
+	addressPrefix  = []byte("a") //addressPrefix + address + height + index -> txID
+	headKey        = []byte("H") //headKey -> blockHash of the canonical head
+
+	//State of a snap synced chain as of the block it was synced at, blocks up to that one are stored as headers only
+	snapshotKey = []byte("S") //snapshotKey -> blockHash of the block the state was synced at
+	statePrefix = []byte("s") //statePrefix + address -> balance
+
+	//Blooms of complete sections of the canonical chain, transposed so there is a row per bit, see bloombits.go
+	bloomBitsPrefix  = []byte("B") //bloomBitsPrefix + section + bit -> which blocks of the section have the bit set
+	bloomSectionsKey = []byte("I") //bloomSectionsKey -> number of sections indexed
+
+	//Nodes of the state trees, shared by every state kept in the store, which states are kept depends on the mode
+	nodePrefix   = []byte("m") //nodePrefix + nodeHash -> state tree node
+	stateModeKey = []byte("M") //stateModeKey -> StateMode, see prune.go
+
+	//Accounts of the state as of the head kept flat, so they're read back without replaying the chain
+	flatHeadKey = []byte("F") //flatHeadKey -> blockHash of the block the flat accounts are as of
+	flatPrefix  = []byte("f") //flatPrefix + address -> balance
+)
+
+// A TxLookup locates a canonical transaction within the chain
+type TxLookup struct {
+	BlockHash [32]byte
+	Index     uint32
+}
+
+// A BlockStore persists chain data in LevelDB
+type BlockStore struct {
+	db *leveldb.DB
+}
+
+// Opens (or creates) a BlockStore in the directory at path
+func OpenBlockStore(path string) (*BlockStore, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockStore{db: db}, nil
+}
+
+// Creates a BlockStore held entirely in memory, used for tests and ephemeral nodes
+func NewMemBlockStore() *BlockStore {
+	//Opening a fresh memory storage cannot fail
+	db, _ := leveldb.Open(storage.NewMemStorage(), nil)
+	return &BlockStore{db: db}
+}
+
+func (bs *BlockStore) Close() error {
+	return bs.db.Close()
+}
+
+// Persist a block and its receipts without making it part of the canonical chain, used for side chains
+func (bs *BlockStore) WriteBlock(b *Block, receipts []*Receipt) error {
+	batch := new(leveldb.Batch)
+	if err := writeBlockData(batch, b, receipts); err != nil {
+		return err
+	}
+	return bs.db.Write(batch, nil)
+}
+
+// Persist a block and its receipts and make it the canonical head, the indexes are updated in the same batch
+func (bs *BlockStore) CommitBlock(b *Block, receipts []*Receipt) error {
+	batch := new(leveldb.Batch)
+	if err := writeBlockData(batch, b, receipts); err != nil {
+		return err
+	}
+	writeIndexes(batch, b)
+	batch.Put(headKey, b.ID[:])
+	return bs.db.Write(batch, nil)
+}
+
+// Reorg atomically replaces the canonical blocks in removed (head first) with the blocks in added (parent first)
+// Every block in added must already have been persisted with WriteBlock
+func (bs *BlockStore) Reorg(removed []*Block, added []*Block) error {
+	if len(added) == 0 {
+		return errors.New("reorg requires at least one new canonical block")
+	}
+	batch := new(leveldb.Batch)
+	//Deletes are applied before puts within a batch so heights shared by both branches end up pointing at the new block
+	for _, b := range removed {
+		deleteIndexes(batch, b)
+	}
+	if err := bs.rewindBloomSections(batch, added[0].height); err != nil {
+		return err
+	}
+	for _, b := range added {
+		writeIndexes(batch, b)
+	}
+	head := added[len(added)-1]
+	batch.Put(headKey, head.ID[:])
+	return bs.db.Write(batch, nil)
+}
+
+// CommitSnapshot makes a run of headers from the genesis block on the canonical chain, with the last one as the head
+// and state as its state. The headers are stored without bodies or receipts, it is meant for a fresh store
+func (bs *BlockStore) CommitSnapshot(headers []*Block, state *State) error {
+	if len(headers) == 0 {
+		return errors.New("snapshot requires at least one header")
+	}
+	batch := new(leveldb.Batch)
+	for _, h := range headers {
+		header := *h
+		header.transactions = nil
+		if err := writeBlockData(batch, &header, nil); err != nil {
+			return err
+		}
+		writeIndexes(batch, &header)
+	}
+	for address, balance := range state.balances {
+		batch.Put(storeKey(statePrefix, address[:]), encodeUint64(balance))
+	}
+	head := headers[len(headers)-1]
+	batch.Put(snapshotKey, head.ID[:])
+	batch.Put(headKey, head.ID[:])
+	return bs.db.Write(batch, nil)
+}
+
+// Read the hash of the block the chain was snap synced at and the state as of it, core.ErrNotFound if it never was
+func (bs *BlockStore) ReadSnapshot() ([32]byte, *State, error) {
+	var hash [32]byte
+	data, err := bs.get(snapshotKey)
+	if err != nil {
+		return hash, nil, err
+	}
+	copy(hash[:], data)
+	balances, err := bs.readBalances(statePrefix)
+	if err != nil {
+		return hash, nil, err
+	}
+	return hash, NewStateWithNodes(bs.Nodes(), balances), nil
+}
+
+// Nodes is where states keep their state trees in the store, any of the structures trees can be kept there
+func (bs *BlockStore) Nodes() structures.NodeStore {
+	return nodeStore{bs}
+}
+
+// Read any persisted block, canonical or not
+func (bs *BlockStore) ReadBlock(blockHash [32]byte) (*Block, error) {
+	data, err := bs.get(storeKey(blockPrefix, blockHash[:]))
+	if err != nil {
+		return nil, err
+	}
+	msg := &types.BlockMsg{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return NewBlockFromPbMsg(msg), nil
+}
+
+// Read the hash of the canonical head
+func (bs *BlockStore) ReadHeadHash() ([32]byte, error) {
+	var hash [32]byte
+	data, err := bs.get(headKey)
+	if err != nil {
+		return hash, err
+	}
+	copy(hash[:], data)
+	return hash, nil
+}
+
+// Read the hash of the canonical block at height
+func (bs *BlockStore) ReadCanonicalHash(height uint64) ([32]byte, error) {
+	var hash [32]byte
+	data, err := bs.get(storeKey(heightPrefix, encodeUint64(height)))
+	if err != nil {
+		return hash, err
+	}
+	copy(hash[:], data)
+	return hash, nil
+}
+
+// Locate a canonical transaction by its ID
+func (bs *BlockStore) ReadTxLookup(txID [32]byte) (*TxLookup, error) {
+	data, err := bs.get(storeKey(txLookupPrefix, txID[:]))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 36 {
+		return nil, errors.New("corrupt transaction lookup entry")
+	}
+	lookup := &TxLookup{Index: binary.BigEndian.Uint32(data[32:])}
+	copy(lookup.BlockHash[:], data[:32])
+	return lookup, nil
+}
+
+// Read the IDs of the canonical transactions an address sent or received, oldest first, ErrTooManyResults past max
+func (bs *BlockStore) ReadAddressHistory(address [AddressLength]byte, max int) ([][32]byte, error) {
+	iter := bs.db.NewIterator(util.BytesPrefix(storeKey(addressPrefix, address[:])), nil)
+	defer iter.Release()
+	history := [][32]byte{}
+	for iter.Next() {
+		if len(history) == max {
+			return nil, ErrTooManyResults
+		}
+		var txID [32]byte
+		copy(txID[:], iter.Value())
+		history = append(history, txID)
+	}
+	return history, iter.Error()
+}
+
+// Read the receipts of the block with the given hash, in transaction order
+func (bs *BlockStore) ReadReceipts(blockHash [32]byte) ([]*Receipt, error) {
+	data, err := bs.get(storeKey(receiptsPrefix, blockHash[:]))
+	if err != nil {
+		return nil, err
+	}
+	list := &types.ReceiptList{}
+	if err := proto.Unmarshal(data, list); err != nil {
+		return nil, err
+	}
+	receipts := make([]*Receipt, 0, len(list.Receipts))
+	for _, msg := range list.Receipts {
+		receipts = append(receipts, NewReceiptFromPbMsg(msg))
+	}
+	return receipts, nil
+}
+
+// Helper methods
+// nodeStore keeps state tree nodes under nodePrefix
+type nodeStore struct {
+	bs *BlockStore
+}
+
+func (ns nodeStore) Get(hash [32]byte) ([]byte, error) {
+	return ns.bs.get(storeKey(nodePrefix, hash[:]))
+}
+
+func (ns nodeStore) Put(hash [32]byte, node []byte) error {
+	return ns.bs.db.Put(storeKey(nodePrefix, hash[:]), node, nil)
+}
+
+// Balances stored flat under prefix + address
+func (bs *BlockStore) readBalances(prefix []byte) (map[[AddressLength]byte]uint64, error) {
+	iter := bs.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+	balances := map[[AddressLength]byte]uint64{}
+	for iter.Next() {
+		var address [AddressLength]byte
+		copy(address[:], iter.Key()[len(prefix):])
+		balances[address] = binary.BigEndian.Uint64(iter.Value())
+	}
+	return balances, iter.Error()
+}
+
+func (bs *BlockStore) get(key []byte) ([]byte, error) {
+	data, err := bs.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func writeBlockData(batch *leveldb.Batch, b *Block, receipts []*Receipt) error {
+	data, err := proto.Marshal(b.ConvertToBlockPbMsg())
+	if err != nil {
+		return err
+	}
+	batch.Put(storeKey(blockPrefix, b.ID[:]), data)
+	data, err = encodeReceipts(receipts)
+	if err != nil {
+		return err
+	}
+	batch.Put(storeKey(receiptsPrefix, b.ID[:]), data)
+	return nil
+}
+
+func writeIndexes(batch *leveldb.Batch, b *Block) {
+	batch.Put(storeKey(heightPrefix, encodeUint64(b.height)), b.ID[:])
+	for i, t := range b.transactions {
+		lookup := make([]byte, 0, 36)
+		lookup = append(lookup, b.ID[:]...)
+		lookup = binary.BigEndian.AppendUint32(lookup, uint32(i))
+		batch.Put(storeKey(txLookupPrefix, t.ID[:]), lookup)
+		batch.Put(addressKey(t.senderAddress, b.height, uint32(i)), t.ID[:])
+		batch.Put(addressKey(t.receiverAddress, b.height, uint32(i)), t.ID[:])
+	}
+}
+
+func deleteIndexes(batch *leveldb.Batch, b *Block) {
+	batch.Delete(storeKey(heightPrefix, encodeUint64(b.height)))
+	for i, t := range b.transactions {
+		batch.Delete(storeKey(txLookupPrefix, t.ID[:]))
+		batch.Delete(addressKey(t.senderAddress, b.height, uint32(i)))
+		batch.Delete(addressKey(t.receiverAddress, b.height, uint32(i)))
+	}
+}
+
+func encodeReceipts(receipts []*Receipt) ([]byte, error) {
+	list := &types.ReceiptList{}
+	for _, r := range receipts {
+		list.Receipts = append(list.Receipts, r.ConvertToReceiptPbMsg())
+	}
+	return proto.Marshal(list)
+}
+
+// Heights are big endian so iteration order matches chain order
+func encodeUint64(n uint64) []byte {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, 8), n)
+}
+
+func addressKey(address [AddressLength]byte, height uint64, index uint32) []byte {
+	key := storeKey(addressPrefix, address[:])
+	key = binary.BigEndian.AppendUint64(key, height)
+	return binary.BigEndian.AppendUint32(key, index)
+}
+
+func storeKey(prefix []byte, id []byte) []byte {
+	key := make([]byte, 0, len(prefix)+len(id))
+	key = append(key, prefix...)
+	return append(key, id...)
+}
@@ -36,15 +36,49 @@ func NewTransaction(i int, v, g uint32, s, r [AddressLength]byte) *Transaction {
 	return trans
 }
 
+// Convert from the pb message back into a Transaction, the ID is recomputed rather than trusted
+func NewTransactionFromPbMsg(msg *types.TransactionMsg) *Transaction {
+	trans := &Transaction{
+		timestamp: msg.GetTimestamp(),
+		value:     msg.GetValue(),
+		gas:       msg.GetGas(),
+	}
+	copy(trans.senderAddress[:], msg.GetSenderAddress())
+	copy(trans.receiverAddress[:], msg.GetReceiverAddress())
+	trans.ID = trans.hashTransaction()
+	return trans
+}
+
+// Getters
+func (t *Transaction) Sender() [AddressLength]byte {
+	return t.senderAddress
+}
+
+func (t *Transaction) Receiver() [AddressLength]byte {
+	return t.receiverAddress
+}
+
+func (t *Transaction) Value() uint32 {
+	return t.value
+}
+
+func (t *Transaction) Gas() uint32 {
+	return t.gas
+}
+
 // Helper methods
+// The ID is left out of the hash as it is the hash
 func (t *Transaction) hashTransaction() [32]byte {
-	data, _ := proto.Marshal(t.convertToTransactionPbMsg())
+	msg := t.ConvertToTransactionPbMsg()
+	msg.ID = nil
+	data, _ := proto.Marshal(msg)
 	return sha3.Sum256(data)
 }
 
 // Convert to protoreflect.Protomessage type for pb marshalling
-func (t *Transaction) convertToTransactionPbMsg() *types.TransactionMsg {
+func (t *Transaction) ConvertToTransactionPbMsg() *types.TransactionMsg {
 	return &types.TransactionMsg{
+		ID:              t.ID[:],
 		Timestamp:       t.timestamp,
 		SenderAddress:   t.senderAddress[:],
 		ReceiverAddress: t.receiverAddress[:],
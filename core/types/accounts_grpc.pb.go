@@ -19,7 +19,10 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	AccountService_CreateAccount_FullMethodName = "/goChain.AccountService/CreateAccount"
+	AccountService_CreateAccount_FullMethodName     = "/goChain.AccountService/CreateAccount"
+	AccountService_GetAddressHistory_FullMethodName = "/goChain.AccountService/GetAddressHistory"
+	AccountService_FindTransactions_FullMethodName  = "/goChain.AccountService/FindTransactions"
+	AccountService_GetBalance_FullMethodName        = "/goChain.AccountService/GetBalance"
 )
 
 // AccountServiceClient is the client API for AccountService service.
@@ -27,6 +30,13 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AccountServiceClient interface {
 	CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*CreateAccountReponse, error)
+	// List the IDs of every canonical transaction sent or received by an address, oldest first
+	GetAddressHistory(ctx context.Context, in *GetAddressHistoryRequest, opts ...grpc.CallOption) (*AddressHistoryResponse, error)
+	// List the IDs of the canonical transactions sent or received by an address from height "from" up to and including
+	// height "to", oldest first
+	FindTransactions(ctx context.Context, in *FindTransactionsRequest, opts ...grpc.CallOption) (*AddressHistoryResponse, error)
+	// Balance of an address as of the canonical head
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
 }
 
 type accountServiceClient struct {
@@ -46,11 +56,45 @@ func (c *accountServiceClient) CreateAccount(ctx context.Context, in *CreateAcco
 	return out, nil
 }
 
+func (c *accountServiceClient) GetAddressHistory(ctx context.Context, in *GetAddressHistoryRequest, opts ...grpc.CallOption) (*AddressHistoryResponse, error) {
+	out := new(AddressHistoryResponse)
+	err := c.cc.Invoke(ctx, AccountService_GetAddressHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) FindTransactions(ctx context.Context, in *FindTransactionsRequest, opts ...grpc.CallOption) (*AddressHistoryResponse, error) {
+	out := new(AddressHistoryResponse)
+	err := c.cc.Invoke(ctx, AccountService_FindTransactions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error) {
+	out := new(BalanceResponse)
+	err := c.cc.Invoke(ctx, AccountService_GetBalance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AccountServiceServer is the server API for AccountService service.
 // All implementations must embed UnimplementedAccountServiceServer
 // for forward compatibility
 type AccountServiceServer interface {
 	CreateAccount(context.Context, *CreateAccountRequest) (*CreateAccountReponse, error)
+	// List the IDs of every canonical transaction sent or received by an address, oldest first
+	GetAddressHistory(context.Context, *GetAddressHistoryRequest) (*AddressHistoryResponse, error)
+	// List the IDs of the canonical transactions sent or received by an address from height "from" up to and including
+	// height "to", oldest first
+	FindTransactions(context.Context, *FindTransactionsRequest) (*AddressHistoryResponse, error)
+	// Balance of an address as of the canonical head
+	GetBalance(context.Context, *GetBalanceRequest) (*BalanceResponse, error)
 	mustEmbedUnimplementedAccountServiceServer()
 }
 
@@ -61,6 +105,15 @@ type UnimplementedAccountServiceServer struct {
 func (UnimplementedAccountServiceServer) CreateAccount(context.Context, *CreateAccountRequest) (*CreateAccountReponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateAccount not implemented")
 }
+func (UnimplementedAccountServiceServer) GetAddressHistory(context.Context, *GetAddressHistoryRequest) (*AddressHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAddressHistory not implemented")
+}
+func (UnimplementedAccountServiceServer) FindTransactions(context.Context, *FindTransactionsRequest) (*AddressHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindTransactions not implemented")
+}
+func (UnimplementedAccountServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*BalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
 func (UnimplementedAccountServiceServer) mustEmbedUnimplementedAccountServiceServer() {}
 
 // UnsafeAccountServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -92,6 +145,60 @@ func _AccountService_CreateAccount_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AccountService_GetAddressHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAddressHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).GetAddressHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_GetAddressHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).GetAddressHistory(ctx, req.(*GetAddressHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_FindTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).FindTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_FindTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).FindTransactions(ctx, req.(*FindTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AccountService_ServiceDesc is the grpc.ServiceDesc for AccountService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -103,6 +210,18 @@ var AccountService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateAccount",
 			Handler:    _AccountService_CreateAccount_Handler,
 		},
+		{
+			MethodName: "GetAddressHistory",
+			Handler:    _AccountService_GetAddressHistory_Handler,
+		},
+		{
+			MethodName: "FindTransactions",
+			Handler:    _AccountService_FindTransactions_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _AccountService_GetBalance_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "accounts.proto",
@@ -21,6 +21,9 @@ const _ = grpc.SupportPackageIsVersion7
 const (
 	TransactionService_CreateTransaction_FullMethodName = "/goChain.TransactionService/CreateTransaction"
 	TransactionService_SendTransactions_FullMethodName  = "/goChain.TransactionService/SendTransactions"
+	TransactionService_GetReceipt_FullMethodName        = "/goChain.TransactionService/GetReceipt"
+	TransactionService_GetTransaction_FullMethodName    = "/goChain.TransactionService/GetTransaction"
+	TransactionService_GetMemPoolStatus_FullMethodName  = "/goChain.TransactionService/GetMemPoolStatus"
 )
 
 // TransactionServiceClient is the client API for TransactionService service.
@@ -31,6 +34,12 @@ type TransactionServiceClient interface {
 	CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
 	// Send an array of transactions to another node
 	SendTransactions(ctx context.Context, in *TransactionBatch, opts ...grpc.CallOption) (*TransactionResponse, error)
+	// Fetch the receipt of an included transaction
+	GetReceipt(ctx context.Context, in *GetReceiptRequest, opts ...grpc.CallOption) (*ReceiptResponse, error)
+	// Fetch an included transaction along with its position in the chain
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
+	// Report how full the mempool is
+	GetMemPoolStatus(ctx context.Context, in *MemPoolStatusRequest, opts ...grpc.CallOption) (*MemPoolStatusResponse, error)
 }
 
 type transactionServiceClient struct {
@@ -59,6 +68,33 @@ func (c *transactionServiceClient) SendTransactions(ctx context.Context, in *Tra
 	return out, nil
 }
 
+func (c *transactionServiceClient) GetReceipt(ctx context.Context, in *GetReceiptRequest, opts ...grpc.CallOption) (*ReceiptResponse, error) {
+	out := new(ReceiptResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetReceipt_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error) {
+	out := new(GetTransactionResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetMemPoolStatus(ctx context.Context, in *MemPoolStatusRequest, opts ...grpc.CallOption) (*MemPoolStatusResponse, error) {
+	out := new(MemPoolStatusResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetMemPoolStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TransactionServiceServer is the server API for TransactionService service.
 // All implementations must embed UnimplementedTransactionServiceServer
 // for forward compatibility
@@ -67,6 +103,12 @@ type TransactionServiceServer interface {
 	CreateTransaction(context.Context, *CreateTransactionRequest) (*TransactionResponse, error)
 	// Send an array of transactions to another node
 	SendTransactions(context.Context, *TransactionBatch) (*TransactionResponse, error)
+	// Fetch the receipt of an included transaction
+	GetReceipt(context.Context, *GetReceiptRequest) (*ReceiptResponse, error)
+	// Fetch an included transaction along with its position in the chain
+	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
+	// Report how full the mempool is
+	GetMemPoolStatus(context.Context, *MemPoolStatusRequest) (*MemPoolStatusResponse, error)
 	mustEmbedUnimplementedTransactionServiceServer()
 }
 
@@ -80,6 +122,15 @@ func (UnimplementedTransactionServiceServer) CreateTransaction(context.Context,
 func (UnimplementedTransactionServiceServer) SendTransactions(context.Context, *TransactionBatch) (*TransactionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendTransactions not implemented")
 }
+func (UnimplementedTransactionServiceServer) GetReceipt(context.Context, *GetReceiptRequest) (*ReceiptResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReceipt not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetMemPoolStatus(context.Context, *MemPoolStatusRequest) (*MemPoolStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMemPoolStatus not implemented")
+}
 func (UnimplementedTransactionServiceServer) mustEmbedUnimplementedTransactionServiceServer() {}
 
 // UnsafeTransactionServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -129,6 +180,60 @@ func _TransactionService_SendTransactions_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TransactionService_GetReceipt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReceiptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetReceipt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetReceipt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetReceipt(ctx, req.(*GetReceiptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetMemPoolStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MemPoolStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetMemPoolStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetMemPoolStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetMemPoolStatus(ctx, req.(*MemPoolStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TransactionService_ServiceDesc is the grpc.ServiceDesc for TransactionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -144,6 +249,18 @@ var TransactionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendTransactions",
 			Handler:    _TransactionService_SendTransactions_Handler,
 		},
+		{
+			MethodName: "GetReceipt",
+			Handler:    _TransactionService_GetReceipt_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _TransactionService_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetMemPoolStatus",
+			Handler:    _TransactionService_GetMemPoolStatus_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "transaction.proto",
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/liangalv/goChain/core/types"
+)
+
+const (
+	//How far ahead of the local clock a block's timestamp may be
+	maxFutureBlockTime = 15 * time.Second
+)
+
+var (
+	ErrKnownBlock       = errors.New("block is already known")
+	ErrUnknownParent    = errors.New("parent block is unknown")
+	ErrKnownTransaction = errors.New("transaction is already in the chain")
+	ErrStaleFork        = errors.New("side chain leaves the canonical chain too far below the head")
+)
+
+// A BlockError means the block itself breaks a rule, whoever sent it sent something no honest node would
+type BlockError struct {
+	Block  [32]byte
+	Reason string
+}
+
+func (e *BlockError) Error() string {
+	return fmt.Sprintf("invalid block %x: %s", e.Block[:4], e.Reason)
+}
+
+// A TxError means a transaction could never be included in a block
+type TxError struct {
+	Tx     [32]byte
+	Reason string
+}
+
+func (e *TxError) Error() string {
+	return fmt.Sprintf("invalid transaction %x: %s", e.Tx[:4], e.Reason)
+}
+
+// VerifyHeader checks the header can follow parent, it says nothing about the transactions
+func (b *Block) VerifyHeader(parent *Block) error {
+	//Only genesis keeps the original format, any other version would let a block pick the weaker receipts tree and
+	//skip the history and bloom commitments
+	if b.version != BlockVersion {
+		return b.invalid(fmt.Sprintf("version %d, expected %d", b.version, BlockVersion))
+	}
+	if b.parentHash != parent.ID {
+		return b.invalid(fmt.Sprintf("parent hash %x does not match parent %x", b.parentHash[:4], parent.ID[:4]))
+	}
+	if b.height != parent.height+1 {
+		return b.invalid(fmt.Sprintf("height %d does not follow parent height %d", b.height, parent.height))
+	}
+	if b.difficulty != DIFFICULTY {
+		return b.invalid(fmt.Sprintf("difficulty %d, expected %d", b.difficulty, DIFFICULTY))
+	}
+	if b.gasLimit != GASLIMIT {
+		return b.invalid(fmt.Sprintf("gas limit %d, expected %d", b.gasLimit, GASLIMIT))
+	}
+	if b.timestamp < parent.timestamp {
+		return b.invalid("timestamp is older than the parent's")
+	}
+	if time.Unix(0, b.timestamp).After(time.Now().Add(maxFutureBlockTime)) {
+		return b.invalid("timestamp is too far in the future")
+	}
+	return nil
+}
+
+// VerifyHistory checks the block commits to root, the root of the MountainRange of every block before it
+func (b *Block) VerifyHistory(root [32]byte) error {
+	if b.version >= 2 && b.historyRoot != root {
+		return b.invalid(fmt.Sprintf("history root %x does not match the chain's %x", b.historyRoot[:4], root[:4]))
+	}
+	return nil
+}
+
+// VerifyBody executes the block on state, which must be the state as of its parent, and checks the block commits to
+// the receipts and state that execution produced. state is modified either way so callers should pass a copy
+func (b *Block) VerifyBody(state *State) ([]*Receipt, error) {
+	receipts := b.Execute(state)
+	if n := len(receipts); n > 0 && receipts[n-1].CumulativeGasUsed > uint64(b.gasLimit) {
+		return nil, b.invalid("transactions exceed the block gas limit")
+	}
+	if root := receiptsRoot(b.TreeMode(), receipts); root != b.receiptsRoot {
+		return nil, b.invalid(fmt.Sprintf("receipts root %x does not match executed receipts %x", b.receiptsRoot[:4], root[:4]))
+	}
+	if b.HasBloom() && CreateBloom(b.transactions, receipts) != b.bloom {
+		return nil, b.invalid("bloom does not match the block's transactions and logs")
+	}
+	if root := state.Root(); root != b.trieRootHash {
+		return nil, b.invalid(fmt.Sprintf("state root %x does not match executed state %x", b.trieRootHash[:4], root[:4]))
+	}
+	return receipts, nil
+}
+
+// ValidateTransactionMsg rejects a relayed transaction that could never execute, before it is decoded
+func ValidateTransactionMsg(msg *types.TransactionMsg) error {
+	invalid := func(reason string) error {
+		return &TxError{Tx: NewTransactionFromPbMsg(msg).ID, Reason: reason}
+	}
+	if len(msg.GetSenderAddress()) != AddressLength {
+		return invalid("malformed sender address")
+	}
+	if len(msg.GetReceiverAddress()) != AddressLength {
+		return invalid("malformed receiver address")
+	}
+	if msg.GetGas() < TxGas {
+		return invalid(fmt.Sprintf("gas %d is below the %d every transaction costs", msg.GetGas(), TxGas))
+	}
+	return nil
+}
+
+// Helper
+func (b *Block) invalid(reason string) *BlockError {
+	return &BlockError{Block: b.ID, Reason: reason}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+)
+
+const (
+	//How long a light chain relays a transaction for, it never sees the bodies that would tell it the transaction was
+	//mined unless a proof of it is fetched
+	lightPoolTTL = 10 * time.Minute
+)
+
+var (
+	errHeadersOnly = errors.New("a light chain only imports headers")
+	errNoHistory   = errors.New("address history is not available to a light client")
+	errNoLogs      = errors.New("logs are not available to a light client")
+	errNoProver    = errors.New("no peers to fetch proofs from")
+)
+
+// A Prover fetches proofs from full nodes, the light Syncer
+type Prover interface {
+	RequestTxProof(txID [32]byte) (*core.TxProof, error)
+	RequestAccountProof(root [32]byte, address [core.AddressLength]byte) (*core.AccountProof, error)
+}
+
+/*
+LightChain follows the canonical chain by its headers alone, for machines that can't keep every block and state.
+The headers are kept by a core.HeaderChain, checked against the header rules and the history root as they come in, and
+the branch with the most total difficulty is canonical, as on a full chain. Queries are answered the same as a full
+chain answers them, but from proofs full nodes serve against the receipts and state roots of the canonical headers.
+Transactions submitted to a light chain are only relayed, it never executes or seals them. They stay in the pool until a
+proof shows they were included or, failing that, for lightPoolTTL.
+*/
+type LightChain struct {
+	*core.HeaderChain
+	mux     sync.RWMutex
+	chainID uint64
+	memPool *core.MemPool
+	prover  Prover
+}
+
+func NewLightChain(store *core.BlockStore, events *core.EventBus) *LightChain {
+	//The genesis block commits to the genesis state, which is only executed to derive the same hash as full nodes
+	genesis := core.NewGenesisBlock()
+	genesis.Finalize(core.NewState(GenesisAlloc))
+	headers, err := core.NewHeaderChain(store, events, genesis)
+	if err != nil {
+		log.Fatalf("Failed to load chain from block store: %v", err)
+	}
+	return &LightChain{
+		HeaderChain: headers,
+		chainID:     ChainID,
+		memPool:     core.NewMemPool(nil, events),
+	}
+}
+
+// SetProver sets where queries fetch their proofs from, the Syncer is built around the chain so is set after it
+func (lc *LightChain) SetProver(p Prover) {
+	lc.mux.Lock()
+	defer lc.mux.Unlock()
+	lc.prover = p
+}
+
+// InsertHeaders imports headers into the core.HeaderChain, pooled transactions that have had long enough to be mined
+// are dropped as the chain moves on
+func (lc *LightChain) InsertHeaders(headers []*core.Block) error {
+	err := lc.HeaderChain.InsertHeaders(headers)
+	lc.memPool.Expire(lightPoolTTL)
+	return err
+}
+
+// InsertBlock implements p2p.BlockChain, a light chain never imports a whole block
+func (lc *LightChain) InsertBlock(b *core.Block) error {
+	return errHeadersOnly
+}
+
+// Query methods
+// GetReceipt fetches the receipt of a canonical transaction with a proof it is in its block
+func (lc *LightChain) GetReceipt(txID [32]byte) (*core.Receipt, error) {
+	tp, err := lc.proveTx(txID)
+	if err != nil {
+		return nil, err
+	}
+	return tp.Receipt, nil
+}
+
+// GetTransaction fetches a canonical transaction with a proof it is in its block
+func (lc *LightChain) GetTransaction(txID [32]byte) (*core.Transaction, *core.TxLookup, error) {
+	tp, err := lc.proveTx(txID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tp.Transaction, &core.TxLookup{BlockHash: tp.BlockHash, Index: tp.Receipt.Index}, nil
+}
+
+// GetBalance fetches the balance as of the head with a proof against its state root
+func (lc *LightChain) GetBalance(address [core.AddressLength]byte) (uint64, error) {
+	lc.mux.RLock()
+	prover := lc.prover
+	lc.mux.RUnlock()
+	head := lc.LastBlock()
+	if prover == nil {
+		return 0, errNoProver
+	}
+	ap, err := prover.RequestAccountProof(head.StateRoot(), address)
+	if err != nil {
+		return 0, err
+	}
+	return ap.Account.Balance, nil
+}
+
+// GetAddressHistory is served from an index of every block's transactions, which a light chain doesn't have
+func (lc *LightChain) GetAddressHistory(address [core.AddressLength]byte) ([][32]byte, error) {
+	return nil, errNoHistory
+}
+
+// GetLogs needs every block's receipts, which a light chain doesn't have
+func (lc *LightChain) GetLogs(filter *core.LogFilter) ([]*core.FilteredLog, error) {
+	return nil, errNoLogs
+}
+
+// FindTransactions needs every block's body, which a light chain doesn't have
+func (lc *LightChain) FindTransactions(address [core.AddressLength]byte, from, to uint64) ([][32]byte, error) {
+	return nil, errNoHistory
+}
+
+// Validate and add a transaction to the MemPool, it is relayed to peers from there
+func (lc *LightChain) AddTransaction(t *core.Transaction) error {
+	return lc.memPool.AddTransactionToPool(t)
+}
+
+// A transaction still waiting in the mempool, nil if it isn't there
+func (lc *LightChain) PoolTransaction(id [32]byte) *core.Transaction {
+	t, _ := lc.memPool.GetTransaction(id)
+	return t
+}
+
+// Every transaction waiting in the mempool
+func (lc *LightChain) PoolTransactions() []*core.Transaction {
+	return lc.memPool.Pending()
+}
+
+// A light chain has no index of the canonical transactions, it only knows what is in its pool
+func (lc *LightChain) TransactionIncluded(id [32]byte) bool {
+	return false
+}
+
+// Number of transactions waiting in the MemPool
+func (lc *LightChain) PendingTransactions() int {
+	return lc.memPool.Size()
+}
+
+func (lc *LightChain) ChainID() uint64 {
+	return lc.chainID
+}
+
+// Helper Methods
+func (lc *LightChain) proveTx(txID [32]byte) (*core.TxProof, error) {
+	lc.mux.RLock()
+	prover := lc.prover
+	lc.mux.RUnlock()
+	if prover == nil {
+		return nil, errNoProver
+	}
+	tp, err := prover.RequestTxProof(txID)
+	if err != nil {
+		return nil, err
+	}
+	//The proof is checked against a canonical header, the transaction no longer needs relaying
+	lc.memPool.RemoveIncluded([]*core.Transaction{tp.Transaction})
+	return tp, nil
+}
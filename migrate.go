@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/p2p"
+)
+
+// Check a node's store keeps the states the node was started to keep, a fresh store is set up to keep them
+func checkStateMode(store *core.BlockStore, want core.StateConfig) error {
+	have, err := store.ReadStateConfig()
+	if err == core.ErrNotFound {
+		return store.WriteStateConfig(want)
+	}
+	if err != nil {
+		return err
+	}
+	have, want = withStateDefaults(have), withStateDefaults(want)
+	switch {
+	case have == want:
+		return nil
+	case have.Mode == core.HeadersOnly || want.Mode == core.HeadersOnly:
+		return fmt.Errorf("store keeps %s, not %s", have.Mode, want.Mode)
+	case have.Mode != want.Mode:
+		return fmt.Errorf("store keeps %s state, not %s. Run gochain migrate to convert it", have.Mode, want.Mode)
+	default:
+		return fmt.Errorf("store keeps %d recent states and a checkpoint every %d blocks, not %d and %d. Run gochain migrate to convert it",
+			have.History, have.Checkpoint, want.History, want.Checkpoint)
+	}
+}
+
+// A pruned store recorded before the state history and checkpoint interval were kept with its mode used the defaults
+func withStateDefaults(cfg core.StateConfig) core.StateConfig {
+	if cfg.Mode == core.PrunedState && cfg.History == 0 {
+		cfg.History, cfg.Checkpoint = defaultStateHistory, defaultStateCheckpoint
+	}
+	//Archive stores keep the in memory states of as many blocks
+	if cfg.Mode != core.PrunedState {
+		cfg.History, cfg.Checkpoint = defaultStateHistory, 0
+	}
+	return cfg
+}
+
+// Define the flags saying which states a full node keeps on fs, the returned func reads them once fs is parsed
+func stateFlags(fs *flag.FlagSet) func() core.StateConfig {
+	archive := fs.Bool("archive", false, "keep the state of every block, otherwise only recent states and periodic checkpoints are kept")
+	history := fs.Uint64("statehistory", defaultStateHistory, "number of recent states a pruned node keeps, it prunes the rest every as many blocks")
+	checkpoint := fs.Uint64("checkpoint", defaultStateCheckpoint, "a pruned node also keeps the state of every block at a multiple of this height")
+	return func() core.StateConfig {
+		if *archive {
+			return withStateDefaults(core.StateConfig{Mode: core.ArchiveState})
+		}
+		//Snap syncing peers download the state of a block this deep
+		if *history < p2p.SnapPivotDepth {
+			log.Fatalf("Invalid -statehistory %d, peers snap sync from %d blocks below the head", *history, p2p.SnapPivotDepth)
+		}
+		if *checkpoint == 0 {
+			log.Fatalf("Invalid -checkpoint 0")
+		}
+		return core.StateConfig{Mode: core.PrunedState, History: *history, Checkpoint: *checkpoint}
+	}
+}
+
+/*
+gochain migrate converts the store of a stopped full node between pruned and archive state, or to keeping a different
+number of recent states or checkpoints. Pruning deletes every state tree the pruned mode doesn't keep, converting to
+archive executes the chain again to rewrite the ones pruned. States before a snap synced block were never executed so
+they can't be written, nor can states already pruned be brought back by keeping more recent states
+*/
+func migrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dataDir := fs.String("datadir", "chaindata", "directory of the block store")
+	stateCfg := stateFlags(fs)
+	fs.Parse(args)
+	want := stateCfg()
+	store, err := core.OpenBlockStore(*dataDir)
+	if err != nil {
+		log.Fatalf("Failed to open block store: %v", err)
+	}
+	defer store.Close()
+	if _, err := store.ReadHeadHash(); err != nil {
+		log.Fatalf("No chain to migrate in %s: %v", *dataDir, err)
+	}
+	//A light chain's store has headers without bodies and no state to convert
+	have, err := store.ReadStateConfig()
+	if err == core.ErrNotFound {
+		log.Fatalf("%s records no state mode, it is a light node's store or a full node never started on it", *dataDir)
+	} else if err != nil {
+		log.Fatalf("Failed to read state mode: %v", err)
+	}
+	if have.Mode == core.HeadersOnly {
+		log.Fatalf("%s is a light node's store, it keeps no state to migrate", *dataDir)
+	}
+	if want.Mode == core.PrunedState {
+		//Recorded first, a node started on the store before pruning finishes prunes it as it goes
+		if err := store.WriteStateConfig(want); err != nil {
+			log.Fatalf("Failed to record state mode: %v", err)
+		}
+		bc, err := NewBlockChain(store, core.NewEventBus())
+		if err != nil {
+			log.Fatalf("Failed to open chain: %v", err)
+		}
+		bc.pruneState()
+		log.Printf("%s now keeps pruned state, the last %d states and every %d'th", *dataDir, want.History, want.Checkpoint)
+		return
+	}
+	bc, err := NewBlockChain(store, core.NewEventBus())
+	if err != nil {
+		log.Fatalf("Failed to open chain: %v", err)
+	}
+	written, err := bc.regenerateStates()
+	if err != nil {
+		log.Fatalf("Failed to regenerate states: %v", err)
+	}
+	if err := store.WriteStateConfig(want); err != nil {
+		log.Fatalf("Failed to record state mode: %v", err)
+	}
+	log.Printf("Wrote the states of %d blocks, %s now keeps archive state", written, *dataDir)
+}
+
+// Execute the canonical chain again from genesis, or the snap synced block, writing the state tree of every block.
+// Returns how many blocks' states were written
+func (bc *BlockChain) regenerateStates() (int, error) {
+	bc.mux.Lock()
+	defer bc.mux.Unlock()
+	state, chain, err := bc.replayBase(bc.chain)
+	if err != nil {
+		return 0, err
+	}
+	for _, b := range chain {
+		b.Execute(state)
+		if root := state.Root(); root != b.StateRoot() {
+			return 0, fmt.Errorf("executed state %x does not match state root of block %d", root[:4], b.Height())
+		}
+	}
+	return len(chain), nil
+}
@@ -1 +1,178 @@
 package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/jsonrpc"
+	"github.com/liangalv/goChain/core/services"
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/p2p"
+	"google.golang.org/grpc"
+)
+
+type Config struct {
+	DataDir      string
+	GRPCAddr     string
+	HTTPAddr     string
+	AdminAddr    string
+	P2PAddr      string
+	MaxPeers     int
+	MinPeers     int
+	Bootnodes    []string
+	BlockTime    time.Duration
+	NodeKeyPath  string
+	AllowedNodes [][32]byte
+	BanHosts     bool
+	SyncMode     string
+	State        core.StateConfig
+}
+
+func parseFlags() *Config {
+	cfg := &Config{}
+	flag.StringVar(&cfg.DataDir, "datadir", "chaindata", "directory of the block store")
+	flag.StringVar(&cfg.GRPCAddr, "grpcaddr", ":9000", "address of the gRPC API")
+	flag.StringVar(&cfg.HTTPAddr, "httpaddr", ":8545", "address of the JSON-RPC gateway")
+	flag.StringVar(&cfg.AdminAddr, "adminaddr", "127.0.0.1:9001", "address of the gRPC admin API, which only the node operator should reach. Empty turns it off")
+	flag.StringVar(&cfg.P2PAddr, "p2paddr", ":30303", "address to accept peer connections on")
+	flag.IntVar(&cfg.MaxPeers, "maxpeers", p2p.DefaultMaxPeers, "maximum number of peer connections")
+	flag.IntVar(&cfg.MinPeers, "minpeers", p2p.DefaultMinPeers, "number of peer connections to keep dialing for")
+	flag.DurationVar(&cfg.BlockTime, "blocktime", 0, "seal pending transactions into a block this often, 0 never produces blocks")
+	flag.StringVar(&cfg.SyncMode, "syncmode", "full", "how a fresh node catches up: full imports every block, snap downloads a recent state, light follows headers only")
+	stateCfg := stateFlags(flag.CommandLine)
+	flag.StringVar(&cfg.NodeKeyPath, "nodekey", "", "file holding the node key, defaults to nodekey in the datadir")
+	bootnodes := flag.String("bootnodes", "", "comma separated addresses to bootstrap peer discovery from")
+	flag.BoolVar(&cfg.BanHosts, "banhosts", false, "also refuse inbound connections from the host of a banned peer, which shuts out every node on that host or behind the same NAT")
+	allowNodes := flag.String("allownodes", "", "comma separated hex node IDs, only these nodes may connect when set")
+	flag.Parse()
+	cfg.State = stateCfg()
+	for _, addr := range strings.Split(*bootnodes, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			cfg.Bootnodes = append(cfg.Bootnodes, addr)
+		}
+	}
+	for _, s := range strings.Split(*allowNodes, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := p2p.ParseNodeID(s)
+		if err != nil {
+			log.Fatalf("Invalid -allownodes: %v", err)
+		}
+		cfg.AllowedNodes = append(cfg.AllowedNodes, id)
+	}
+	if cfg.SyncMode != "full" && cfg.SyncMode != "snap" && cfg.SyncMode != "light" {
+		log.Fatalf("Invalid -syncmode %q, expected full, snap or light", cfg.SyncMode)
+	}
+	if cfg.SyncMode == "light" && cfg.BlockTime > 0 {
+		log.Fatalf("A light node has no state to produce blocks on, -blocktime requires -syncmode full or snap")
+	}
+	if cfg.SyncMode == "light" && cfg.State.Mode == core.ArchiveState {
+		log.Fatalf("A light node keeps no state, -archive requires -syncmode full or snap")
+	}
+	if cfg.NodeKeyPath == "" {
+		cfg.NodeKeyPath = filepath.Join(cfg.DataDir, "nodekey")
+	}
+	return cfg
+}
+
+// Protocols a full node runs
+func fullProtocols(cfg *Config, bc *BlockChain, events *core.EventBus) []p2p.Protocol {
+	//Gossiped transactions go through the same TransactionService the API uses
+	protocols := []p2p.Protocol{
+		p2p.NewTxGossip(bc, services.NewTransactionService(bc), events),
+		p2p.NewBlockGossip(bc, bc, events),
+	}
+	if cfg.SyncMode == "snap" {
+		return append(protocols, p2p.NewSnapSyncer(bc))
+	}
+	return append(protocols, p2p.NewSyncer(bc))
+}
+
+// Protocols a light node runs, it relays transactions but never sees a whole block
+func lightProtocols(lc *LightChain, events *core.EventBus) []p2p.Protocol {
+	syncer := p2p.NewLightSyncer(lc)
+	//Queries are proven by the peers the Syncer is connected to
+	lc.SetProver(syncer)
+	return []p2p.Protocol{p2p.NewTxGossip(lc, services.NewTransactionService(lc), events), syncer}
+}
+
+// Start the p2p server and the protocols it runs, peers talk to it on a listener separate from the client facing API
+func startP2P(cfg *Config, chain p2p.Chain, protocols []p2p.Protocol) (*p2p.Server, error) {
+	key, err := p2p.LoadNodeKey(cfg.NodeKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	node := p2p.NewServer(p2p.Config{
+		ListenAddr:   cfg.P2PAddr,
+		MaxPeers:     cfg.MaxPeers,
+		MinPeers:     cfg.MinPeers,
+		Bootnodes:    cfg.Bootnodes,
+		PeerBookPath: filepath.Join(cfg.DataDir, "peers.json"),
+		NodeKey:      key,
+		AllowedNodes: cfg.AllowedNodes,
+		BanHosts:     cfg.BanHosts,
+	}, chain)
+	for _, protocol := range protocols {
+		node.RegisterProtocol(protocol)
+	}
+	if err := node.Start(); err != nil {
+		return nil, err
+	}
+	id := node.NodeID()
+	log.Printf("p2p server listening on %s, node ID %x", node.Addr(), id)
+	return node, nil
+}
+
+// Serve the gRPC services and the JSON-RPC gateway in front of them, blocks until the gRPC server stops
+func serveAPI(cfg *Config, chain services.Chain, events *core.EventBus, node *p2p.Server) error {
+	lis, err := net.Listen("tcp", cfg.GRPCAddr)
+	if err != nil {
+		return err
+	}
+	if cfg.AdminAddr != "" {
+		if err := serveAdmin(cfg.AdminAddr, node); err != nil {
+			return err
+		}
+	}
+	//Instantiate services
+	ts := services.NewTransactionService(chain)
+	bs := services.NewBlockService(chain)
+	as := services.NewAccountService(chain)
+	ss := services.NewSubscriptionService(events)
+
+	go func() {
+		if err := http.ListenAndServe(cfg.HTTPAddr, jsonrpc.NewServer(ts, as, bs)); err != nil {
+			log.Fatalf("Failed to serve JSON-RPC gateway over %s: %v", cfg.HTTPAddr, err)
+		}
+	}()
+
+	grpcServer := grpc.NewServer()
+	types.RegisterTransactionServiceServer(grpcServer, ts)
+	types.RegisterBlockServiceServer(grpcServer, bs)
+	types.RegisterAccountServiceServer(grpcServer, as)
+	types.RegisterSubscriptionServiceServer(grpcServer, ss)
+	return grpcServer.Serve(lis)
+}
+
+// Serve the admin service on a listener of its own, apart from the public API, so it can be kept to the local machine
+func serveAdmin(addr string, node *p2p.Server) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	adminServer := grpc.NewServer()
+	types.RegisterAdminServiceServer(adminServer, services.NewAdminService(node))
+	go func() {
+		if err := adminServer.Serve(lis); err != nil {
+			log.Fatalf("Failed to serve admin API over %s: %v", addr, err)
+		}
+	}()
+	return nil
+}
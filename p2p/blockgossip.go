@@ -0,0 +1,338 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+)
+
+const (
+	//Block hashes remembered per peer
+	maxKnownBlocks = 1024
+	//Cap on hashes per request and blocks per batch
+	maxBlocksPerMsg = 64
+	//Blocks held while their ancestors are fetched
+	maxOrphans = 256
+	//A block requested from one peer isn't requested again until this has passed
+	blockRequestTimeout = 5 * time.Second
+	blockEventBuffer    = 256
+)
+
+// BlockChain is the view of the chain block propagation validates against and serves blocks from
+type BlockChain interface {
+	GetBlockByHash(hash [32]byte) (*core.Block, error)
+	TotalDifficulty() uint64
+	//Validates and imports a block, core.ErrKnownBlock and core.ErrUnknownParent aren't the sender's fault
+	InsertBlock(b *core.Block) error
+}
+
+/*
+BlockGossip propagates new heads across the network.
+Whenever the chain gets a new head, whether sealed locally or imported from a peer, the block is pushed in full to
+sqrt(n) of the peers that don't have it and announced by hash to the rest, who request it if they need it. Blocks are
+only relayed once they have been validated and imported so an invalid block never makes it past the first node.
+A block whose parent is unknown is held as an orphan while its ancestors are requested from the peer that sent it.
+Pushed blocks are sent compact, as a header and short transaction IDs the receiver rebuilds the block from using its
+own mempool, with only the transactions it is missing sent in full. Announced and requested blocks are sent in full.
+*/
+type BlockGossip struct {
+	chain BlockChain
+	pool  TxPool
+
+	mux       sync.Mutex
+	peers     map[*Peer]*knownSet
+	requested map[[32]byte]time.Time
+	orphans   map[[32]byte][]*core.Block //keyed by parent hash
+	orphanIDs map[[32]byte]bool
+	partials  map[[32]byte]*partialBlock
+
+	events *core.EventBus
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+func NewBlockGossip(chain BlockChain, pool TxPool, events *core.EventBus) *BlockGossip {
+	g := &BlockGossip{
+		chain:     chain,
+		pool:      pool,
+		peers:     map[*Peer]*knownSet{},
+		requested: map[[32]byte]time.Time{},
+		orphans:   map[[32]byte][]*core.Block{},
+		orphanIDs: map[[32]byte]bool{},
+		partials:  map[[32]byte]*partialBlock{},
+		events:    events,
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go g.relayLoop()
+	return g
+}
+
+// Stop relaying new heads, peers are still served until the server stops
+func (g *BlockGossip) Stop() {
+	//The Server stops its protocols, which may already have been stopped by hand
+	select {
+	case <-g.quit:
+		return
+	default:
+	}
+	close(g.quit)
+	<-g.done
+}
+
+// Capabilities implements Protocol
+func (g *BlockGossip) Capabilities() []Capability {
+	return []Capability{BlocksCap, CompactCap}
+}
+
+// AddPeer implements Protocol, a peer ahead of us has its head requested straight away
+func (g *BlockGossip) AddPeer(p *Peer) {
+	known := newKnownSet(maxKnownBlocks)
+	_, head := p.Head()
+	known.Add(head)
+	g.mux.Lock()
+	g.peers[p] = known
+	g.mux.Unlock()
+	g.requestUnknown(p, head)
+}
+
+// RemovePeer implements Protocol
+func (g *BlockGossip) RemovePeer(p *Peer) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	delete(g.peers, p)
+}
+
+// HandleMsg implements Protocol
+func (g *BlockGossip) HandleMsg(p *Peer, msg *types.PeerMsg) error {
+	known := g.known(p)
+	if known == nil {
+		return nil
+	}
+	switch payload := msg.GetPayload().(type) {
+	case *types.PeerMsg_NewBlock:
+		return g.handleBlocks(p, known, []*types.BlockMsg{payload.NewBlock})
+	case *types.PeerMsg_Blocks:
+		if n := len(payload.Blocks.GetBlocks()); n > maxBlocksPerMsg {
+			return fmt.Errorf("block batch of %d exceeds %d", n, maxBlocksPerMsg)
+		}
+		return g.handleBlocks(p, known, payload.Blocks.GetBlocks())
+	case *types.PeerMsg_BlockAnnounce:
+		hash, ok := toHash(payload.BlockAnnounce.GetHash())
+		if !ok {
+			return errors.New("malformed block hash")
+		}
+		known.Add(hash)
+		updateHead(p, payload.BlockAnnounce.GetHeight(), hash)
+		if td := payload.BlockAnnounce.GetTotalDifficulty(); td > p.TotalDifficulty() {
+			p.SetTotalDifficulty(td)
+		}
+		g.requestUnknown(p, hash)
+	case *types.PeerMsg_BlockRequest:
+		return g.handleRequest(p, payload.BlockRequest.GetHashes())
+	case *types.PeerMsg_CompactBlock:
+		return g.handleCompact(p, known, payload.CompactBlock)
+	case *types.PeerMsg_BlockTxsRequest:
+		return g.serveBlockTxs(p, payload.BlockTxsRequest)
+	case *types.PeerMsg_BlockTxs:
+		return g.handleBlockTxs(p, payload.BlockTxs)
+	}
+	return nil
+}
+
+// Helper methods
+func (g *BlockGossip) known(p *Peer) *knownSet {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	return g.peers[p]
+}
+
+func (g *BlockGossip) handleBlocks(p *Peer, known *knownSet, msgs []*types.BlockMsg) error {
+	for _, msg := range msgs {
+		//The ID is recomputed from the header, the sender's claimed ID is never trusted
+		b := core.NewBlockFromPbMsg(msg)
+		known.Add(b.ID)
+		updateHead(p, b.Height(), b.ID)
+		g.mux.Lock()
+		delete(g.requested, b.ID)
+		g.mux.Unlock()
+		if err := g.importBlock(p, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *BlockGossip) handleRequest(p *Peer, raw [][]byte) error {
+	if len(raw) > maxBlocksPerMsg {
+		return fmt.Errorf("%d block hashes exceeds %d per message", len(raw), maxBlocksPerMsg)
+	}
+	batch := &types.BlockBatch{}
+	for _, h := range raw {
+		hash, ok := toHash(h)
+		if !ok {
+			return errors.New("malformed block hash")
+		}
+		if b, err := g.chain.GetBlockByHash(hash); err == nil {
+			batch.Blocks = append(batch.Blocks, b.ConvertToBlockPbMsg())
+		}
+	}
+	if len(batch.Blocks) == 0 {
+		return nil
+	}
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_Blocks{Blocks: batch}})
+}
+
+// Import a block, fetching its ancestors from p first if we don't have them. Only invalid blocks are an error
+func (g *BlockGossip) importBlock(p *Peer, b *core.Block) error {
+	err := g.chain.InsertBlock(b)
+	switch {
+	case err == nil:
+		g.importOrphans(b.ID)
+		return nil
+	case errors.Is(err, core.ErrKnownBlock):
+		return nil
+	case errors.Is(err, core.ErrUnknownParent):
+		if g.addOrphan(b) {
+			g.requestUnknown(p, b.ParentHash())
+		}
+		return nil
+	default:
+		return err
+	}
+}
+
+// Import every orphan that was waiting on parent, and every orphan waiting on those in turn
+func (g *BlockGossip) importOrphans(parent [32]byte) {
+	queue := [][32]byte{parent}
+	for len(queue) > 0 {
+		g.mux.Lock()
+		children := g.orphans[queue[0]]
+		delete(g.orphans, queue[0])
+		for _, b := range children {
+			delete(g.orphanIDs, b.ID)
+		}
+		g.mux.Unlock()
+		queue = queue[1:]
+		for _, b := range children {
+			//Orphans were checked for nothing but their hash, anything invalid is simply dropped
+			if err := g.chain.InsertBlock(b); err == nil {
+				queue = append(queue, b.ID)
+			}
+		}
+	}
+}
+
+// Reports whether the block was held, orphans past the cap are dropped rather than fetched
+func (g *BlockGossip) addOrphan(b *core.Block) bool {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	if g.orphanIDs[b.ID] || len(g.orphanIDs) >= maxOrphans {
+		return false
+	}
+	g.orphanIDs[b.ID] = true
+	g.orphans[b.ParentHash()] = append(g.orphans[b.ParentHash()], b)
+	return true
+}
+
+// Request a block from p unless we have it, are holding it as an orphan or already asked someone for it
+func (g *BlockGossip) requestUnknown(p *Peer, hash [32]byte) {
+	if _, err := g.chain.GetBlockByHash(hash); err == nil {
+		return
+	}
+	now := time.Now()
+	g.mux.Lock()
+	if at, ok := g.requested[hash]; g.orphanIDs[hash] || (ok && now.Sub(at) < blockRequestTimeout) {
+		g.mux.Unlock()
+		return
+	}
+	g.requested[hash] = now
+	for h, at := range g.requested {
+		if now.Sub(at) >= blockRequestTimeout {
+			delete(g.requested, h)
+		}
+	}
+	g.mux.Unlock()
+	p.Send(&types.PeerMsg{Payload: &types.PeerMsg_BlockRequest{BlockRequest: &types.BlockRequest{Hashes: [][]byte{hash[:]}}}})
+}
+
+// Relay every new head, reorgs relay the head of the branch that won
+func (g *BlockGossip) relayLoop() {
+	defer close(g.done)
+	sub := g.events.Subscribe(blockEventBuffer)
+	defer func() { sub.Unsubscribe() }()
+	for {
+		select {
+		case <-g.quit:
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				sub = g.events.Subscribe(blockEventBuffer)
+				continue
+			}
+			switch e.Type {
+			case core.NewHeadEvent:
+				g.relay(e.Block)
+			case core.ReorgEvent:
+				g.relay(e.Added[len(e.Added)-1])
+			}
+		}
+	}
+}
+
+// Push the compact block to sqrt(n) of the peers that don't have it and announce it to the rest.
+// Peers that don't support compact blocks are pushed the full block instead
+func (g *BlockGossip) relay(b *core.Block) {
+	g.mux.Lock()
+	var targets []*Peer
+	for p, known := range g.peers {
+		if known.AddIfMissing(b.ID) {
+			targets = append(targets, p)
+		}
+	}
+	push := int(math.Ceil(math.Sqrt(float64(len(g.peers)))))
+	g.mux.Unlock()
+	if len(targets) == 0 {
+		return
+	}
+	rand.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+	compact := &types.PeerMsg{Payload: &types.PeerMsg_CompactBlock{CompactBlock: newCompactBlock(b)}}
+	full := &types.PeerMsg{Payload: &types.PeerMsg_NewBlock{NewBlock: b.ConvertToBlockPbMsg()}}
+	announce := &types.PeerMsg{Payload: &types.PeerMsg_BlockAnnounce{BlockAnnounce: &types.BlockAnnounce{
+		Hash:            b.ID[:],
+		Height:          b.Height(),
+		TotalDifficulty: g.chain.TotalDifficulty(),
+	}}}
+	for i, p := range targets {
+		switch {
+		case i >= push:
+			p.Send(announce)
+		case p.Supports(CompactCap.Name):
+			p.Send(compact)
+		default:
+			p.Send(full)
+		}
+	}
+}
+
+// Track the highest block a peer has shown us it has
+func updateHead(p *Peer, height uint64, hash [32]byte) {
+	if current, _ := p.Head(); height > current {
+		p.SetHead(height, hash)
+	}
+}
+
+func toHash(b []byte) ([32]byte, bool) {
+	var h [32]byte
+	if len(b) != len(h) {
+		return h, false
+	}
+	copy(h[:], b)
+	return h, true
+}
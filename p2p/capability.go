@@ -0,0 +1,123 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/liangalv/goChain/core/types"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+/*
+The wire protocol is split into capabilities, versioned sets of messages each implemented by a Protocol.
+Both sides list the capabilities they support in the handshake and every connection runs the highest version of each
+capability both sides have, so a capability can move to a new version, or a new one can be added, without every node
+on the network upgrading at once. A peer is only sent, and may only send, the messages of capabilities it shares
+with us, and protocols fall back to what the peer does support where they can.
+*/
+
+// A Capability is a versioned set of messages
+type Capability struct {
+	Name    string
+	Version uint32
+}
+
+// Capabilities of the built in protocols
+var (
+	TxCap      = Capability{Name: "tx", Version: 1}
+	BlocksCap  = Capability{Name: "blocks", Version: 2}
+	CompactCap = Capability{Name: "compact", Version: 1}
+	SyncCap    = Capability{Name: "sync", Version: 1}
+	SnapCap    = Capability{Name: "snap", Version: 2}
+	LightCap   = Capability{Name: "light", Version: 1}
+)
+
+var errUnsupported = errors.New("peer does not support the message's capability")
+
+// The capability each PeerMsg payload belongs to, keyed by payload field like messageLimits
+// The handshake and disconnect belong to the base protocol every peer runs
+var messageCapabilities = map[protoreflect.Name]string{
+	"txAnnounce":          TxCap.Name,
+	"txRequest":           TxCap.Name,
+	"transactions":        TxCap.Name,
+	"newBlock":            BlocksCap.Name,
+	"blockAnnounce":       BlocksCap.Name,
+	"blockRequest":        BlocksCap.Name,
+	"blocks":              BlocksCap.Name,
+	"compactBlock":        CompactCap.Name,
+	"blockTxsRequest":     CompactCap.Name,
+	"blockTxs":            CompactCap.Name,
+	"headersRequest":      SyncCap.Name,
+	"headers":             SyncCap.Name,
+	"bodiesRequest":       SyncCap.Name,
+	"bodies":              SyncCap.Name,
+	"accountRangeRequest": SnapCap.Name,
+	"accountRange":        SnapCap.Name,
+	"txProofRequest":      LightCap.Name,
+	"txProof":             LightCap.Name,
+	"accountProofRequest": LightCap.Name,
+	"accountProof":        LightCap.Name,
+}
+
+// Messages also handed to the protocols of other capabilities the peer shares, after those of their own. The Syncer
+// watches announcements to start syncing as soon as a peer pulls ahead rather than at its next check
+var messageWatchers = map[protoreflect.Name][]string{
+	"blockAnnounce": {SyncCap.Name},
+}
+
+func (c Capability) String() string {
+	return fmt.Sprintf("%s/%d", c.Name, c.Version)
+}
+
+// Helper functions
+// The highest version of each capability both sides support
+func negotiate(local []Capability, remote []*types.Capability) map[string]uint32 {
+	offered := map[Capability]bool{}
+	for _, c := range remote {
+		offered[Capability{Name: c.GetName(), Version: c.GetVersion()}] = true
+	}
+	shared := map[string]uint32{}
+	for _, c := range local {
+		if offered[c] && c.Version > shared[c.Name] {
+			shared[c.Name] = c.Version
+		}
+	}
+	return shared
+}
+
+// Every capability of the protocols, without duplicates and in a stable order
+func collectCapabilities(protocols []Protocol) []Capability {
+	seen := map[Capability]bool{}
+	var caps []Capability
+	for _, proto := range protocols {
+		for _, c := range proto.Capabilities() {
+			if !seen[c] {
+				seen[c] = true
+				caps = append(caps, c)
+			}
+		}
+	}
+	sort.Slice(caps, func(i, j int) bool {
+		if caps[i].Name != caps[j].Name {
+			return caps[i].Name < caps[j].Name
+		}
+		return caps[i].Version < caps[j].Version
+	})
+	return caps
+}
+
+// The capability msg belongs to, "" for base protocol messages
+func capabilityOf(msg *types.PeerMsg) string {
+	return messageCapabilities[payloadName(msg)]
+}
+
+// Name of the PeerMsg payload field that is set, "" if none is
+func payloadName(msg *types.PeerMsg) protoreflect.Name {
+	m := msg.ProtoReflect()
+	field := m.WhichOneof(m.Descriptor().Oneofs().ByName("payload"))
+	if field == nil {
+		return ""
+	}
+	return field.Name()
+}
@@ -0,0 +1,189 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	//Short IDs are the low 6 bytes of a keyed hash of the transaction ID
+	shortIDMask = 1<<48 - 1
+	//Compact blocks held while their missing transactions are fetched
+	maxPendingCompact = 64
+)
+
+// A compact block waiting on the transactions that weren't in our mempool
+type partialBlock struct {
+	peer    *Peer
+	header  *types.BlockMsg
+	trans   []*types.TransactionMsg
+	missing []uint32
+}
+
+// Build the compact form of b with a fresh salt
+func newCompactBlock(b *core.Block) *types.CompactBlock {
+	var salt [8]byte
+	rand.Read(salt[:])
+	cb := &types.CompactBlock{Header: b.ConvertToBlockPbMsg(), Salt: binary.BigEndian.Uint64(salt[:])}
+	cb.Header.Transactions = nil
+	key := compactKey(b.ID, cb.Salt)
+	for _, t := range b.Transactions() {
+		cb.ShortIDs = append(cb.ShortIDs, shortTxID(key, t.ID))
+	}
+	return cb
+}
+
+// Rebuild a compact block from the mempool, requesting whatever is missing from the peer that sent it.
+// Anything that stops the block being rebuilt falls back to requesting the full block
+func (g *BlockGossip) handleCompact(p *Peer, known *knownSet, cb *types.CompactBlock) error {
+	header := cb.GetHeader()
+	if header == nil {
+		return errors.New("compact block without a header")
+	}
+	header.Transactions = nil
+	b := core.NewBlockFromPbMsg(header)
+	known.Add(b.ID)
+	updateHead(p, b.Height(), b.ID)
+	g.mux.Lock()
+	delete(g.requested, b.ID)
+	g.mux.Unlock()
+	if _, err := g.chain.GetBlockByHash(b.ID); err == nil {
+		return nil
+	}
+	//The block can't be imported without its parent, the full block goes through the orphan path instead
+	if _, err := g.chain.GetBlockByHash(b.ParentHash()); err != nil {
+		g.requestUnknown(p, b.ID)
+		return nil
+	}
+
+	key := compactKey(b.ID, cb.GetSalt())
+	pool := map[uint64]*core.Transaction{}
+	ambiguous := map[uint64]bool{}
+	for _, t := range g.pool.PoolTransactions() {
+		id := shortTxID(key, t.ID)
+		if _, ok := pool[id]; ok {
+			ambiguous[id] = true
+		}
+		pool[id] = t
+	}
+	partial := &partialBlock{peer: p, header: header, trans: make([]*types.TransactionMsg, len(cb.GetShortIDs()))}
+	seen := map[uint64]bool{}
+	for i, id := range cb.GetShortIDs() {
+		//Two of the block's own transactions collide, there's no telling which is which
+		if seen[id] {
+			g.requestUnknown(p, b.ID)
+			return nil
+		}
+		seen[id] = true
+		if t, ok := pool[id]; ok && !ambiguous[id] {
+			partial.trans[i] = t.ConvertToTransactionPbMsg()
+		} else {
+			partial.missing = append(partial.missing, uint32(i))
+		}
+	}
+	if len(partial.missing) == 0 {
+		return g.completeCompact(b.ID, partial)
+	}
+
+	g.mux.Lock()
+	if len(g.partials) >= maxPendingCompact {
+		for hash := range g.partials {
+			delete(g.partials, hash)
+			break
+		}
+	}
+	g.partials[b.ID] = partial
+	g.mux.Unlock()
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_BlockTxsRequest{BlockTxsRequest: &types.BlockTxsRequest{
+		BlockHash: b.ID[:],
+		Indexes:   partial.missing,
+	}}})
+}
+
+// Fill in the transactions that were missing from a compact block
+func (g *BlockGossip) handleBlockTxs(p *Peer, bt *types.BlockTxs) error {
+	hash, ok := toHash(bt.GetBlockHash())
+	if !ok {
+		return errors.New("malformed block hash")
+	}
+	g.mux.Lock()
+	partial, ok := g.partials[hash]
+	if ok && partial.peer == p {
+		delete(g.partials, hash)
+	}
+	g.mux.Unlock()
+	//Unsolicited, or late after the partial was dropped
+	if !ok || partial.peer != p {
+		return nil
+	}
+	if len(bt.GetTransactions()) != len(partial.missing) {
+		g.requestUnknown(p, hash)
+		return nil
+	}
+	for i, index := range partial.missing {
+		partial.trans[index] = bt.GetTransactions()[i]
+	}
+	return g.completeCompact(hash, partial)
+}
+
+func (g *BlockGossip) serveBlockTxs(p *Peer, req *types.BlockTxsRequest) error {
+	hash, ok := toHash(req.GetBlockHash())
+	if !ok {
+		return errors.New("malformed block hash")
+	}
+	b, err := g.chain.GetBlockByHash(hash)
+	if err != nil {
+		return nil
+	}
+	trans := b.Transactions()
+	if len(req.GetIndexes()) > len(trans) {
+		return fmt.Errorf("%d transactions requested from a block of %d", len(req.GetIndexes()), len(trans))
+	}
+	res := &types.BlockTxs{BlockHash: hash[:]}
+	for _, i := range req.GetIndexes() {
+		if int(i) >= len(trans) {
+			return fmt.Errorf("transaction %d requested from a block of %d", i, len(trans))
+		}
+		res.Transactions = append(res.Transactions, trans[i].ConvertToTransactionPbMsg())
+	}
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_BlockTxs{BlockTxs: res}})
+}
+
+// Import a rebuilt block. A short ID can match the wrong mempool transaction so a block that fails validation
+// is fetched in full rather than held against the peer
+func (g *BlockGossip) completeCompact(hash [32]byte, partial *partialBlock) error {
+	partial.header.Transactions = partial.trans
+	b := core.NewBlockFromPbMsg(partial.header)
+	err := g.chain.InsertBlock(b)
+	switch {
+	case err == nil:
+		g.importOrphans(b.ID)
+	case errors.Is(err, core.ErrKnownBlock):
+	default:
+		g.requestUnknown(partial.peer, hash)
+	}
+	return nil
+}
+
+// Helper functions
+// Key for a block's short IDs, the salt makes it different for every relay of the block
+func compactKey(hash [32]byte, salt uint64) [32]byte {
+	var buf [40]byte
+	copy(buf[:], hash[:])
+	binary.BigEndian.PutUint64(buf[32:], salt)
+	return sha3.Sum256(buf[:])
+}
+
+func shortTxID(key, id [32]byte) uint64 {
+	var buf [64]byte
+	copy(buf[:], key[:])
+	copy(buf[32:], id[:])
+	h := sha3.Sum256(buf[:])
+	return binary.BigEndian.Uint64(h[:8]) & shortIDMask
+}
@@ -0,0 +1,54 @@
+package p2p
+
+import "sync"
+
+// knownSet is a bounded set of hashes a peer is known to have, once full the oldest are forgotten first
+type knownSet struct {
+	mux   sync.Mutex
+	set   map[[32]byte]struct{}
+	order [][32]byte //ring buffer in insertion order
+	next  int
+}
+
+func newKnownSet(max int) *knownSet {
+	return &knownSet{set: make(map[[32]byte]struct{}, max), order: make([][32]byte, 0, max)}
+}
+
+func (ks *knownSet) Add(hash [32]byte) {
+	ks.mux.Lock()
+	defer ks.mux.Unlock()
+	ks.add(hash)
+}
+
+func (ks *knownSet) Has(hash [32]byte) bool {
+	ks.mux.Lock()
+	defer ks.mux.Unlock()
+	_, ok := ks.set[hash]
+	return ok
+}
+
+// Adds hash and reports whether it was new, so checking and marking can't race
+func (ks *knownSet) AddIfMissing(hash [32]byte) bool {
+	ks.mux.Lock()
+	defer ks.mux.Unlock()
+	if _, ok := ks.set[hash]; ok {
+		return false
+	}
+	ks.add(hash)
+	return true
+}
+
+// Helper, caller must hold ks.mux
+func (ks *knownSet) add(hash [32]byte) {
+	if _, ok := ks.set[hash]; ok {
+		return
+	}
+	if len(ks.order) < cap(ks.order) {
+		ks.order = append(ks.order, hash)
+	} else {
+		delete(ks.set, ks.order[ks.next])
+		ks.order[ks.next] = hash
+		ks.next = (ks.next + 1) % len(ks.order)
+	}
+	ks.set[hash] = struct{}{}
+}
@@ -0,0 +1,197 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+)
+
+// ProofChain is a SyncChain that can prove its transactions and recent balances to light clients
+type ProofChain interface {
+	SyncChain
+	//Proof of a canonical transaction's receipt against its block's receiptsRoot
+	TxProof(txID [32]byte) (*core.TxProof, error)
+	//Proof of a balance in the state with the given root, core.ErrNotFound if the state isn't kept anymore
+	AccountProof(root [32]byte, address [core.AddressLength]byte) (*core.AccountProof, error)
+}
+
+// LightChain is a SyncChain of headers only
+type LightChain interface {
+	SyncChain
+	//Store headers, which follow on from a block the chain has and were checked against the header rules, making them
+	//canonical if they give the chain more total difficulty
+	InsertHeaders(headers []*core.Block) error
+}
+
+/*
+A light Syncer follows the best peer's headers without ever downloading a body or a state.
+There is no block gossip for a chain of headers to import from, so the Syncer polls every peer that is at least level
+with us and imports whatever headers they have on top of ours. Anything the light client is asked about is fetched
+from peers serving light/1 along with a proof, which is only accepted if it checks out against one of our canonical
+headers, so a peer can at worst withhold an answer.
+*/
+func NewLightSyncer(chain LightChain) *Syncer {
+	return newSyncer(chain, false)
+}
+
+// RequestTxProof fetches the proof a transaction was executed, checked against our canonical header of its block.
+// core.ErrNotFound if no peer could prove it
+func (s *Syncer) RequestTxProof(txID [32]byte) (*core.TxProof, error) {
+	for _, p := range s.lightPeers() {
+		res, err := s.request(p, func(id uint64) *types.PeerMsg {
+			return &types.PeerMsg{Payload: &types.PeerMsg_TxProofRequest{TxProofRequest: &types.GetTxProof{
+				RequestID: id, TxID: txID[:],
+			}}}
+		})
+		if err != nil {
+			continue
+		}
+		tp, err := core.NewTxProofFromPbMsg(res.GetTxProof())
+		if errors.Is(err, core.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			p.Penalize(err)
+			continue
+		}
+		if tp.Transaction.ID != txID {
+			p.Penalize(fmt.Errorf("%s proved transaction %x, requested %x", p, tp.Transaction.ID[:4], txID[:4]))
+			continue
+		}
+		//A block we don't have the header of yet, or that isn't canonical for us, can't be checked
+		header, err := s.canonicalHeader(tp.BlockHash)
+		if err != nil {
+			continue
+		}
+		if err := tp.Verify(header); err != nil {
+			p.Penalize(err)
+			continue
+		}
+		return tp, nil
+	}
+	return nil, core.ErrNotFound
+}
+
+// RequestAccountProof fetches the balance of address in the state with the given root along with its proof.
+// core.ErrNotFound if no peer still has the state
+func (s *Syncer) RequestAccountProof(root [32]byte, address [core.AddressLength]byte) (*core.AccountProof, error) {
+	for _, p := range s.lightPeers() {
+		res, err := s.request(p, func(id uint64) *types.PeerMsg {
+			return &types.PeerMsg{Payload: &types.PeerMsg_AccountProofRequest{AccountProofRequest: &types.GetAccountProof{
+				RequestID: id, Root: root[:], Address: address[:],
+			}}}
+		})
+		if err != nil {
+			continue
+		}
+		ap, err := core.NewAccountProofFromPbMsg(res.GetAccountProof())
+		if errors.Is(err, core.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			p.Penalize(err)
+			continue
+		}
+		if ap.Account.Address != address {
+			p.Penalize(fmt.Errorf("%s proved account %x, requested %x", p, ap.Account.Address[:4], address[:4]))
+			continue
+		}
+		if err := ap.Verify(root); err != nil {
+			p.Penalize(err)
+			continue
+		}
+		return ap, nil
+	}
+	return nil, core.ErrNotFound
+}
+
+// Helper methods
+// Peers serving proofs, in a stable order
+func (s *Syncer) lightPeers() []*Peer {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	var peers []*Peer
+	for p := range s.peers {
+		if p.Supports(LightCap.Name) {
+			peers = append(peers, p)
+		}
+	}
+	sortPeers(peers)
+	return peers
+}
+
+// Our header of the block with the given hash, if it is canonical
+func (s *Syncer) canonicalHeader(hash [32]byte) (*core.Block, error) {
+	header, err := s.chain.GetBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := s.chain.GetBlockByHeight(header.Height())
+	if err != nil {
+		return nil, err
+	}
+	if canonical.ID != hash {
+		return nil, fmt.Errorf("block %x is not canonical", hash[:4])
+	}
+	return header, nil
+}
+
+// Import headers verified by sync into a light chain
+func (s *Syncer) importHeaders(p *Peer, headers []*core.Block) error {
+	if err := s.light.InsertHeaders(headers); err != nil {
+		p.Penalize(err)
+		return err
+	}
+	//The peer has at least the headers it served, without gossip this is all we learn of its head
+	last := headers[len(headers)-1]
+	if height, _ := p.Head(); last.Height() > height {
+		p.SetHead(last.Height(), last.ID)
+	}
+	if td := s.chain.TotalDifficulty(); td > p.TotalDifficulty() {
+		p.SetTotalDifficulty(td)
+	}
+	s.mux.Lock()
+	s.progress.CurrentHeight = last.Height()
+	s.mux.Unlock()
+	return nil
+}
+
+func (s *Syncer) serveTxProof(p *Peer, req *types.GetTxProof) error {
+	txID, ok := toHash(req.GetTxID())
+	if !ok {
+		return errors.New("malformed transaction ID")
+	}
+	res := &types.TxProof{}
+	if s.proofs != nil {
+		if tp, err := s.proofs.TxProof(txID); err == nil {
+			res = tp.ConvertToTxProofPbMsg()
+		} else if !errors.Is(err, core.ErrNotFound) {
+			log.Printf("Proving transaction %x failed: %v", txID[:4], err)
+		}
+	}
+	res.RequestID = req.GetRequestID()
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_TxProof{TxProof: res}})
+}
+
+func (s *Syncer) serveAccountProof(p *Peer, req *types.GetAccountProof) error {
+	root, ok := toHash(req.GetRoot())
+	if !ok {
+		return errors.New("malformed state root")
+	}
+	if len(req.GetAddress()) != core.AddressLength {
+		return errors.New("malformed address")
+	}
+	var address [core.AddressLength]byte
+	copy(address[:], req.GetAddress())
+	res := &types.AccountProof{Unavailable: true}
+	if s.proofs != nil {
+		if ap, err := s.proofs.AccountProof(root, address); err == nil {
+			res = ap.ConvertToAccountProofPbMsg()
+		}
+	}
+	res.RequestID = req.GetRequestID()
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_AccountProof{AccountProof: res}})
+}
@@ -0,0 +1,139 @@
+package p2p
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	//Self-signed certificates are regenerated on every start, the key they are derived from is what persists
+	certValidity = 10 * 365 * 24 * time.Hour
+)
+
+var errNoNodeCert = errors.New("peer presented no ed25519 node certificate")
+
+/*
+Nodes are identified by an ed25519 node key, the node ID is its public key.
+Peer connections run over mutual TLS with a certificate self-signed by the node key, so each side learns the other's
+node ID from the TLS handshake and can hold it to the ID claimed in the p2p handshake.
+*/
+
+// Generate a throwaway node key, for nodes that don't need to keep their identity across restarts
+func GenerateNodeKey() ed25519.PrivateKey {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	return key
+}
+
+// Load the hex encoded node key seed at path, generating and saving a new key if there is none yet
+func LoadNodeKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		key := GenerateNodeKey()
+		if err := os.WriteFile(path, []byte(hex.EncodeToString(key.Seed())), 0600); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid node key in %s", path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func NodeIDFromKey(key ed25519.PrivateKey) [32]byte {
+	var id [32]byte
+	copy(id[:], key.Public().(ed25519.PublicKey))
+	return id
+}
+
+// Parse a hex encoded node ID, as printed in logs and taken by the allowlist
+func ParseNodeID(s string) ([32]byte, error) {
+	var id [32]byte
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != len(id) {
+		return id, fmt.Errorf("invalid node ID %q", s)
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// Helper functions
+// A certificate for key signed by key itself, the common name is the node ID
+func nodeCertificate(key ed25519.PrivateKey) (tls.Certificate, error) {
+	id := NodeIDFromKey(key)
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: hex.EncodeToString(id[:])},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// The node ID a peer's certificate chain proves, there is no CA so all that is checked is that it is self-signed by an ed25519 key
+func nodeIDFromCerts(rawCerts [][]byte) ([32]byte, error) {
+	var id [32]byte
+	if len(rawCerts) == 0 {
+		return id, errNoNodeCert
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return id, err
+	}
+	pub, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return id, errNoNodeCert
+	}
+	//CheckSignatureFrom would insist the signer is a CA
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return id, err
+	}
+	copy(id[:], pub)
+	return id, nil
+}
+
+// TLS config for both ends of a peer connection, verify is called with the remote's node ID once its certificate checks out
+func tlsConfig(cert tls.Certificate, verify func(id [32]byte)) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+		ClientAuth:   tls.RequireAnyClientCert,
+		//Node certificates are self-signed, chain verification is replaced by nodeIDFromCerts
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			id, err := nodeIDFromCerts(rawCerts)
+			if err != nil {
+				return err
+			}
+			if verify != nil {
+				verify(id)
+			}
+			return nil
+		},
+	}
+}
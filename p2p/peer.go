@@ -0,0 +1,249 @@
+package p2p
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/liangalv/goChain/core/types"
+	"google.golang.org/grpc"
+)
+
+const (
+	//Messages queued for a peer before it is considered too slow and further sends fail
+	peerQueueSize = 256
+)
+
+var (
+	errPeerClosed = errors.New("peer connection is closed")
+	errQueueFull  = errors.New("peer send queue is full")
+)
+
+// DisconnectError is returned when a connection is refused or dropped during the handshake
+type DisconnectError struct {
+	Reason types.DisconnectReason
+}
+
+func (e *DisconnectError) Error() string {
+	return fmt.Sprintf("peer disconnected: %s", e.Reason)
+}
+
+// msgStream is satisfied by both the client and server ends of a PeerService.Connect stream
+type msgStream interface {
+	Send(*types.PeerMsg) error
+	Recv() (*types.PeerMsg, error)
+}
+
+/*
+A Peer is a live, handshaken connection to another node.
+gRPC streams don't allow concurrent Sends so every outgoing message goes through queue and is written by a single
+writer goroutine, Send never blocks on the network.
+*/
+type Peer struct {
+	id         [32]byte
+	addr       string
+	listenAddr string
+	inbound    bool
+	status     *types.Handshake
+	stream     msgStream
+	teardown   func()            //ends the underlying stream, unblocking Recv
+	conn       *grpc.ClientConn  //set on outbound peers, for unary calls to the peer
+	caps       map[string]uint32 //negotiated version of each capability we share with the peer
+
+	mux        sync.RWMutex
+	headHeight uint64
+	headHash   [32]byte
+	td         uint64
+	score      int64
+
+	limiter   *rateLimiter
+	misbehave func(*Peer, error) //set by the server the peer belongs to
+
+	queue     chan *types.PeerMsg
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newPeer(stream msgStream, teardown func(), addr, listenAddr string, inbound bool, status *types.Handshake) *Peer {
+	p := &Peer{
+		addr:       addr,
+		listenAddr: listenAddr,
+		inbound:    inbound,
+		status:     status,
+		stream:     stream,
+		teardown:   teardown,
+		headHeight: status.GetHeadHeight(),
+		td:         status.GetTotalDifficulty(),
+		limiter:    newRateLimiter(),
+		queue:      make(chan *types.PeerMsg, peerQueueSize),
+		closed:     make(chan struct{}),
+	}
+	copy(p.id[:], status.GetNodeID())
+	copy(p.headHash[:], status.GetHeadHash())
+	return p
+}
+
+// Getters
+func (p *Peer) ID() [32]byte {
+	return p.id
+}
+
+// The address we dialed for outbound peers, the remote's source address for inbound peers
+func (p *Peer) Addr() string {
+	return p.addr
+}
+
+// The address the peer accepts connections on, empty if it didn't advertise one
+func (p *Peer) ListenAddr() string {
+	return p.listenAddr
+}
+
+func (p *Peer) Inbound() bool {
+	return p.inbound
+}
+
+func (p *Peer) ProtocolVersion() uint32 {
+	return p.status.GetProtocolVersion()
+}
+
+// Whether we share the capability with the peer
+func (p *Peer) Supports(name string) bool {
+	return p.caps[name] != 0
+}
+
+// The version of a capability negotiated with the peer, 0 if we don't share it
+func (p *Peer) Version(name string) uint32 {
+	return p.caps[name]
+}
+
+// Every capability negotiated with the peer, by name
+func (p *Peer) Capabilities() []Capability {
+	caps := make([]Capability, 0, len(p.caps))
+	for name, version := range p.caps {
+		caps = append(caps, Capability{Name: name, Version: version})
+	}
+	sort.Slice(caps, func(i, j int) bool { return caps[i].Name < caps[j].Name })
+	return caps
+}
+
+// The latest head the peer has told us about
+func (p *Peer) Head() (uint64, [32]byte) {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.headHeight, p.headHash
+}
+
+func (p *Peer) SetHead(height uint64, hash [32]byte) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.headHeight, p.headHash = height, hash
+}
+
+// Total difficulty of the peer's chain as of the latest head it told us about
+func (p *Peer) TotalDifficulty() uint64 {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.td
+}
+
+func (p *Peer) SetTotalDifficulty(td uint64) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.td = td
+}
+
+// Starts at 0 and drops as the peer misbehaves
+func (p *Peer) Score() int64 {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.score
+}
+
+// Penalize the peer for err, for misbehaviour noticed outside of handling one of its messages
+func (p *Peer) Penalize(err error) {
+	if p.misbehave != nil {
+		p.misbehave(p, err)
+	}
+}
+
+// Queue a message for the peer, fails rather than blocks when the peer isn't keeping up.
+// Messages of a capability the peer doesn't share with us are refused
+func (p *Peer) Send(msg *types.PeerMsg) error {
+	if name := capabilityOf(msg); name != "" && !p.Supports(name) {
+		return errUnsupported
+	}
+	select {
+	case <-p.closed:
+		return errPeerClosed
+	default:
+	}
+	select {
+	case p.queue <- msg:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// Tell the peer why we are leaving and close the connection once that has been written
+func (p *Peer) Disconnect(reason types.DisconnectReason) {
+	if err := p.Send(disconnectMsg(reason)); err != nil {
+		p.close()
+	}
+}
+
+// Closed is closed once the connection has ended
+func (p *Peer) Closed() <-chan struct{} {
+	return p.closed
+}
+
+func (p *Peer) String() string {
+	direction := "outbound"
+	if p.inbound {
+		direction = "inbound"
+	}
+	return fmt.Sprintf("peer %x@%s (%s)", p.id[:4], p.addr, direction)
+}
+
+// Helper methods
+func (p *Peer) adjustScore(delta int64) int64 {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.score += delta
+	return p.score
+}
+
+func (p *Peer) close() {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+		p.teardown()
+	})
+}
+
+// Drains the queue onto the stream until the peer closes
+func (p *Peer) writeLoop() {
+	for {
+		select {
+		case <-p.closed:
+			return
+		case msg := <-p.queue:
+			if err := p.stream.Send(msg); err != nil {
+				p.close()
+				return
+			}
+			if msg.GetDisconnect() != nil {
+				p.close()
+				return
+			}
+		}
+	}
+}
+
+// Order peers by ID so callers picking among them get a stable order
+func sortPeers(peers []*Peer) {
+	sort.Slice(peers, func(i, j int) bool {
+		return bytes.Compare(peers[i].id[:], peers[j].id[:]) < 0
+	})
+}
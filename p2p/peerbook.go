@@ -0,0 +1,345 @@
+package p2p
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//Addresses that fail this many dials in a row are forgotten
+	maxDialFailures = 5
+	//Cap on the number of addresses the book remembers
+	maxBookSize = 1000
+	//Cap on the bans of peers that dialed in, kept apart from the addresses. Past it the bans that ran out are dropped,
+	//then the one closest to running out
+	maxBanRecords = 1000
+	//A first ban lasts baseBanDuration, each ban after doubles it up to maxBanDuration
+	baseBanDuration = 10 * time.Minute
+	maxBanDuration  = 24 * time.Hour
+)
+
+// A PeerRecord is what the node remembers about an address between runs
+type PeerRecord struct {
+	Addr     string    `json:"addr"`
+	LastSeen time.Time `json:"lastSeen"`
+	Failures int       `json:"failures"`
+	//Hex node ID of the peer last banned at this address
+	NodeID      string    `json:"nodeID,omitempty"`
+	Bans        int       `json:"bans,omitempty"`
+	BannedUntil time.Time `json:"bannedUntil,omitempty"`
+}
+
+func (r *PeerRecord) banned(now time.Time) bool {
+	return now.Before(r.BannedUntil)
+}
+
+// The PeerBook holds every address the node has learnt, persisted as JSON so discovery survives restarts
+type PeerBook struct {
+	mux     sync.Mutex
+	path    string
+	records map[string]*PeerRecord
+}
+
+// Loads the peer book at path if it exists, an empty path keeps the book in memory only
+func NewPeerBook(path string) (*PeerBook, error) {
+	pb := &PeerBook{path: path, records: map[string]*PeerRecord{}}
+	if path == "" {
+		return pb, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return pb, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []*PeerRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		pb.records[r.Addr] = r
+	}
+	return pb, nil
+}
+
+// Remember an address, known addresses are left untouched
+func (pb *PeerBook) Add(addr string) {
+	if addr == "" {
+		return
+	}
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	if _, ok := pb.records[addr]; ok || len(pb.records) >= maxBookSize {
+		return
+	}
+	pb.records[addr] = &PeerRecord{Addr: addr}
+}
+
+// Record a successful connection to addr
+func (pb *PeerBook) MarkSeen(addr string) {
+	if addr == "" {
+		return
+	}
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	r, ok := pb.records[addr]
+	if !ok {
+		r = &PeerRecord{Addr: addr}
+		pb.records[addr] = r
+	}
+	r.LastSeen = time.Now()
+	r.Failures = 0
+}
+
+// Record a failed dial, the address is forgotten once it keeps failing
+func (pb *PeerBook) MarkFailed(addr string) {
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	r, ok := pb.records[addr]
+	if !ok {
+		return
+	}
+	r.Failures++
+	//Banned addresses are kept around until the ban runs out or it would be forgotten
+	if r.Failures >= maxDialFailures && !r.banned(time.Now()) {
+		delete(pb.records, addr)
+	}
+}
+
+// Bans of peers whose listen address wasn't confirmed by dialing it are kept under the node ID and the host the
+// peer connected from, so a peer can't get an address it only claims banned
+func banKey(id [32]byte, observed string) string {
+	return hex.EncodeToString(id[:]) + "@" + hostOf(observed)
+}
+
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// Whether a record only holds a ban rather than an address that can be dialed
+func (r *PeerRecord) banOnly() bool {
+	return strings.Contains(r.Addr, "@")
+}
+
+// The host a ban only record bans, empty for any other record
+func (r *PeerRecord) bannedHost() string {
+	if i := strings.LastIndex(r.Addr, "@"); i >= 0 {
+		return r.Addr[i+1:]
+	}
+	return ""
+}
+
+/*
+Ban the peer with node ID id, connected from observed, for twice as long as last time. confirmed is the peer's listen
+address if it was confirmed by dialing it, which is then banned as well. Returns how long the ban lasts
+*/
+func (pb *PeerBook) Ban(id [32]byte, observed, confirmed string) time.Duration {
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	key := banKey(id, observed)
+	r, ok := pb.records[key]
+	if !ok {
+		pb.makeBanRoom()
+		r = &PeerRecord{Addr: key}
+		pb.records[key] = r
+	}
+	d := baseBanDuration
+	for i := 0; i < r.Bans && d < maxBanDuration; i++ {
+		d *= 2
+	}
+	if d > maxBanDuration {
+		d = maxBanDuration
+	}
+	r.Bans++
+	r.NodeID = hex.EncodeToString(id[:])
+	r.BannedUntil = time.Now().Add(d)
+	//A full book leaves the confirmed address out, the node ID and host are still banned
+	if c, ok := pb.records[confirmed]; ok {
+		c.Bans, c.NodeID, c.BannedUntil = r.Bans, r.NodeID, r.BannedUntil
+	} else if confirmed != "" && len(pb.records) < maxBookSize {
+		pb.records[confirmed] = &PeerRecord{Addr: confirmed, Bans: r.Bans, NodeID: r.NodeID, BannedUntil: r.BannedUntil}
+	}
+	return d
+}
+
+// Make room for another ban only record once there are maxBanRecords of them, caller must hold pb.mux
+func (pb *PeerBook) makeBanRoom() {
+	now := time.Now()
+	var bans []*PeerRecord
+	for _, r := range pb.records {
+		if r.banOnly() {
+			bans = append(bans, r)
+		}
+	}
+	if len(bans) < maxBanRecords {
+		return
+	}
+	active := 0
+	var soonest *PeerRecord
+	for _, r := range bans {
+		if !r.banned(now) {
+			delete(pb.records, r.Addr)
+			continue
+		}
+		active++
+		if soonest == nil || r.BannedUntil.Before(soonest.BannedUntil) {
+			soonest = r
+		}
+	}
+	if active >= maxBanRecords {
+		delete(pb.records, soonest.Addr)
+	}
+}
+
+/*
+Lift a ban early, along with every other ban of the same node, the ban count is kept so the next ban still backs off.
+Reports whether addr was banned
+*/
+func (pb *PeerBook) Unban(addr string) bool {
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	now := time.Now()
+	r, ok := pb.records[addr]
+	if !ok || !r.banned(now) {
+		return false
+	}
+	for _, other := range pb.records {
+		if other.NodeID == r.NodeID && other.banned(now) {
+			other.BannedUntil = time.Time{}
+		}
+	}
+	r.BannedUntil = time.Time{}
+	return true
+}
+
+// Whether a peer is banned, by the address it connected from or its node ID
+func (pb *PeerBook) Banned(addr string, id [32]byte) bool {
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	now := time.Now()
+	if r, ok := pb.records[addr]; ok && r.banned(now) {
+		return true
+	}
+	nodeID := hex.EncodeToString(id[:])
+	for _, r := range pb.records {
+		if r.banned(now) && r.NodeID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether a banned peer connected from the host of addr, which keeps it from coming straight back from the same machine
+// under a new node key. Every other node on that host or behind the same NAT is caught by it too
+func (pb *PeerBook) BannedHost(addr string) bool {
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	now, host := time.Now(), hostOf(addr)
+	for _, r := range pb.records {
+		if r.banned(now) && r.bannedHost() == host {
+			return true
+		}
+	}
+	return false
+}
+
+// Every record under an active ban
+func (pb *PeerBook) BannedRecords() []PeerRecord {
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	now := time.Now()
+	var banned []PeerRecord
+	for _, r := range pb.records {
+		if r.banned(now) {
+			banned = append(banned, *r)
+		}
+	}
+	sort.Slice(banned, func(i, j int) bool { return banned[i].Addr < banned[j].Addr })
+	return banned
+}
+
+func (pb *PeerBook) Remove(addr string) {
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	delete(pb.records, addr)
+}
+
+func (pb *PeerBook) Get(addr string) (PeerRecord, bool) {
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	r, ok := pb.records[addr]
+	if !ok {
+		return PeerRecord{}, false
+	}
+	return *r, true
+}
+
+// Up to n addresses that aren't banned, most reliable first: fewest failures then most recently seen
+func (pb *PeerBook) Addresses(n int) []string {
+	pb.mux.Lock()
+	now := time.Now()
+	//Copied so they can be sorted outside the lock
+	records := make([]PeerRecord, 0, len(pb.records))
+	for _, r := range pb.records {
+		if !r.banned(now) && !r.banOnly() {
+			records = append(records, *r)
+		}
+	}
+	pb.mux.Unlock()
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Failures != records[j].Failures {
+			return records[i].Failures < records[j].Failures
+		}
+		return records[i].LastSeen.After(records[j].LastSeen)
+	})
+	addrs := make([]string, 0, n)
+	for i := 0; i < len(records) && i < n; i++ {
+		addrs = append(addrs, records[i].Addr)
+	}
+	return addrs
+}
+
+func (pb *PeerBook) Len() int {
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	return len(pb.records)
+}
+
+// Write the book to disk, through a temporary file so a crash never leaves it half written
+func (pb *PeerBook) Save() error {
+	if pb.path == "" {
+		return nil
+	}
+	pb.mux.Lock()
+	records := make([]*PeerRecord, 0, len(pb.records))
+	now := time.Now()
+	for _, r := range pb.records {
+		//A ban that ran out is all there is to a ban only record
+		if r.banOnly() && !r.banned(now) {
+			continue
+		}
+		records = append(records, r)
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	pb.mux.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp := pb.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, pb.path)
+}
@@ -0,0 +1,146 @@
+package p2p
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+/*
+Peers start with a score of 0 and lose points for every message that breaks the rules, a peer that falls to
+banThreshold is disconnected and banned. Penalties are picked from the error a protocol returns: anything proving the
+peer relayed invalid consensus data is as bad as it gets, sending more than its share of a message type costs a little
+each time so that sustained flooding ends in a ban while an occasional burst doesn't, as do forks too deep to reorg to.
+*/
+
+const (
+	banThreshold = -100
+
+	penaltyInvalidBlock      = 100
+	penaltyInvalidTx         = 25
+	penaltyProtocolViolation = 50
+	penaltyRateLimited       = 5
+	penaltyStaleFork         = 20
+)
+
+var errRateLimited = errors.New("message rate limit exceeded")
+
+// Points an error returned while handling a peer's message costs the peer
+func penalty(err error) int64 {
+	var blockErr *core.BlockError
+	var txErr *core.TxError
+	switch {
+	case errors.Is(err, errPeerClosed), errors.Is(err, errQueueFull), errors.Is(err, errUnsupported):
+		//Our own send queue backing up, or a protocol trying to send what the peer can't take, isn't the peer's doing
+		return 0
+	case errors.As(err, &blockErr):
+		return penaltyInvalidBlock
+	case errors.Is(err, core.ErrStaleFork):
+		//Valid but of no use to anyone, a peer that keeps sending them is wasting our replays
+		return penaltyStaleFork
+	case errors.As(err, &txErr):
+		return penaltyInvalidTx
+	case errors.Is(err, errRateLimited):
+		return penaltyRateLimited
+	default:
+		return penaltyProtocolViolation
+	}
+}
+
+// Lower the peer's score by what err costs, banning the peer once it crosses banThreshold
+func (s *Server) misbehave(p *Peer, err error) {
+	cost := penalty(err)
+	if cost == 0 {
+		return
+	}
+	score := p.adjustScore(-cost)
+	log.Printf("%s misbehaved, score %d: %v", p, score, err)
+	//Only the penalty that crosses the threshold bans, the peer may still be sending while it is disconnected
+	if score > banThreshold || score+cost <= banThreshold {
+		return
+	}
+	//An inbound peer's listen address is only what it claims, it could be another node's
+	confirmed := ""
+	if !p.inbound {
+		confirmed = p.ListenAddr()
+	}
+	d := s.book.Ban(p.id, p.Addr(), confirmed)
+	log.Printf("Banned %s for %s", p, d)
+	p.Disconnect(types.DisconnectReason_BANNED)
+}
+
+// Sustained messages per second and burst allowance of a message type
+type rateLimit struct {
+	rate  float64
+	burst float64
+}
+
+// Keyed by the PeerMsg payload field, requests made during sync are allowed the most headroom
+var (
+	messageLimits = map[protoreflect.Name]rateLimit{
+		"txAnnounce":          {rate: 20, burst: 100},
+		"txRequest":           {rate: 20, burst: 100},
+		"transactions":        {rate: 20, burst: 100},
+		"newBlock":            {rate: 2, burst: 10},
+		"compactBlock":        {rate: 2, burst: 10},
+		"blockAnnounce":       {rate: 5, burst: 20},
+		"blockRequest":        {rate: 10, burst: 50},
+		"blocks":              {rate: 10, burst: 50},
+		"blockTxsRequest":     {rate: 5, burst: 20},
+		"blockTxs":            {rate: 5, burst: 20},
+		"headersRequest":      {rate: 50, burst: 200},
+		"headers":             {rate: 50, burst: 200},
+		"bodiesRequest":       {rate: 100, burst: 400},
+		"bodies":              {rate: 100, burst: 400},
+		"accountRangeRequest": {rate: 50, burst: 200},
+		"accountRange":        {rate: 50, burst: 200},
+		"txProofRequest":      {rate: 20, burst: 100},
+		"txProof":             {rate: 20, burst: 100},
+		"accountProofRequest": {rate: 20, burst: 100},
+		"accountProof":        {rate: 20, burst: 100},
+	}
+	defaultLimit = rateLimit{rate: 10, burst: 50}
+)
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter holds a token bucket per message type, it is only used by the peer's read loop so needs no lock
+type rateLimiter struct {
+	buckets map[protoreflect.Name]*tokenBucket
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: map[protoreflect.Name]*tokenBucket{}}
+}
+
+// Take a token for msg's type, false if the bucket is empty
+func (rl *rateLimiter) allow(msg *types.PeerMsg, now time.Time) bool {
+	name := payloadName(msg)
+	if name == "" {
+		return true
+	}
+	limit, ok := messageLimits[name]
+	if !ok {
+		limit = defaultLimit
+	}
+	b, ok := rl.buckets[name]
+	if !ok {
+		b = &tokenBucket{tokens: limit.burst, last: now}
+		rl.buckets[name] = b
+	}
+	b.tokens = math.Min(limit.burst, b.tokens+now.Sub(b.last).Seconds()*limit.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
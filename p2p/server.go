@@ -0,0 +1,690 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	//ProtocolVersion is bumped on any incompatible change to the wire protocol
+	ProtocolVersion = 1
+	//DefaultMaxPeers is used when the Config leaves MaxPeers unset
+	DefaultMaxPeers = 25
+
+	//DefaultMinPeers is used when the Config leaves MinPeers unset
+	DefaultMinPeers = 8
+	//DefaultDiscoveryInterval is used when the Config leaves DiscoveryInterval unset
+	DefaultDiscoveryInterval = 10 * time.Second
+
+	handshakeTimeout = 5 * time.Second
+	dialTimeout      = 5 * time.Second
+	//Most addresses returned by GetPeers
+	maxPeersResponse = 16
+)
+
+type Config struct {
+	//Address to accept inbound connections on, e.g. ":30303"
+	ListenAddr string
+	//High watermark, cap on inbound and outbound connections combined
+	MaxPeers int
+	//Low watermark, the server keeps dialing known addresses while it has fewer peers than this
+	MinPeers int
+	//Addresses dialed when the node knows no one else, e.g. "10.0.0.1:30303"
+	Bootnodes []string
+	//File the peer book is persisted to, empty keeps it in memory
+	PeerBookPath string
+	//How often the server dials and exchanges peers to stay between the watermarks
+	DiscoveryInterval time.Duration
+	//Key the node ID and TLS certificate are derived from, nil generates a throwaway key
+	NodeKey ed25519.PrivateKey
+	//Node IDs allowed to connect, for permissioned networks. Empty allows any node
+	AllowedNodes [][32]byte
+	//Refuse inbound connections from the host of a banned peer, not just its node ID. Off by default as it also shuts
+	//out every honest node on that host or behind the same NAT, a devnet on one machine included
+	BanHosts bool
+}
+
+// Chain is the view of the BlockChain the p2p layer needs for handshakes
+type Chain interface {
+	ChainID() uint64
+	GenesisHash() [32]byte
+	LastBlock() *core.Block
+	TotalDifficulty() uint64
+}
+
+// A Protocol implements one or more capabilities. It is told about every peer that shares one of them with us and
+// handed every message of those capabilities the peer sends
+type Protocol interface {
+	Capabilities() []Capability
+	AddPeer(p *Peer)
+	RemovePeer(p *Peer)
+	HandleMsg(p *Peer, msg *types.PeerMsg) error
+}
+
+// The Server owns every peer connection, inbound over its own gRPC listener and outbound through Dial
+type Server struct {
+	types.UnimplementedPeerServiceServer
+	cfg     Config
+	chain   Chain
+	nodeID  [32]byte
+	cert    tls.Certificate
+	allowed map[[32]byte]bool
+
+	mux       sync.RWMutex
+	peers     map[[32]byte]*Peer
+	protocols []Protocol
+	//Every capability of the registered protocols and the protocols implementing each, fixed once started
+	caps   []Capability
+	routes map[Capability][]Protocol
+
+	book       *PeerBook
+	grpcServer *grpc.Server
+	listener   net.Listener
+	quit       chan struct{}
+	wg         sync.WaitGroup
+	//Every added peer until runPeer is done with it, so Stop can wait for the messages being handed to protocols
+	peerRuns sync.WaitGroup
+}
+
+func NewServer(cfg Config, chain Chain) *Server {
+	if cfg.MaxPeers <= 0 {
+		cfg.MaxPeers = DefaultMaxPeers
+	}
+	if cfg.MinPeers <= 0 {
+		cfg.MinPeers = DefaultMinPeers
+	}
+	if cfg.MinPeers > cfg.MaxPeers {
+		cfg.MinPeers = cfg.MaxPeers
+	}
+	if cfg.DiscoveryInterval <= 0 {
+		cfg.DiscoveryInterval = DefaultDiscoveryInterval
+	}
+	if cfg.NodeKey == nil {
+		cfg.NodeKey = GenerateNodeKey()
+	}
+	s := &Server{
+		cfg:    cfg,
+		chain:  chain,
+		nodeID: NodeIDFromKey(cfg.NodeKey),
+		peers:  map[[32]byte]*Peer{},
+		quit:   make(chan struct{}),
+	}
+	if len(cfg.AllowedNodes) > 0 {
+		s.allowed = map[[32]byte]bool{}
+		for _, id := range cfg.AllowedNodes {
+			s.allowed[id] = true
+		}
+	}
+	return s
+}
+
+// Protocols must be registered before the server is started
+func (s *Server) RegisterProtocol(p Protocol) {
+	s.protocols = append(s.protocols, p)
+}
+
+// Start accepting inbound connections and dialing out to known peers
+func (s *Server) Start() error {
+	book, err := NewPeerBook(s.cfg.PeerBookPath)
+	if err != nil {
+		return err
+	}
+	s.book = book
+	s.caps = collectCapabilities(s.protocols)
+	s.routes = map[Capability][]Protocol{}
+	for _, proto := range s.protocols {
+		for _, c := range proto.Capabilities() {
+			s.routes[c] = append(s.routes[c], proto)
+		}
+	}
+	cert, err := nodeCertificate(s.cfg.NodeKey)
+	if err != nil {
+		return err
+	}
+	s.cert = cert
+	lis, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return err
+	}
+	s.listener = lis
+	s.grpcServer = grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig(cert, nil))))
+	types.RegisterPeerServiceServer(s.grpcServer, s)
+	go func() {
+		if err := s.grpcServer.Serve(lis); err != nil {
+			log.Printf("p2p server stopped: %v", err)
+		}
+	}()
+	s.wg.Add(1)
+	go s.discoveryLoop()
+	return nil
+}
+
+// Disconnect every peer, stop listening and every registered protocol with a Stop method, then persist the peer book.
+// Nothing the protocols run in the background is left writing to the chain once it returns
+func (s *Server) Stop() {
+	select {
+	case <-s.quit:
+		return
+	default:
+	}
+	close(s.quit)
+	s.wg.Wait()
+	for _, p := range s.Peers() {
+		p.Disconnect(types.DisconnectReason_REQUESTED)
+	}
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+	s.peerRuns.Wait()
+	for _, proto := range s.protocols {
+		if stopper, ok := proto.(interface{ Stop() }); ok {
+			stopper.Stop()
+		}
+	}
+	if s.book != nil {
+		if err := s.book.Save(); err != nil {
+			log.Printf("Failed to save peer book: %v", err)
+		}
+	}
+}
+
+func (s *Server) PeerBook() *PeerBook {
+	return s.book
+}
+
+// The address the server is actually listening on, useful when ListenAddr used port 0
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+func (s *Server) NodeID() [32]byte {
+	return s.nodeID
+}
+
+func (s *Server) Peers() []*Peer {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	peers := make([]*Peer, 0, len(s.peers))
+	for _, p := range s.peers {
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+func (s *Server) PeerCount() int {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return len(s.peers)
+}
+
+// Dial opens an outbound connection to addr, it returns once the handshake has completed
+func (s *Server) Dial(addr string) (*Peer, error) {
+	if s.PeerCount() >= s.cfg.MaxPeers {
+		return nil, &DisconnectError{Reason: types.DisconnectReason_TOO_MANY_PEERS}
+	}
+	//The node ID the remote's certificate proves, the handshake has to claim the same one
+	verified := make(chan [32]byte, 1)
+	creds := credentials.NewTLS(tlsConfig(s.cert, func(id [32]byte) {
+		select {
+		case verified <- id:
+		default:
+		}
+	}))
+	dialCtx, cancelDial := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancelDial()
+	conn, err := grpc.DialContext(dialCtx, addr, grpc.WithTransportCredentials(creds), grpc.WithBlock())
+	if err != nil {
+		return nil, err
+	}
+	var remote [32]byte
+	select {
+	case remote = <-verified:
+	default:
+		conn.Close()
+		return nil, errNoNodeCert
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	teardown := func() {
+		cancel()
+		conn.Close()
+	}
+	stream, err := types.NewPeerServiceClient(conn).Connect(ctx)
+	if err != nil {
+		teardown()
+		return nil, err
+	}
+	status, err := s.handshake(stream, teardown, remote)
+	if err != nil {
+		teardown()
+		return nil, err
+	}
+	//The address we dialed is the one we know works, whatever the peer advertised
+	p := newPeer(stream, teardown, addr, addr, false, status)
+	p.conn = conn
+	p.caps = negotiate(s.caps, status.GetCapabilities())
+	if err := s.addPeer(p); err != nil {
+		stream.Send(disconnectMsg(err.(*DisconnectError).Reason))
+		teardown()
+		return nil, err
+	}
+	go s.runPeer(p)
+	return p, nil
+}
+
+// GetPeers implements PeerServiceServer, connected peers come first as they are known to be reachable right now
+func (s *Server) GetPeers(ctx context.Context, req *types.GetPeersRequest) (*types.PeersResponse, error) {
+	max := int(req.GetMax())
+	if max <= 0 || max > maxPeersResponse {
+		max = maxPeersResponse
+	}
+	seen := map[string]bool{}
+	res := &types.PeersResponse{}
+	add := func(addr string) {
+		if addr == "" || seen[addr] || len(res.Addrs) >= max {
+			return
+		}
+		seen[addr] = true
+		res.Addrs = append(res.Addrs, addr)
+	}
+	for _, p := range s.Peers() {
+		add(p.ListenAddr())
+	}
+	for _, addr := range s.book.Addresses(max) {
+		add(addr)
+	}
+	return res, nil
+}
+
+// Connect implements PeerServiceServer, it is the inbound side of Dial and lives as long as the connection
+func (s *Server) Connect(stream types.PeerService_ConnectServer) error {
+	addr := "unknown"
+	var remote [32]byte
+	if pr, ok := peer.FromContext(stream.Context()); ok {
+		addr = pr.Addr.String()
+		//Left zero without a certificate, which no handshake can match
+		if info, ok := pr.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+			remote, _ = nodeIDFromCerts([][]byte{info.State.PeerCertificates[0].Raw})
+		}
+	}
+	if s.PeerCount() >= s.cfg.MaxPeers {
+		return stream.Send(disconnectMsg(types.DisconnectReason_TOO_MANY_PEERS))
+	}
+	//Returning from the handler is what ends a server stream, so teardown just signals this goroutine
+	done := make(chan struct{})
+	var once sync.Once
+	teardown := func() { once.Do(func() { close(done) }) }
+	//The dialer's handshake is checked before ours is sent, so whatever we refuse it for is what its Dial returns
+	status, err := s.receiveHandshake(stream, teardown, remote)
+	if err != nil {
+		return nil
+	}
+	p := newPeer(stream, teardown, addr, dialableAddr(addr, status.GetListenAddr()), true, status)
+	p.caps = negotiate(s.caps, status.GetCapabilities())
+	if err := s.addPeer(p); err != nil {
+		return stream.Send(disconnectMsg(err.(*DisconnectError).Reason))
+	}
+	if err := s.sendHandshake(stream); err != nil {
+		s.removePeer(p)
+		s.peerRuns.Done()
+		return nil
+	}
+	go s.runPeer(p)
+	select {
+	case <-done:
+	case <-stream.Context().Done():
+		p.close()
+	}
+	return nil
+}
+
+// Helper methods
+// Exchange handshakes and check the remote is on the same chain, the remote is told why if it isn't
+func (s *Server) handshake(stream msgStream, teardown func(), remote [32]byte) (*types.Handshake, error) {
+	if err := s.sendHandshake(stream); err != nil {
+		return nil, err
+	}
+	return s.receiveHandshake(stream, teardown, remote)
+}
+
+func (s *Server) sendHandshake(stream msgStream) error {
+	return stream.Send(&types.PeerMsg{Payload: &types.PeerMsg_Handshake{Handshake: s.localHandshake()}})
+}
+
+// Wait for the remote's handshake and check it is on our chain and who its certificate says it is, refusing it with a Disconnect if not
+func (s *Server) receiveHandshake(stream msgStream, teardown func(), remote [32]byte) (*types.Handshake, error) {
+	type result struct {
+		msg *types.PeerMsg
+		err error
+	}
+	received := make(chan result, 1)
+	go func() {
+		msg, err := stream.Recv()
+		received <- result{msg, err}
+	}()
+	var res result
+	select {
+	case res = <-received:
+	case <-time.After(handshakeTimeout):
+		teardown()
+		return nil, errors.New("timed out waiting for handshake")
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	if d := res.msg.GetDisconnect(); d != nil {
+		return nil, &DisconnectError{Reason: d.GetReason()}
+	}
+	status := res.msg.GetHandshake()
+	if status == nil {
+		stream.Send(disconnectMsg(types.DisconnectReason_BAD_HANDSHAKE))
+		return nil, &DisconnectError{Reason: types.DisconnectReason_BAD_HANDSHAKE}
+	}
+	if reason, ok := s.checkHandshake(status, remote); !ok {
+		stream.Send(disconnectMsg(reason))
+		return nil, &DisconnectError{Reason: reason}
+	}
+	return status, nil
+}
+
+func (s *Server) localHandshake() *types.Handshake {
+	genesis := s.chain.GenesisHash()
+	head := s.chain.LastBlock()
+	caps := make([]*types.Capability, len(s.caps))
+	for i, c := range s.caps {
+		caps[i] = &types.Capability{Name: c.Name, Version: c.Version}
+	}
+	return &types.Handshake{
+		ProtocolVersion: ProtocolVersion,
+		ChainID:         s.chain.ChainID(),
+		GenesisHash:     genesis[:],
+		HeadHeight:      head.Height(),
+		HeadHash:        head.ID[:],
+		NodeID:          s.nodeID[:],
+		ListenAddr:      s.Addr(),
+		TotalDifficulty: s.chain.TotalDifficulty(),
+		Capabilities:    caps,
+	}
+}
+
+// remote is the node ID proven by the connection's TLS certificate
+func (s *Server) checkHandshake(h *types.Handshake, remote [32]byte) (types.DisconnectReason, bool) {
+	genesis := s.chain.GenesisHash()
+	switch {
+	case h.GetProtocolVersion() != ProtocolVersion:
+		return types.DisconnectReason_PROTOCOL_MISMATCH, false
+	case h.GetChainID() != s.chain.ChainID():
+		return types.DisconnectReason_CHAIN_ID_MISMATCH, false
+	case !bytes.Equal(h.GetGenesisHash(), genesis[:]):
+		return types.DisconnectReason_GENESIS_MISMATCH, false
+	case bytes.Equal(h.GetNodeID(), s.nodeID[:]):
+		return types.DisconnectReason_SELF_CONNECTION, false
+	case !bytes.Equal(h.GetNodeID(), remote[:]):
+		return types.DisconnectReason_IDENTITY_MISMATCH, false
+	case s.allowed != nil && !s.allowed[remote]:
+		return types.DisconnectReason_NOT_ALLOWED, false
+	case len(s.caps) > 0 && len(negotiate(s.caps, h.GetCapabilities())) == 0:
+		return types.DisconnectReason_NO_SHARED_CAPABILITIES, false
+	}
+	return 0, true
+}
+
+// Register a handshaken peer, enforcing MaxPeers and rejecting a second connection to the same node
+func (s *Server) addPeer(p *Peer) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	//Checked under s.mux so no peer is added once Stop has listed the peers to disconnect
+	select {
+	case <-s.quit:
+		return &DisconnectError{Reason: types.DisconnectReason_REQUESTED}
+	default:
+	}
+	//Host bans come from the addresses peers dialed in from, a dialed address is only banned by its own record
+	if s.book.Banned(p.Addr(), p.id) || (s.cfg.BanHosts && p.inbound && s.book.BannedHost(p.Addr())) {
+		return &DisconnectError{Reason: types.DisconnectReason_BANNED}
+	}
+	if existing, ok := s.peers[p.id]; ok {
+		//Two nodes dialing each other at once would otherwise each reject the other's connection and end up with
+		//neither, both sides agree to keep the connection dialed by the node with the lower ID
+		if !s.preferred(p, existing) {
+			return &DisconnectError{Reason: types.DisconnectReason_ALREADY_CONNECTED}
+		}
+		existing.Disconnect(types.DisconnectReason_ALREADY_CONNECTED)
+	} else if len(s.peers) >= s.cfg.MaxPeers {
+		return &DisconnectError{Reason: types.DisconnectReason_TOO_MANY_PEERS}
+	}
+	s.peers[p.id] = p
+	s.peerRuns.Add(1)
+	p.misbehave = s.misbehave
+	s.book.MarkSeen(p.ListenAddr())
+	return nil
+}
+
+// Whether connection p should replace existing, a connection to the same node in the opposite direction
+func (s *Server) preferred(p, existing *Peer) bool {
+	if p.inbound == existing.inbound {
+		return false
+	}
+	dialer := s.nodeID
+	if p.inbound {
+		dialer = p.id
+	}
+	other := s.nodeID
+	if dialer == s.nodeID {
+		other = p.id
+	}
+	return bytes.Compare(dialer[:], other[:]) < 0
+}
+
+// Hand msg to the protocols implementing the version of its capability negotiated with the peer, then to any watching it
+func (s *Server) dispatch(p *Peer, msg *types.PeerMsg) {
+	name := capabilityOf(msg)
+	if !p.Supports(name) {
+		s.misbehave(p, fmt.Errorf("%q message of a capability we don't share", payloadName(msg)))
+		return
+	}
+	protos := s.routes[Capability{Name: name, Version: p.Version(name)}]
+	for _, watcher := range messageWatchers[payloadName(msg)] {
+		if !p.Supports(watcher) {
+			continue
+		}
+		for _, proto := range s.routes[Capability{Name: watcher, Version: p.Version(watcher)}] {
+			//A protocol of both capabilities already has the message
+			if !slices.Contains(protos, proto) {
+				protos = append(protos[:len(protos):len(protos)], proto)
+			}
+		}
+	}
+	for _, proto := range protos {
+		if err := proto.HandleMsg(p, msg); err != nil {
+			s.misbehave(p, err)
+		}
+	}
+}
+
+// The registered protocols sharing at least one capability with the peer
+func (s *Server) sharedProtocols(p *Peer) []Protocol {
+	var shared []Protocol
+	for _, proto := range s.protocols {
+		for _, c := range proto.Capabilities() {
+			if p.Version(c.Name) == c.Version {
+				shared = append(shared, proto)
+				break
+			}
+		}
+	}
+	return shared
+}
+
+func (s *Server) removePeer(p *Peer) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.peers[p.id] == p {
+		delete(s.peers, p.id)
+	}
+}
+
+// Runs the peer until its connection ends, dispatching every message to the protocols of its capability
+func (s *Server) runPeer(p *Peer) {
+	go p.writeLoop()
+	protocols := s.sharedProtocols(p)
+	for _, proto := range protocols {
+		proto.AddPeer(p)
+	}
+	defer func() {
+		p.close()
+		s.removePeer(p)
+		for _, proto := range protocols {
+			proto.RemovePeer(p)
+		}
+		s.peerRuns.Done()
+	}()
+	for {
+		msg, err := p.stream.Recv()
+		if err != nil {
+			return
+		}
+		switch msg.GetPayload().(type) {
+		case *types.PeerMsg_Disconnect:
+			return
+		case *types.PeerMsg_Handshake:
+			p.Disconnect(types.DisconnectReason_BAD_HANDSHAKE)
+			<-p.Closed()
+			return
+		}
+		//Messages over the limit are dropped unhandled
+		if !p.limiter.allow(msg, time.Now()) {
+			s.misbehave(p, errRateLimited)
+			if p.Score() <= banThreshold {
+				<-p.Closed()
+				return
+			}
+			continue
+		}
+		s.dispatch(p, msg)
+		if p.Score() <= banThreshold {
+			//Let the writer flush the disconnect before the deferred close tears the stream down
+			<-p.Closed()
+			return
+		}
+	}
+}
+
+// Keeps the peer count between the watermarks, learning new addresses from peers as it goes
+func (s *Server) discoveryLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.cfg.DiscoveryInterval)
+	defer ticker.Stop()
+	for {
+		s.discover()
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) discover() {
+	for _, addr := range s.cfg.Bootnodes {
+		s.book.Add(addr)
+	}
+	s.exchangePeers()
+	if need := s.cfg.MinPeers - s.PeerCount(); need > 0 {
+		s.dialCandidates(need)
+	}
+	if err := s.book.Save(); err != nil {
+		log.Printf("Failed to save peer book: %v", err)
+	}
+}
+
+// Ask outbound peers for the addresses they know, inbound peers have no client connection to ask through
+func (s *Server) exchangePeers() {
+	for _, p := range s.Peers() {
+		if p.conn == nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+		res, err := types.NewPeerServiceClient(p.conn).GetPeers(ctx, &types.GetPeersRequest{Max: maxPeersResponse})
+		cancel()
+		if err != nil {
+			continue
+		}
+		for _, addr := range res.GetAddrs() {
+			s.book.Add(addr)
+		}
+	}
+}
+
+// Dial up to n known addresses that we aren't already connected to
+func (s *Server) dialCandidates(n int) {
+	connected := map[string]bool{s.Addr(): true}
+	for _, p := range s.Peers() {
+		connected[p.Addr()] = true
+		connected[p.ListenAddr()] = true
+	}
+	for _, addr := range s.book.Addresses(s.book.Len()) {
+		if n == 0 {
+			return
+		}
+		select {
+		case <-s.quit:
+			return
+		default:
+		}
+		if connected[addr] {
+			continue
+		}
+		_, err := s.Dial(addr)
+		var disc *DisconnectError
+		switch {
+		case err == nil:
+			n--
+		case errors.As(err, &disc) && disc.Reason == types.DisconnectReason_SELF_CONNECTION:
+			s.book.Remove(addr)
+		case errors.As(err, &disc) && disc.Reason == types.DisconnectReason_ALREADY_CONNECTED:
+			//Reachable, just under a different address than the one we have for it
+		case errors.As(err, &disc) && disc.Reason == types.DisconnectReason_TOO_MANY_PEERS:
+			s.book.MarkSeen(addr)
+		default:
+			s.book.MarkFailed(addr)
+		}
+	}
+}
+
+// Peers advertise the address they listen on, which is often unspecified (":30303", "[::]:30303")
+// in which case the host they connected to us from is combined with the advertised port
+func dialableAddr(observed, advertised string) string {
+	host, port, err := net.SplitHostPort(advertised)
+	if err != nil || port == "0" {
+		return ""
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host, _, err = net.SplitHostPort(observed)
+		if err != nil {
+			return ""
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func disconnectMsg(reason types.DisconnectReason) *types.PeerMsg {
+	return &types.PeerMsg{Payload: &types.PeerMsg_Disconnect{Disconnect: &types.Disconnect{Reason: reason}}}
+}
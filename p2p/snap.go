@@ -0,0 +1,143 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+)
+
+const (
+	//How far behind the peer's head the snap sync pivot is. There is no finality rule yet so a block this deep is
+	//taken as final, it has to stay well within the number of recent states peers keep around to serve
+	SnapPivotDepth = 64
+	//Cap on accounts per range request
+	maxAccountsPerMsg = 256
+)
+
+var errStateUnavailable = errors.New("peer no longer has the state")
+
+// StateChain is a SyncChain that can serve its recent states to snap syncing peers and adopt a downloaded state itself
+type StateChain interface {
+	SyncChain
+	//A range of the accounts of the state with the given root, core.ErrNotFound if the state isn't kept anymore
+	AccountRange(root [32]byte, origin [32]byte, max int) (*core.AccountRange, error)
+	//Make the last header the head with state as its state, headers follow on from genesis and have no bodies
+	InsertSnapshot(headers []*core.Block, state *core.State) error
+	//Height of the block the chain was snap synced at, blocks up to it have no bodies. 0 if every block was imported
+	SnapshotHeight() uint64
+}
+
+/*
+A snap Syncer joining the network with nothing but the genesis block doesn't replay every block.
+It picks a pivot block SnapPivotDepth below the best peer's head, downloads and checks the headers up to it, then
+downloads the state as of the pivot in ranges of accounts, each verified against the pivot's state root before it is
+accepted. Once the whole state is in, the pivot becomes the head and blocks above it are imported as usual.
+*/
+func NewSnapSyncer(chain StateChain) *Syncer {
+	return newSyncer(chain, true)
+}
+
+// Helper methods
+// Snap sync to the block at height pivot
+func (s *Syncer) snapSync(peer *Peer, pivot uint64) error {
+	log.Printf("Snap syncing from %s at pivot height %d", peer, pivot)
+	parent := s.chain.LastBlock()
+	headers := make([]*core.Block, 0, pivot)
+	for from := uint64(1); from <= pivot; {
+		count := pivot - from + 1
+		if count > maxHeadersPerMsg {
+			count = maxHeadersPerMsg
+		}
+		batch, err := s.fetchHeaders(peer, from, int(count))
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return fmt.Errorf("%s has no header at height %d", peer, from)
+		}
+		if err := checkHeaderChain(parent, from, batch); err != nil {
+			peer.Penalize(err)
+			return err
+		}
+		headers = append(headers, batch...)
+		parent = batch[len(batch)-1]
+		from += uint64(len(batch))
+	}
+
+	root := parent.StateRoot()
+	balances := map[[core.AddressLength]byte]uint64{}
+	for origin, more := [32]byte{}, true; more; {
+		r, err := s.fetchAccountRange(peer, root, origin)
+		if err != nil {
+			return err
+		}
+		for _, a := range r.Accounts {
+			balances[a.Address] = a.Balance
+		}
+		s.mux.Lock()
+		s.progress.SyncedAccounts, s.progress.TotalAccounts = uint64(len(balances)), r.Total
+		s.mux.Unlock()
+		origin, more = r.Next()
+	}
+	if err := s.state.InsertSnapshot(headers, core.NewState(balances)); err != nil {
+		return err
+	}
+	log.Printf("Snap synced %d accounts at height %d", len(balances), pivot)
+	return nil
+}
+
+// Fetch and verify the accounts from key origin of the state with the given root
+func (s *Syncer) fetchAccountRange(p *Peer, root [32]byte, origin [32]byte) (*core.AccountRange, error) {
+	res, err := s.request(p, func(id uint64) *types.PeerMsg {
+		return &types.PeerMsg{Payload: &types.PeerMsg_AccountRangeRequest{AccountRangeRequest: &types.GetAccountRange{
+			RequestID: id, Root: root[:], Origin: origin[:], Max: maxAccountsPerMsg,
+		}}}
+	})
+	if err != nil {
+		return nil, err
+	}
+	msg := res.GetAccountRange()
+	if len(msg.GetAccounts()) == 0 && !msg.GetLast() {
+		return nil, errStateUnavailable
+	}
+	r, err := core.NewAccountRangeFromPbMsg(msg)
+	if err != nil {
+		p.Penalize(err)
+		return nil, err
+	}
+	if err := r.Verify(root); err != nil {
+		p.Penalize(err)
+		return nil, fmt.Errorf("account range from %s: %w", p, err)
+	}
+	if r.Origin != origin || len(r.Accounts) > maxAccountsPerMsg {
+		err := fmt.Errorf("%s returned %d accounts from %x, requested %d from %x", p, len(r.Accounts), r.Origin[:4], maxAccountsPerMsg, origin[:4])
+		p.Penalize(err)
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *Syncer) serveAccountRange(p *Peer, req *types.GetAccountRange) error {
+	if req.GetMax() > maxAccountsPerMsg {
+		return fmt.Errorf("%d accounts requested, at most %d are served", req.GetMax(), maxAccountsPerMsg)
+	}
+	root, ok := toHash(req.GetRoot())
+	if !ok {
+		return errors.New("malformed state root")
+	}
+	origin, ok := toHash(req.GetOrigin())
+	if !ok {
+		return errors.New("malformed range origin")
+	}
+	res := &types.AccountRange{}
+	if s.state != nil {
+		if r, err := s.state.AccountRange(root, origin, int(req.GetMax())); err == nil {
+			res = r.ConvertToAccountRangePbMsg()
+		}
+	}
+	res.RequestID = req.GetRequestID()
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_AccountRange{AccountRange: res}})
+}
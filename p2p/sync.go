@@ -0,0 +1,574 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+)
+
+const (
+	//Cap on headers per response and on bodies per request
+	maxHeadersPerMsg = 192
+	maxBodiesPerMsg  = 32
+	//How long a header or body request may go unanswered before it is retried elsewhere
+	syncRequestTimeout = 5 * time.Second
+	//Attempts at fetching a chunk of bodies before the round is abandoned
+	maxBodyAttempts = 3
+	//How often the Syncer checks whether a peer is ahead of us
+	syncInterval = 2 * time.Second
+)
+
+var (
+	errSyncTimeout = errors.New("sync request timed out")
+	errNoHeaders   = errors.New("peer served no headers past our chain despite advertising more total difficulty")
+)
+
+// SyncChain is the view of the chain initial block download serves from and imports into
+type SyncChain interface {
+	BlockChain
+	LastBlock() *core.Block
+	//Canonical block at height
+	GetBlockByHeight(height uint64) (*core.Block, error)
+}
+
+// SyncProgress reports how far a sync has got, heights are those of our head and the peer we're syncing from
+type SyncProgress struct {
+	Syncing        bool
+	StartingHeight uint64
+	CurrentHeight  uint64
+	HighestHeight  uint64
+	//Accounts downloaded so far of those in the state being snap synced
+	SyncedAccounts uint64
+	TotalAccounts  uint64
+}
+
+/*
+The Syncer catches the node up with the network.
+Whenever a peer advertises more total difficulty than our chain has, the Syncer downloads that peer's canonical
+headers in batches, checks every parentHash link and header rule before any bodies are fetched, then spreads the body
+requests over every peer that has the blocks and imports the assembled blocks in order. Every block is persisted as it
+is imported so a node restarted mid sync resumes from its head rather than from genesis.
+*/
+type Syncer struct {
+	chain SyncChain
+	//Set when the chain can serve its state to snap syncing peers
+	state StateChain
+	snap  bool
+	//Set when the chain can prove transactions and balances to light clients
+	proofs ProofChain
+	//Set when the chain keeps headers only
+	light LightChain
+
+	mux      sync.Mutex
+	peers    map[*Peer]bool
+	pending  map[uint64]*syncRequest
+	nextID   uint64
+	progress SyncProgress
+
+	trigger chan struct{}
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+type syncRequest struct {
+	peer     *Peer
+	response chan *types.PeerMsg
+}
+
+func NewSyncer(chain SyncChain) *Syncer {
+	return newSyncer(chain, false)
+}
+
+func newSyncer(chain SyncChain, snap bool) *Syncer {
+	s := &Syncer{
+		chain:   chain,
+		snap:    snap,
+		peers:   map[*Peer]bool{},
+		pending: map[uint64]*syncRequest{},
+		trigger: make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.state, _ = chain.(StateChain)
+	s.proofs, _ = chain.(ProofChain)
+	s.light, _ = chain.(LightChain)
+	go s.loop()
+	return s
+}
+
+func (s *Syncer) Stop() {
+	//The Server stops its protocols, which may already have been stopped by hand
+	select {
+	case <-s.quit:
+		return
+	default:
+	}
+	close(s.quit)
+	<-s.done
+}
+
+func (s *Syncer) Progress() SyncProgress {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.progress
+}
+
+// Capabilities implements Protocol, state and proofs are only offered by a chain that can serve them
+func (s *Syncer) Capabilities() []Capability {
+	caps := []Capability{SyncCap}
+	if s.state != nil {
+		caps = append(caps, SnapCap)
+	}
+	if s.proofs != nil || s.light != nil {
+		caps = append(caps, LightCap)
+	}
+	return caps
+}
+
+// AddPeer implements Protocol
+func (s *Syncer) AddPeer(p *Peer) {
+	s.mux.Lock()
+	s.peers[p] = true
+	s.mux.Unlock()
+	s.poke()
+}
+
+// RemovePeer implements Protocol
+func (s *Syncer) RemovePeer(p *Peer) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.peers, p)
+}
+
+// HandleMsg implements Protocol
+func (s *Syncer) HandleMsg(p *Peer, msg *types.PeerMsg) error {
+	switch payload := msg.GetPayload().(type) {
+	case *types.PeerMsg_HeadersRequest:
+		return s.serveHeaders(p, payload.HeadersRequest)
+	case *types.PeerMsg_BodiesRequest:
+		return s.serveBodies(p, payload.BodiesRequest)
+	case *types.PeerMsg_Headers:
+		s.deliver(p, payload.Headers.GetRequestID(), msg)
+	case *types.PeerMsg_Bodies:
+		s.deliver(p, payload.Bodies.GetRequestID(), msg)
+	case *types.PeerMsg_AccountRangeRequest:
+		return s.serveAccountRange(p, payload.AccountRangeRequest)
+	case *types.PeerMsg_AccountRange:
+		s.deliver(p, payload.AccountRange.GetRequestID(), msg)
+	case *types.PeerMsg_TxProofRequest:
+		return s.serveTxProof(p, payload.TxProofRequest)
+	case *types.PeerMsg_TxProof:
+		s.deliver(p, payload.TxProof.GetRequestID(), msg)
+	case *types.PeerMsg_AccountProofRequest:
+		return s.serveAccountProof(p, payload.AccountProofRequest)
+	case *types.PeerMsg_AccountProof:
+		s.deliver(p, payload.AccountProof.GetRequestID(), msg)
+	case *types.PeerMsg_BlockAnnounce:
+		//A peer pulling ahead may be further than gossip can catch us up
+		if payload.BlockAnnounce.GetTotalDifficulty() > s.chain.TotalDifficulty() {
+			s.poke()
+		}
+	}
+	return nil
+}
+
+// Helper methods
+func (s *Syncer) poke() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Syncer) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+		case <-s.trigger:
+		}
+		s.sync()
+	}
+}
+
+// The peer advertising the most total difficulty, other than those in tried, nil if no one is ahead of us.
+// A light chain hears of no new blocks, so it also polls peers that are level with us
+func (s *Syncer) bestPeer(tried map[*Peer]bool) *Peer {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	var best *Peer
+	td := s.chain.TotalDifficulty()
+	if s.light != nil && td > 0 {
+		td--
+	}
+	for p := range s.peers {
+		if !tried[p] && p.Supports(SyncCap.Name) && p.TotalDifficulty() > td {
+			best, td = p, p.TotalDifficulty()
+		}
+	}
+	return best
+}
+
+// Sync with the best peer, moving on to the next best whenever one leaves us short of the difficulty it advertised
+func (s *Syncer) sync() {
+	tried := map[*Peer]bool{}
+	for {
+		peer := s.bestPeer(tried)
+		if peer == nil {
+			return
+		}
+		tried[peer] = true
+		s.syncWith(peer)
+		//Whether it lied about its chain or stopped answering, the peer only counts as ahead of us again once it
+		//announces a new head
+		td := s.chain.TotalDifficulty()
+		if peer.TotalDifficulty() <= td {
+			return
+		}
+		peer.SetTotalDifficulty(td)
+		select {
+		case <-s.quit:
+			return
+		default:
+		}
+	}
+}
+
+// Sync with peer until we have every header it served us
+func (s *Syncer) syncWith(peer *Peer) {
+	target, _ := peer.Head()
+	start := s.chain.LastBlock().Height()
+	s.setProgress(SyncProgress{Syncing: true, StartingHeight: start, CurrentHeight: start, HighestHeight: target})
+	defer func() {
+		s.mux.Lock()
+		s.progress.Syncing = false
+		s.mux.Unlock()
+	}()
+	if s.light == nil {
+		log.Printf("Syncing from %s, height %d to %d", peer, start, target)
+	}
+	//A node with nothing but the genesis block downloads a recent state rather than replaying every block
+	if s.snap && start == 0 && target > SnapPivotDepth && !peer.Supports(SnapCap.Name) {
+		log.Printf("%s does not serve state, importing every block instead", peer)
+	} else if s.snap && start == 0 && target > SnapPivotDepth {
+		if err := s.snapSync(peer, target-SnapPivotDepth); err != nil {
+			log.Printf("Snap sync with %s failed: %v", peer, err)
+			return
+		}
+		start = target - SnapPivotDepth
+		s.mux.Lock()
+		s.progress.CurrentHeight = start
+		s.mux.Unlock()
+	}
+
+	from, err := s.findAncestor(peer, start)
+	if err != nil {
+		log.Printf("Sync with %s failed: %v", peer, err)
+		return
+	}
+	for first := from; ; {
+		headers, err := s.fetchHeaders(peer, from, maxHeadersPerMsg)
+		if err != nil {
+			log.Printf("Sync with %s failed: %v", peer, err)
+			return
+		}
+		if len(headers) == 0 {
+			//A peer with more total difficulty has a block above the one we share, light clients poll level peers too
+			if from == first && peer.TotalDifficulty() > s.chain.TotalDifficulty() {
+				log.Printf("Sync with %s failed: %v", peer, errNoHeaders)
+				peer.Penalize(errNoHeaders)
+			}
+			break
+		}
+		if err := s.verifyHeaders(from, headers); err != nil {
+			log.Printf("Sync with %s failed: %v", peer, err)
+			peer.Penalize(err)
+			return
+		}
+		if s.light != nil {
+			err = s.importHeaders(peer, headers)
+		} else {
+			err = s.downloadBodies(headers)
+		}
+		if err != nil {
+			log.Printf("Sync with %s failed: %v", peer, err)
+			return
+		}
+		from += uint64(len(headers))
+		if s.light == nil {
+			log.Printf("Synced to height %d of %d", from-1, target)
+		}
+		if len(headers) < maxHeadersPerMsg {
+			break
+		}
+	}
+}
+
+func (s *Syncer) setProgress(p SyncProgress) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.progress = p
+}
+
+// Height to start downloading from, the first height above a block we share with the peer.
+// Probes back from our head in growing steps, the genesis block is always shared since the handshake checked it
+func (s *Syncer) findAncestor(p *Peer, head uint64) (uint64, error) {
+	height, step := head, uint64(1)
+	if peerHeight, _ := p.Head(); peerHeight < height {
+		height = peerHeight
+	}
+	for height > 0 {
+		headers, err := s.fetchHeaders(p, height, 1)
+		if err != nil {
+			return 0, err
+		}
+		if len(headers) == 1 {
+			if _, err := s.chain.GetBlockByHash(headers[0].ID); err == nil {
+				return height + 1, nil
+			}
+		}
+		if step > height {
+			break
+		}
+		height -= step
+		step *= 2
+	}
+	return 1, nil
+}
+
+// Checks the headers link up from a block we already have and each follows the header rules
+func (s *Syncer) verifyHeaders(from uint64, headers []*core.Block) error {
+	parent, err := s.chain.GetBlockByHash(headers[0].ParentHash())
+	if err != nil {
+		return fmt.Errorf("first header at height %d has an unknown parent", from)
+	}
+	return checkHeaderChain(parent, from, headers)
+}
+
+// Checks headers, starting at height from, link up from parent and each follows the header rules
+func checkHeaderChain(parent *core.Block, from uint64, headers []*core.Block) error {
+	for i, h := range headers {
+		if h.Height() != from+uint64(i) {
+			return fmt.Errorf("header at height %d, expected %d", h.Height(), from+uint64(i))
+		}
+		if err := h.VerifyHeader(parent); err != nil {
+			return fmt.Errorf("header %d: %w", h.Height(), err)
+		}
+		parent = h
+	}
+	return nil
+}
+
+// Fetch bodies for the headers spread across peers, then import the assembled blocks in order
+func (s *Syncer) downloadBodies(headers []*core.Block) error {
+	type chunk struct {
+		headers []*core.Block
+		bodies  []*types.TransactionBatch
+		peer    *Peer
+		err     error
+	}
+	var chunks []*chunk
+	for i := 0; i < len(headers); i += maxBodiesPerMsg {
+		end := i + maxBodiesPerMsg
+		if end > len(headers) {
+			end = len(headers)
+		}
+		chunks = append(chunks, &chunk{headers: headers[i:end]})
+	}
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func(i int, c *chunk) {
+			defer wg.Done()
+			last := c.headers[len(c.headers)-1].Height()
+			for attempt := 0; attempt < maxBodyAttempts; attempt++ {
+				c.peer = s.bodyPeer(last, i+attempt)
+				if c.peer == nil {
+					c.err = errors.New("no peer has the blocks")
+					return
+				}
+				c.bodies, c.err = s.fetchBodies(c.peer, c.headers)
+				if c.err == nil {
+					return
+				}
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, c := range chunks {
+		if c.err != nil {
+			return c.err
+		}
+		for i, h := range c.headers {
+			msg := h.ConvertToBlockPbMsg()
+			msg.Transactions = c.bodies[i].GetBatch()
+			b := core.NewBlockFromPbMsg(msg)
+			if err := s.chain.InsertBlock(b); err != nil && !errors.Is(err, core.ErrKnownBlock) {
+				//Headers were already checked so a block failing now has a bad body
+				c.peer.Penalize(err)
+				return fmt.Errorf("block %d from %s: %w", b.Height(), c.peer, err)
+			}
+			s.mux.Lock()
+			s.progress.CurrentHeight = b.Height()
+			s.mux.Unlock()
+		}
+	}
+	return nil
+}
+
+// Pick a peer whose head is at least height, rotating through them by n so chunks spread out
+func (s *Syncer) bodyPeer(height uint64, n int) *Peer {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	var candidates []*Peer
+	for p := range s.peers {
+		if h, _ := p.Head(); h >= height && p.Supports(SyncCap.Name) {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	//Map iteration order is random, sort so rotating by n actually visits each candidate
+	sortPeers(candidates)
+	return candidates[n%len(candidates)]
+}
+
+func (s *Syncer) fetchHeaders(p *Peer, from uint64, count int) ([]*core.Block, error) {
+	res, err := s.request(p, func(id uint64) *types.PeerMsg {
+		return &types.PeerMsg{Payload: &types.PeerMsg_HeadersRequest{HeadersRequest: &types.GetBlockHeaders{
+			RequestID: id, FromHeight: from, Count: uint32(count),
+		}}}
+	})
+	if err != nil {
+		return nil, err
+	}
+	msgs := res.GetHeaders().GetHeaders()
+	if len(msgs) > count {
+		return nil, fmt.Errorf("%d headers returned, %d requested", len(msgs), count)
+	}
+	headers := make([]*core.Block, len(msgs))
+	for i, msg := range msgs {
+		headers[i] = core.NewBlockFromPbMsg(msg)
+	}
+	return headers, nil
+}
+
+func (s *Syncer) fetchBodies(p *Peer, headers []*core.Block) ([]*types.TransactionBatch, error) {
+	hashes := make([][]byte, len(headers))
+	for i, h := range headers {
+		hashes[i] = h.ID[:]
+	}
+	res, err := s.request(p, func(id uint64) *types.PeerMsg {
+		return &types.PeerMsg{Payload: &types.PeerMsg_BodiesRequest{BodiesRequest: &types.GetBlockBodies{
+			RequestID: id, Hashes: hashes,
+		}}}
+	})
+	if err != nil {
+		return nil, err
+	}
+	bodies := res.GetBodies().GetBodies()
+	if len(bodies) != len(headers) {
+		return nil, fmt.Errorf("%s returned %d of %d bodies", p, len(bodies), len(headers))
+	}
+	return bodies, nil
+}
+
+// Send a request built around a fresh request ID and wait for the matching response
+func (s *Syncer) request(p *Peer, build func(id uint64) *types.PeerMsg) (*types.PeerMsg, error) {
+	req := &syncRequest{peer: p, response: make(chan *types.PeerMsg, 1)}
+	s.mux.Lock()
+	s.nextID++
+	id := s.nextID
+	s.pending[id] = req
+	s.mux.Unlock()
+	defer func() {
+		s.mux.Lock()
+		delete(s.pending, id)
+		s.mux.Unlock()
+	}()
+	if err := p.Send(build(id)); err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(syncRequestTimeout)
+	defer timeout.Stop()
+	select {
+	case res := <-req.response:
+		return res, nil
+	case <-p.Closed():
+		return nil, errPeerClosed
+	case <-timeout.C:
+		return nil, errSyncTimeout
+	case <-s.quit:
+		return nil, errors.New("syncer stopped")
+	}
+}
+
+// Hand a response to the request waiting on it, responses nobody is waiting for are dropped
+func (s *Syncer) deliver(p *Peer, id uint64, msg *types.PeerMsg) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	req, ok := s.pending[id]
+	if !ok || req.peer != p {
+		return
+	}
+	select {
+	case req.response <- msg:
+	default:
+	}
+}
+
+// Whether b is one of the blocks a snap synced or light chain only has the header of
+func (s *Syncer) bodyless(b *core.Block) bool {
+	if s.light != nil {
+		return b.Height() > 0
+	}
+	return s.state != nil && b.Height() > 0 && b.Height() <= s.state.SnapshotHeight()
+}
+
+func (s *Syncer) serveHeaders(p *Peer, req *types.GetBlockHeaders) error {
+	count := req.GetCount()
+	if count > maxHeadersPerMsg {
+		return fmt.Errorf("%d headers requested, at most %d are served", count, maxHeadersPerMsg)
+	}
+	res := &types.BlockHeaders{RequestID: req.GetRequestID()}
+	for h := req.GetFromHeight(); h < req.GetFromHeight()+uint64(count); h++ {
+		b, err := s.chain.GetBlockByHeight(h)
+		if err != nil {
+			break
+		}
+		header := b.ConvertToBlockPbMsg()
+		header.Transactions = nil
+		res.Headers = append(res.Headers, header)
+	}
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_Headers{Headers: res}})
+}
+
+func (s *Syncer) serveBodies(p *Peer, req *types.GetBlockBodies) error {
+	if len(req.GetHashes()) > maxBodiesPerMsg {
+		return fmt.Errorf("%d bodies requested, at most %d are served", len(req.GetHashes()), maxBodiesPerMsg)
+	}
+	res := &types.BlockBodies{RequestID: req.GetRequestID()}
+	for _, raw := range req.GetHashes() {
+		hash, ok := toHash(raw)
+		if !ok {
+			return errors.New("malformed block hash")
+		}
+		b, err := s.chain.GetBlockByHash(hash)
+		if err != nil || s.bodyless(b) {
+			break
+		}
+		res.Bodies = append(res.Bodies, &types.TransactionBatch{Batch: b.ConvertToBlockPbMsg().GetTransactions()})
+	}
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_Bodies{Bodies: res}})
+}
@@ -0,0 +1,299 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+)
+
+const (
+	//Transaction hashes remembered per peer
+	maxKnownTxs = 32768
+	//Cap on hashes per announce or request and transactions per batch
+	maxTxsPerMsg = 256
+	//A hash requested from one peer isn't requested again from another until this has passed
+	txRequestTimeout = 5 * time.Second
+	txEventBuffer    = 1024
+)
+
+// TxPool is the view of the MemPool gossip serves transactions from and rebuilds compact blocks with
+type TxPool interface {
+	PoolTransaction(id [32]byte) *core.Transaction
+	PoolTransactions() []*core.Transaction
+	//Whether a transaction is already in a canonical block, those are never requested from peers
+	TransactionIncluded(id [32]byte) bool
+}
+
+/*
+TxGossip relays MemPool transactions between peers.
+Transactions accepted into the pool are announced to peers by hash, peers request the hashes they don't have and get
+the full transactions back in a TransactionBatch which is handed to TransactionService.SendTransactions, the same
+path a client relaying transactions over the API takes. Each peer has a set of hashes it is known to have so a
+transaction is never announced or sent back to a peer that has already seen it.
+*/
+type TxGossip struct {
+	pool TxPool
+	txs  types.TransactionServiceServer
+
+	mux       sync.Mutex
+	peers     map[*Peer]*knownSet
+	requested map[[32]byte]time.Time
+	//Hashes whose announcement didn't fit in a peer's send queue, tried again with its next announcement
+	unsent map[*Peer][][32]byte
+
+	events *core.EventBus
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+func NewTxGossip(pool TxPool, txs types.TransactionServiceServer, events *core.EventBus) *TxGossip {
+	g := &TxGossip{
+		pool:      pool,
+		txs:       txs,
+		peers:     map[*Peer]*knownSet{},
+		requested: map[[32]byte]time.Time{},
+		unsent:    map[*Peer][][32]byte{},
+		events:    events,
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go g.announceLoop()
+	return g
+}
+
+// Stop announcing, peers are still served until the server stops
+func (g *TxGossip) Stop() {
+	//The Server stops its protocols, which may already have been stopped by hand
+	select {
+	case <-g.quit:
+		return
+	default:
+	}
+	close(g.quit)
+	<-g.done
+}
+
+// Capabilities implements Protocol
+func (g *TxGossip) Capabilities() []Capability {
+	return []Capability{TxCap}
+}
+
+// AddPeer implements Protocol
+func (g *TxGossip) AddPeer(p *Peer) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	g.peers[p] = newKnownSet(maxKnownTxs)
+}
+
+// RemovePeer implements Protocol
+func (g *TxGossip) RemovePeer(p *Peer) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	delete(g.peers, p)
+	delete(g.unsent, p)
+}
+
+// HandleMsg implements Protocol
+func (g *TxGossip) HandleMsg(p *Peer, msg *types.PeerMsg) error {
+	known := g.known(p)
+	if known == nil {
+		return nil
+	}
+	switch payload := msg.GetPayload().(type) {
+	case *types.PeerMsg_TxAnnounce:
+		return g.handleAnnounce(p, known, payload.TxAnnounce.GetHashes())
+	case *types.PeerMsg_TxRequest:
+		return g.handleRequest(p, known, payload.TxRequest.GetHashes())
+	case *types.PeerMsg_Transactions:
+		return g.handleTransactions(known, payload.Transactions)
+	}
+	return nil
+}
+
+// Helper methods
+func (g *TxGossip) known(p *Peer) *knownSet {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	return g.peers[p]
+}
+
+// Request every announced hash that isn't in the pool or the chain, or already on its way from another peer
+func (g *TxGossip) handleAnnounce(p *Peer, known *knownSet, raw [][]byte) error {
+	hashes, err := decodeHashes(raw)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var missing [][]byte
+	g.mux.Lock()
+	for _, h := range hashes {
+		known.Add(h)
+		if g.pool.PoolTransaction(h) != nil || g.pool.TransactionIncluded(h) {
+			continue
+		}
+		if at, ok := g.requested[h]; ok && now.Sub(at) < txRequestTimeout {
+			continue
+		}
+		g.requested[h] = now
+		missing = append(missing, h[:])
+	}
+	g.pruneRequested(now)
+	g.mux.Unlock()
+	if len(missing) == 0 {
+		return nil
+	}
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_TxRequest{TxRequest: &types.TxRequest{Hashes: missing}}})
+}
+
+// Serve whatever requested transactions are still in the pool, ones that have left it are silently skipped
+func (g *TxGossip) handleRequest(p *Peer, known *knownSet, raw [][]byte) error {
+	hashes, err := decodeHashes(raw)
+	if err != nil {
+		return err
+	}
+	batch := &types.TransactionBatch{}
+	for _, h := range hashes {
+		t := g.pool.PoolTransaction(h)
+		if t == nil {
+			continue
+		}
+		known.Add(h)
+		batch.Batch = append(batch.Batch, t.ConvertToTransactionPbMsg())
+	}
+	if len(batch.Batch) == 0 {
+		return nil
+	}
+	return p.Send(&types.PeerMsg{Payload: &types.PeerMsg_Transactions{Transactions: batch}})
+}
+
+func (g *TxGossip) handleTransactions(known *knownSet, batch *types.TransactionBatch) error {
+	if len(batch.GetBatch()) > maxTxsPerMsg {
+		return fmt.Errorf("transaction batch of %d exceeds %d", len(batch.GetBatch()), maxTxsPerMsg)
+	}
+	for _, msg := range batch.GetBatch() {
+		if err := core.ValidateTransactionMsg(msg); err != nil {
+			return err
+		}
+	}
+	g.mux.Lock()
+	for _, msg := range batch.GetBatch() {
+		//IDs are recomputed from the contents, the sender's claimed ID is never trusted
+		id := core.NewTransactionFromPbMsg(msg).ID
+		known.Add(id)
+		delete(g.requested, id)
+	}
+	g.mux.Unlock()
+	_, err := g.txs.SendTransactions(context.Background(), batch)
+	return err
+}
+
+// Forget requests that timed out once the map grows large, caller must hold g.mux
+func (g *TxGossip) pruneRequested(now time.Time) {
+	if len(g.requested) < maxKnownTxs {
+		return
+	}
+	for h, at := range g.requested {
+		if now.Sub(at) >= txRequestTimeout {
+			delete(g.requested, h)
+		}
+	}
+}
+
+// Announce transactions as they enter the pool, batching whatever has queued up since the last announcement
+func (g *TxGossip) announceLoop() {
+	defer close(g.done)
+	sub := g.events.Subscribe(txEventBuffer)
+	defer func() { sub.Unsubscribe() }()
+	for {
+		select {
+		case <-g.quit:
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				//Dropped for lagging, missed announcements are only a latency cost so pick up from here
+				sub = g.events.Subscribe(txEventBuffer)
+				continue
+			}
+			hashes := appendTxHash(nil, e)
+		drain:
+			for len(hashes) < maxTxsPerMsg {
+				select {
+				case e, ok := <-sub.Events():
+					if !ok {
+						break drain
+					}
+					hashes = appendTxHash(hashes, e)
+				default:
+					break drain
+				}
+			}
+			g.announce(hashes)
+		}
+	}
+}
+
+// Send each peer the hashes it isn't already known to have. They are only marked known once the announcement is
+// queued, a peer whose queue is full has them announced again along with the next hashes
+func (g *TxGossip) announce(hashes [][32]byte) {
+	if len(hashes) == 0 {
+		return
+	}
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	for p, known := range g.peers {
+		var unknown [][32]byte
+		for _, h := range append(g.unsent[p], hashes...) {
+			if !known.Has(h) {
+				unknown = append(unknown, h)
+			}
+		}
+		delete(g.unsent, p)
+		//What doesn't fit in one message waits for the next, a peer that can't keep up only misses announcements
+		//past that, which are a latency cost as for a lagging subscription
+		send := unknown[:min(len(unknown), maxTxsPerMsg)]
+		if len(send) == 0 {
+			continue
+		}
+		raw := make([][]byte, len(send))
+		for i := range send {
+			raw[i] = send[i][:]
+		}
+		msg := &types.PeerMsg{Payload: &types.PeerMsg_TxAnnounce{TxAnnounce: &types.TxAnnounce{Hashes: raw}}}
+		if err := p.Send(msg); err != nil {
+			g.unsent[p] = send
+			continue
+		}
+		for _, h := range send {
+			known.Add(h)
+		}
+		if rest := unknown[len(send):]; len(rest) > 0 {
+			g.unsent[p] = rest[:min(len(rest), maxTxsPerMsg)]
+		}
+	}
+}
+
+func appendTxHash(hashes [][32]byte, e core.Event) [][32]byte {
+	if e.Type != core.TxAddedEvent {
+		return hashes
+	}
+	return append(hashes, e.Transaction.ID)
+}
+
+func decodeHashes(raw [][]byte) ([][32]byte, error) {
+	if len(raw) > maxTxsPerMsg {
+		return nil, fmt.Errorf("%d hashes exceeds %d per message", len(raw), maxTxsPerMsg)
+	}
+	hashes := make([][32]byte, len(raw))
+	for i, b := range raw {
+		if len(b) != 32 {
+			return nil, errors.New("malformed transaction hash")
+		}
+		copy(hashes[i][:], b)
+	}
+	return hashes, nil
+}
@@ -0,0 +1,177 @@
+package structures
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"golang.org/x/crypto/sha3"
+)
+
+/*
+Merkle Mountain Range
+An append only accumulator: a list of perfect binary trees (mountains) of strictly decreasing size, one for each set
+bit of the number of leaves. Appending a leaf adds a mountain of one and merges equal mountains, so it costs O(log n)
+hashes amortized and never touches an existing node. The root commits to the number of leaves and the peak of every
+mountain, and a proof of a leaf is its path up to its mountain's peak plus the other peaks.
+
+Design considerations:
+-A leaf's index and the number of leaves fix which mountain it's in and how high every node on its path is, so leaves
+go in as they are and only interior nodes are hashed, with a prefix of their own
+-Interior nodes are stored by hash in a NodeStore like the other trees, so a proof can walk down from a peak. Appends
+only add nodes, ranges that share a prefix of leaves share its nodes
+*/
+
+const mountainPrefix = 5
+
+// A MountainProof shows a leaf is at Index of a MountainRange with Size leaves
+type MountainProof struct {
+	Index uint64
+	Size  uint64
+	//Siblings on the leaf's path up to its mountain's peak, from the bottom up
+	Siblings [][32]byte
+	//Peak of every mountain, biggest first
+	Peaks [][32]byte
+}
+
+type MountainRange struct {
+	store NodeStore
+	size  uint64
+	peaks [][32]byte
+}
+
+// An empty range keeping its nodes in store
+func NewMountainRange(store NodeStore) *MountainRange {
+	return &MountainRange{store: store}
+}
+
+// Number of leaves
+func (mr *MountainRange) Size() uint64 {
+	return mr.size
+}
+
+// Commitment to every leaf in order, zero for an empty range
+func (mr *MountainRange) Root() [32]byte {
+	if mr.size == 0 {
+		return [32]byte{}
+	}
+	return bagPeaks(mr.size, mr.peaks)
+}
+
+// A copy sharing the store, appends to either range don't show in the other
+func (mr *MountainRange) Copy() *MountainRange {
+	return &MountainRange{store: mr.store, size: mr.size, peaks: append([][32]byte{}, mr.peaks...)}
+}
+
+// Append a leaf, merging every mountain it completes
+func (mr *MountainRange) Append(leaf [32]byte) error {
+	node := leaf
+	//Each set low bit of size is a mountain the same height as the one being carried up
+	for size := mr.size; size&1 == 1; size >>= 1 {
+		var err error
+		node, err = mr.put(mr.peaks[len(mr.peaks)-1], node)
+		if err != nil {
+			return err
+		}
+		mr.peaks = mr.peaks[:len(mr.peaks)-1]
+	}
+	mr.peaks = append(mr.peaks, node)
+	mr.size++
+	return nil
+}
+
+// Proof of the leaf at index
+func (mr *MountainRange) ConstructProof(index uint64) (*MountainProof, error) {
+	if index >= mr.size {
+		return nil, fmt.Errorf("leaf %d is out of range of %d leaves", index, mr.size)
+	}
+	proof := &MountainProof{Index: index, Size: mr.size, Peaks: append([][32]byte{}, mr.peaks...)}
+	peak, height, offset := mountainOf(mr.size, index)
+	node := mr.peaks[peak]
+	for level := height - 1; level >= 0; level-- {
+		data, err := mr.store.Get(node)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) != 65 || data[0] != mountainPrefix {
+			return nil, fmt.Errorf("mountain node %x is corrupt", node[:4])
+		}
+		left, right := data[1:33], data[33:]
+		if offset>>level&1 == 1 {
+			left, right = right, left
+		}
+		copy(node[:], left)
+		var sibling [32]byte
+		copy(sibling[:], right)
+		proof.Siblings = append(proof.Siblings, sibling)
+	}
+	//Collected from the peak down
+	for i, j := 0, len(proof.Siblings)-1; i < j; i, j = i+1, j-1 {
+		proof.Siblings[i], proof.Siblings[j] = proof.Siblings[j], proof.Siblings[i]
+	}
+	return proof, nil
+}
+
+// VerifyMountainProof checks proof shows leaf is at proof.Index of the range with the given root
+func VerifyMountainProof(root [32]byte, leaf [32]byte, proof *MountainProof) bool {
+	if proof.Index >= proof.Size || len(proof.Peaks) != bits.OnesCount64(proof.Size) {
+		return false
+	}
+	peak, height, offset := mountainOf(proof.Size, proof.Index)
+	if len(proof.Siblings) != height {
+		return false
+	}
+	node := leaf
+	for level, sibling := range proof.Siblings {
+		if offset>>level&1 == 1 {
+			node = mountainHash(sibling, node)
+		} else {
+			node = mountainHash(node, sibling)
+		}
+	}
+	return node == proof.Peaks[peak] && bagPeaks(proof.Size, proof.Peaks) == root
+}
+
+// Helper Methods
+// Which mountain of a range of size leaves holds the leaf at index, that mountain's height and the leaf's offset in it
+func mountainOf(size, index uint64) (int, int, uint64) {
+	peak := 0
+	for height := 63; height >= 0; height-- {
+		width := uint64(1) << height
+		if size&width == 0 {
+			continue
+		}
+		if index < width {
+			return peak, height, index
+		}
+		index -= width
+		peak++
+	}
+	panic("leaf index is out of range")
+}
+
+// Store the parent of left and right and return its hash
+func (mr *MountainRange) put(left, right [32]byte) ([32]byte, error) {
+	data := mountainNode(left, right)
+	hash := sha3.Sum256(data)
+	return hash, mr.store.Put(hash, data)
+}
+
+func mountainNode(left, right [32]byte) []byte {
+	data := make([]byte, 0, 65)
+	data = append(data, mountainPrefix)
+	data = append(data, left[:]...)
+	return append(data, right[:]...)
+}
+
+func mountainHash(left, right [32]byte) [32]byte {
+	return sha3.Sum256(mountainNode(left, right))
+}
+
+func bagPeaks(size uint64, peaks [][32]byte) [32]byte {
+	data := binary.BigEndian.AppendUint64(nil, size)
+	for _, peak := range peaks {
+		data = append(data, peak[:]...)
+	}
+	return sha3.Sum256(data)
+}
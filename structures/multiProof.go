@@ -0,0 +1,126 @@
+package structures
+
+import (
+	"errors"
+	"sort"
+)
+
+/*
+Multiproofs prove a set of transactions against a TransactionTree root at once.
+Proofs of single transactions from the same tree repeat most of their hashes, since paths to nearby leaves share
+everything above where they meet, and a sibling on one path is often on another path already. A multiproof holds only
+the hashes the verifier can't work out from the leaves it has: walking up the tree a level at a time, a node's sibling
+is added if it isn't itself on the path of a proven leaf.
+*/
+
+// A MultiProof proves a set of leaves of a TransactionTree
+type MultiProof struct {
+	//Position of each proven leaf in the tree, in the order the leaves were given
+	Indices []int
+	//Number of leaves in the tree
+	LeafCount int
+	//Hashes the verifier can't work out itself, level by level from the leaves up and left to right within a level
+	Hashes [][32]byte
+}
+
+// Construct a proof of every one of transactionHashes from the last constructed tree
+func (tt *TransactionTree) ConstructMultiProof(transactionHashes [][32]byte) (*MultiProof, error) {
+	//Add and Delete reset the root, the tree levels are stale until it is recomputed
+	if tt.merkleRoot == ([32]byte{}) {
+		return nil, errors.New("merkle root was never computed, proof could not be constructed")
+	}
+	if len(transactionHashes) == 0 {
+		return nil, errors.New("multiproof needs at least one transaction")
+	}
+	proof := &MultiProof{LeafCount: len(tt.transactionHashes)}
+	for _, th := range transactionHashes {
+		ind, ok := tt.verifyMembership(th)
+		if !ok {
+			return nil, errors.New("transaction was not found in tree")
+		}
+		proof.Indices = append(proof.Indices, ind)
+	}
+	known, err := sortedIndices(proof.Indices)
+	if err != nil {
+		return nil, err
+	}
+	for _, level := range tt.levels() {
+		for k, ind := range known {
+			sibling := ind ^ 1
+			//Skip siblings the verifier has, either as a proven node or as the node itself on an odd level's end
+			if sibling >= len(level) || (k > 0 && known[k-1] == sibling) || (k+1 < len(known) && known[k+1] == sibling) {
+				continue
+			}
+			proof.Hashes = append(proof.Hashes, level[sibling])
+		}
+		known = parentIndices(known)
+	}
+	return proof, nil
+}
+
+// VerifyMultiProof checks every leaf is in the tree with the given root at its position in the proof, leaves are
+// given in the same order as the proof's indices. The tree's mode comes from whoever committed to root, never the proof
+func VerifyMultiProof(mode TreeMode, root [32]byte, leaves [][32]byte, proof *MultiProof) bool {
+	if len(leaves) == 0 || len(leaves) != len(proof.Indices) {
+		return false
+	}
+	known, err := sortedIndices(proof.Indices)
+	if err != nil || known[len(known)-1] >= proof.LeafCount {
+		return false
+	}
+	nodes := make(map[int][32]byte, len(leaves))
+	for i, ind := range proof.Indices {
+		nodes[ind] = mode.leaf(leaves[i])
+	}
+	hashes := proof.Hashes
+	for k, size := 0, proof.LeafCount; !mode.isRoot(k, size); k, size = k+1, (size+1)/2 {
+		parents := make(map[int][32]byte, len(known))
+		for _, ind := range known {
+			if _, done := parents[ind/2]; done {
+				continue
+			}
+			sibling, ok := nodes[ind^1]
+			if !ok && ind^1 < size {
+				if len(hashes) == 0 {
+					return false
+				}
+				sibling, hashes, ok = hashes[0], hashes[1:], true
+			}
+			switch {
+			case !ok:
+				//The last node of an odd level
+				parents[ind/2] = mode.parent([][32]byte{nodes[ind]}, 0)
+			case ind%2 == 1:
+				parents[ind/2] = mode.hashPair(sibling, nodes[ind])
+			default:
+				parents[ind/2] = mode.hashPair(nodes[ind], sibling)
+			}
+		}
+		nodes, known = parents, parentIndices(known)
+	}
+	return len(hashes) == 0 && nodes[0] == root
+}
+
+// Helper Methods
+// A sorted copy of indices, which must all be distinct and not negative
+func sortedIndices(indices []int) ([]int, error) {
+	sorted := append([]int{}, indices...)
+	sort.Ints(sorted)
+	for i, ind := range sorted {
+		if ind < 0 || (i > 0 && sorted[i-1] == ind) {
+			return nil, errors.New("leaf indices must be distinct and not negative")
+		}
+	}
+	return sorted, nil
+}
+
+// Indices of the parents of the sorted indices, sorted and without duplicates
+func parentIndices(indices []int) []int {
+	var parents []int
+	for _, ind := range indices {
+		if len(parents) == 0 || parents[len(parents)-1] != ind/2 {
+			parents = append(parents, ind/2)
+		}
+	}
+	return parents
+}
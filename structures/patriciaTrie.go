@@ -0,0 +1,512 @@
+package structures
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+)
+
+/*
+Merkle Patricia Trie
+A hexary trie over arbitrary byte keys, walked one nibble (half byte) of the key at a time, with three kinds of node:
+-Leaf: the rest of a key's path and its value
+-Extension: a run of path shared by every key below it, and the child the run leads to
+-Branch: a child for each of the 16 next nibbles, and the value of the key that ends at the branch if there is one
+Like the Ethereum trie its shape only depends on the keys in it, but nodes are encoded with the simple fixed layout
+below instead of RLP:
+-Leaf: 2, the path, the value
+-Extension: 3, the path, the child's hash
+-Branch: 4, a 16 bit big endian bitmap of the children present, their hashes in nibble order, the value
+Paths are a uvarint count of nibbles followed by the nibbles packed two to a byte, high nibble first, with an odd path
+padded with a zero nibble. Values are a uvarint length followed by the value. Children are always referenced by hash.
+The node kinds carry on from the SparseMerkleTree prefixes so nodes of both kinds of tree can share a NodeStore.
+
+Design considerations:
+-Nodes are stored by hash and never modified, like the SparseMerkleTree, so older roots stay readable and a trie is
+cheap to copy
+-Deletes restore the shape the trie would have had if the key was never inserted, so the root stays independent of the
+order keys were written in
+-Empty values aren't stored, a key either has a value or is absent
+*/
+
+const (
+	trieLeaf = iota + 2
+	trieExtension
+	trieBranch
+)
+
+var errKeyNotFound = errors.New("key was not found in the trie")
+
+type PatriciaTrie struct {
+	store NodeStore
+	root  [32]byte
+}
+
+// Open the trie with the given root in store, a zero root is the empty trie
+func NewPatriciaTrie(store NodeStore, root [32]byte) *PatriciaTrie {
+	return &PatriciaTrie{store: store, root: root}
+}
+
+// Returns the current root
+func (pt *PatriciaTrie) Root() [32]byte {
+	return pt.root
+}
+
+// A copy sharing the store, updates to either trie don't show in the other
+func (pt *PatriciaTrie) Copy() *PatriciaTrie {
+	return &PatriciaTrie{store: pt.store, root: pt.root}
+}
+
+// The value of key, nil if the key is absent
+func (pt *PatriciaTrie) Get(key []byte) ([]byte, error) {
+	path := toNibbles(key)
+	hash := pt.root
+	for hash != ([32]byte{}) {
+		n, err := pt.load(hash)
+		if err != nil {
+			return nil, err
+		}
+		var done bool
+		if hash, path, done = n.next(path); done {
+			return n.valueAt(path), nil
+		}
+	}
+	return nil, nil
+}
+
+// Set the value of key, replacing any value it had
+func (pt *PatriciaTrie) Insert(key []byte, value []byte) error {
+	if len(value) == 0 {
+		return errors.New("empty values can't be stored, delete the key instead")
+	}
+	root, err := pt.insert(pt.root, toNibbles(key), value)
+	if err != nil {
+		return err
+	}
+	pt.root = root
+	return nil
+}
+
+// Delete key from the trie
+func (pt *PatriciaTrie) Delete(key []byte) error {
+	root, err := pt.delete(pt.root, toNibbles(key))
+	if err != nil {
+		return err
+	}
+	pt.root = root
+	return nil
+}
+
+// Proof of the value of key, which proves the key is absent if it has no value
+// Holds the encoding of every node on the key's path from the root down
+func (pt *PatriciaTrie) ConstructProof(key []byte) ([][]byte, error) {
+	var proof [][]byte
+	path := toNibbles(key)
+	hash := pt.root
+	for hash != ([32]byte{}) {
+		data, err := pt.store.Get(hash)
+		if err != nil {
+			return nil, err
+		}
+		n, err := decodeTrieNode(data)
+		if err != nil {
+			return nil, fmt.Errorf("trie node %x is corrupt: %v", hash[:4], err)
+		}
+		proof = append(proof, data)
+		var done bool
+		if hash, path, done = n.next(path); done {
+			break
+		}
+	}
+	return proof, nil
+}
+
+// VerifyPatriciaProof checks proof against the trie root and returns the value of key it proves, nil if it proves the
+// key is absent
+func VerifyPatriciaProof(root [32]byte, key []byte, proof [][]byte) ([]byte, error) {
+	path := toNibbles(key)
+	hash := root
+	for i, data := range proof {
+		if hash == ([32]byte{}) || sha3.Sum256(data) != hash {
+			return nil, fmt.Errorf("proof node %d does not match its parent", i)
+		}
+		n, err := decodeTrieNode(data)
+		if err != nil {
+			return nil, err
+		}
+		var done bool
+		if hash, path, done = n.next(path); done {
+			if i != len(proof)-1 {
+				return nil, errors.New("proof has unused nodes")
+			}
+			return n.valueAt(path), nil
+		}
+	}
+	if hash != ([32]byte{}) {
+		return nil, errors.New("proof is too short")
+	}
+	return nil, nil
+}
+
+// Helper Methods
+type trieNode struct {
+	kind     byte
+	path     []byte //Nibbles, of a leaf or extension
+	value    []byte //Of a leaf or branch
+	child    [32]byte
+	children [16][32]byte
+}
+
+// Follow path through n, returns the next node and what is left of the path, or done if the walk ends at n
+func (n *trieNode) next(path []byte) ([32]byte, []byte, bool) {
+	switch n.kind {
+	case trieExtension:
+		if bytes.HasPrefix(path, n.path) {
+			return n.child, path[len(n.path):], false
+		}
+	case trieBranch:
+		if len(path) > 0 && n.children[path[0]] != ([32]byte{}) {
+			return n.children[path[0]], path[1:], false
+		}
+	}
+	return [32]byte{}, path, true
+}
+
+// Value of the key whose path ends at n with path left over, nil if there is none
+func (n *trieNode) valueAt(path []byte) []byte {
+	switch {
+	case n.kind == trieLeaf && bytes.Equal(n.path, path):
+		return n.value
+	case n.kind == trieBranch && len(path) == 0:
+		return n.value
+	}
+	return nil
+}
+
+// Write value at path into the subtrie with the given root, returns the subtrie's new root
+func (pt *PatriciaTrie) insert(hash [32]byte, path []byte, value []byte) ([32]byte, error) {
+	if hash == ([32]byte{}) {
+		return pt.put(&trieNode{kind: trieLeaf, path: path, value: value})
+	}
+	n, err := pt.load(hash)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	switch n.kind {
+	case trieLeaf:
+		if bytes.Equal(n.path, path) {
+			return pt.put(&trieNode{kind: trieLeaf, path: path, value: value})
+		}
+		//Split the leaf where the two paths part
+		common := commonNibbles(n.path, path)
+		branch := &trieNode{kind: trieBranch}
+		if err := pt.branchOut(branch, n.path[common:], n.value); err != nil {
+			return [32]byte{}, err
+		}
+		if err := pt.branchOut(branch, path[common:], value); err != nil {
+			return [32]byte{}, err
+		}
+		return pt.extend(path[:common], branch)
+	case trieExtension:
+		common := commonNibbles(n.path, path)
+		if common == len(n.path) {
+			child, err := pt.insert(n.child, path[common:], value)
+			if err != nil {
+				return [32]byte{}, err
+			}
+			return pt.put(&trieNode{kind: trieExtension, path: n.path, child: child})
+		}
+		//Split the extension where the two paths part
+		branch := &trieNode{kind: trieBranch}
+		if rest := n.path[common+1:]; len(rest) == 0 {
+			branch.children[n.path[common]] = n.child
+		} else if branch.children[n.path[common]], err = pt.put(&trieNode{kind: trieExtension, path: rest, child: n.child}); err != nil {
+			return [32]byte{}, err
+		}
+		if err := pt.branchOut(branch, path[common:], value); err != nil {
+			return [32]byte{}, err
+		}
+		return pt.extend(path[:common], branch)
+	default:
+		if len(path) == 0 {
+			n.value = value
+			return pt.put(n)
+		}
+		if n.children[path[0]], err = pt.insert(n.children[path[0]], path[1:], value); err != nil {
+			return [32]byte{}, err
+		}
+		return pt.put(n)
+	}
+}
+
+// Hang value off branch at path, a path that ends at the branch sets the branch's own value
+func (pt *PatriciaTrie) branchOut(branch *trieNode, path []byte, value []byte) error {
+	if len(path) == 0 {
+		branch.value = value
+		return nil
+	}
+	leaf, err := pt.put(&trieNode{kind: trieLeaf, path: path[1:], value: value})
+	branch.children[path[0]] = leaf
+	return err
+}
+
+// Store branch and return the root of it behind an extension of path, or the branch itself for an empty path
+func (pt *PatriciaTrie) extend(path []byte, branch *trieNode) ([32]byte, error) {
+	hash, err := pt.put(branch)
+	if err != nil || len(path) == 0 {
+		return hash, err
+	}
+	return pt.put(&trieNode{kind: trieExtension, path: path, child: hash})
+}
+
+// Remove the key at path from the subtrie with the given root, returns the subtrie's new root
+func (pt *PatriciaTrie) delete(hash [32]byte, path []byte) ([32]byte, error) {
+	if hash == ([32]byte{}) {
+		return [32]byte{}, errKeyNotFound
+	}
+	n, err := pt.load(hash)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	switch n.kind {
+	case trieLeaf:
+		if !bytes.Equal(n.path, path) {
+			return [32]byte{}, errKeyNotFound
+		}
+		return [32]byte{}, nil
+	case trieExtension:
+		if !bytes.HasPrefix(path, n.path) {
+			return [32]byte{}, errKeyNotFound
+		}
+		//A branch always keeps at least one entry, so the child never empties out
+		child, err := pt.delete(n.child, path[len(n.path):])
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return pt.prefix(n.path, child)
+	default:
+		if len(path) == 0 {
+			if n.value == nil {
+				return [32]byte{}, errKeyNotFound
+			}
+			n.value = nil
+		} else if n.children[path[0]], err = pt.delete(n.children[path[0]], path[1:]); err != nil {
+			return [32]byte{}, err
+		}
+		return pt.collapse(n)
+	}
+}
+
+// Store a branch that may have lost an entry, one left with a single entry is replaced by a leaf or extension
+func (pt *PatriciaTrie) collapse(branch *trieNode) ([32]byte, error) {
+	only, entries := -1, 0
+	for i, child := range branch.children {
+		if child != ([32]byte{}) {
+			only, entries = i, entries+1
+		}
+	}
+	if branch.value != nil {
+		entries++
+	}
+	switch {
+	case entries > 1:
+		return pt.put(branch)
+	case branch.value != nil:
+		return pt.put(&trieNode{kind: trieLeaf, path: []byte{}, value: branch.value})
+	}
+	return pt.prefix([]byte{byte(only)}, branch.children[only])
+}
+
+// Root of the subtrie reached by path then the node with the given hash, merging path into the node where it can
+func (pt *PatriciaTrie) prefix(path []byte, hash [32]byte) ([32]byte, error) {
+	n, err := pt.load(hash)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	switch n.kind {
+	case trieLeaf:
+		return pt.put(&trieNode{kind: trieLeaf, path: joinNibbles(path, n.path), value: n.value})
+	case trieExtension:
+		return pt.put(&trieNode{kind: trieExtension, path: joinNibbles(path, n.path), child: n.child})
+	default:
+		return pt.put(&trieNode{kind: trieExtension, path: path, child: hash})
+	}
+}
+
+func (pt *PatriciaTrie) load(hash [32]byte) (*trieNode, error) {
+	data, err := pt.store.Get(hash)
+	if err != nil {
+		return nil, err
+	}
+	n, err := decodeTrieNode(data)
+	if err != nil {
+		return nil, fmt.Errorf("trie node %x is corrupt: %v", hash[:4], err)
+	}
+	return n, nil
+}
+
+// Store a node and return its hash
+func (pt *PatriciaTrie) put(n *trieNode) ([32]byte, error) {
+	data := n.encode()
+	hash := sha3.Sum256(data)
+	return hash, pt.store.Put(hash, data)
+}
+
+func (n *trieNode) encode() []byte {
+	data := []byte{n.kind}
+	switch n.kind {
+	case trieLeaf:
+		data = appendNibbles(data, n.path)
+		data = appendTrieValue(data, n.value)
+	case trieExtension:
+		data = appendNibbles(data, n.path)
+		data = append(data, n.child[:]...)
+	default:
+		var bitmap uint16
+		for i, child := range n.children {
+			if child != ([32]byte{}) {
+				bitmap |= 1 << (15 - i)
+			}
+		}
+		data = binary.BigEndian.AppendUint16(data, bitmap)
+		for i := range n.children {
+			if n.children[i] != ([32]byte{}) {
+				data = append(data, n.children[i][:]...)
+			}
+		}
+		data = appendTrieValue(data, n.value)
+	}
+	return data
+}
+
+// Decode a node, rejecting any encoding but the one encode would produce
+func decodeTrieNode(data []byte) (*trieNode, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty node")
+	}
+	n := &trieNode{kind: data[0]}
+	rest := data[1:]
+	var err error
+	switch n.kind {
+	case trieLeaf:
+		if n.path, rest, err = readNibbles(rest); err != nil {
+			return nil, err
+		}
+		if n.value, rest, err = readTrieValue(rest); err != nil {
+			return nil, err
+		}
+		if n.value == nil {
+			return nil, errors.New("leaf has no value")
+		}
+	case trieExtension:
+		if n.path, rest, err = readNibbles(rest); err != nil {
+			return nil, err
+		}
+		if len(n.path) == 0 || len(rest) != 32 {
+			return nil, errors.New("malformed extension")
+		}
+		copy(n.child[:], rest)
+		rest = nil
+	case trieBranch:
+		if len(rest) < 2 {
+			return nil, errors.New("truncated branch")
+		}
+		bitmap := binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+		for i := range n.children {
+			if bitmap&(1<<(15-i)) == 0 {
+				continue
+			}
+			if len(rest) < 32 {
+				return nil, errors.New("truncated branch")
+			}
+			copy(n.children[i][:], rest)
+			if n.children[i] == ([32]byte{}) {
+				return nil, errors.New("branch child has an empty hash")
+			}
+			rest = rest[32:]
+		}
+		if n.value, rest, err = readTrieValue(rest); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown node kind %d", n.kind)
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing bytes")
+	}
+	return n, nil
+}
+
+func appendNibbles(data []byte, nibbles []byte) []byte {
+	data = binary.AppendUvarint(data, uint64(len(nibbles)))
+	for i := 0; i < len(nibbles); i += 2 {
+		b := nibbles[i] << 4
+		if i+1 < len(nibbles) {
+			b |= nibbles[i+1]
+		}
+		data = append(data, b)
+	}
+	return data
+}
+
+func readNibbles(data []byte) ([]byte, []byte, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > uint64(2*len(data)) {
+		return nil, nil, errors.New("malformed path")
+	}
+	data = data[n:]
+	packed := int(count+1) / 2
+	if len(data) < packed {
+		return nil, nil, errors.New("truncated path")
+	}
+	if count%2 == 1 && data[packed-1]&0x0f != 0 {
+		return nil, nil, errors.New("path padding is not zero")
+	}
+	nibbles := toNibbles(data[:packed])[:count]
+	return nibbles, data[packed:], nil
+}
+
+// A uvarint length and the value, an absent value is written as an empty one
+func appendTrieValue(data []byte, value []byte) []byte {
+	data = binary.AppendUvarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+func readTrieValue(data []byte) ([]byte, []byte, error) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || length > uint64(len(data)-n) {
+		return nil, nil, errors.New("malformed value")
+	}
+	data = data[n:]
+	if length == 0 {
+		return nil, data, nil
+	}
+	return data[:length], data[length:], nil
+}
+
+func toNibbles(key []byte) []byte {
+	nibbles := make([]byte, 0, 2*len(key))
+	for _, b := range key {
+		nibbles = append(nibbles, b>>4, b&0x0f)
+	}
+	return nibbles
+}
+
+// Number of leading nibbles a and b share
+func commonNibbles(a, b []byte) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// A fresh path of a followed by b
+func joinNibbles(a, b []byte) []byte {
+	path := make([]byte, 0, len(a)+len(b))
+	return append(append(path, a...), b...)
+}
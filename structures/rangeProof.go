@@ -0,0 +1,259 @@
+package structures
+
+import (
+	"errors"
+)
+
+/*
+Range proofs show that a run of leaves is every leaf of a SparseMerkleTree between two keys.
+A run covers the keys from its origin up to its last leaf, or up to the end of the key space for the last run of a
+tree. The proof holds the subtrees just outside those bounds, in the order a left to right walk of the tree meets them,
+so a verifier holding the leaves of the run can rebuild the root on its own. Every subtree within the bounds is
+rebuilt from the leaves, so a verified range can't have had leaves left out of it.
+Rebuilding the root needs to know which of the subtrees outside the range hold a single leaf, as a lone leaf takes the
+place of its parent, so each one is sent as empty bytes if it is empty, as the leaf's key and value if it is a single
+leaf, or as its hash otherwise.
+*/
+
+// A SparseRange is a run of consecutive leaves of a SparseMerkleTree in key order
+type SparseRange struct {
+	Origin [32]byte
+	Keys   [][32]byte
+	Values [][32]byte
+	//The range runs to the end of the key space, there are no leaves after it
+	Last  bool
+	Proof [][]byte
+}
+
+// Up to max leaves from origin in key order with their range proof
+func (smt *SparseMerkleTree) ConstructRangeProof(origin [32]byte, max int) (*SparseRange, error) {
+	if max <= 0 {
+		return nil, errors.New("range must hold at least one leaf")
+	}
+	r := &SparseRange{Origin: origin}
+	if err := smt.collect(smt.root, 0, [32]byte{}, r, max); err != nil {
+		return nil, err
+	}
+	r.Last = len(r.Keys) < max
+	if err := smt.rangeProof(smt.root, 0, [32]byte{}, origin, lastKey(r), &r.Proof); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Rebuild the root of the tree the range was taken from out of its leaves and proof
+func (r *SparseRange) Root() ([32]byte, error) {
+	if len(r.Keys) != len(r.Values) {
+		return [32]byte{}, errors.New("range has a different number of keys and values")
+	}
+	if len(r.Keys) == 0 && !r.Last {
+		return [32]byte{}, errors.New("range is empty")
+	}
+	for i, key := range r.Keys {
+		if compareKeys(key, r.Origin) < 0 {
+			return [32]byte{}, errors.New("range holds a key before its origin")
+		}
+		if i > 0 && compareKeys(r.Keys[i-1], key) >= 0 {
+			return [32]byte{}, errors.New("range keys are not in order")
+		}
+		if r.Values[i] == ([32]byte{}) {
+			return [32]byte{}, errors.New("range holds an empty leaf")
+		}
+	}
+	proof := r.Proof
+	root, err := rangeRoot(0, [32]byte{}, r.Origin, lastKey(r), r.Keys, r.Values, &proof)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if len(proof) != 0 {
+		return [32]byte{}, errors.New("range proof has unused nodes")
+	}
+	return root.hash, nil
+}
+
+// Helper Methods
+// A rebuilt subtree, leaf is set if it holds a single leaf
+type subtree struct {
+	hash [32]byte
+	leaf bool
+}
+
+// Parent of two subtrees, a lone leaf moves up to take the parent's place
+func joinSubtrees(left, right subtree) subtree {
+	if left.hash == ([32]byte{}) && (right.hash == ([32]byte{}) || right.leaf) {
+		return right
+	}
+	if right.hash == ([32]byte{}) && left.leaf {
+		return left
+	}
+	return subtree{hash: interiorHash(left.hash, right.hash)}
+}
+
+// Walk the subtree under hash at depth, whose keys start at lo, appending its leaves from r.Origin until r holds max
+func (smt *SparseMerkleTree) collect(hash [32]byte, depth int, lo [32]byte, r *SparseRange, max int) error {
+	if hash == ([32]byte{}) || len(r.Keys) == max {
+		return nil
+	}
+	if hi := upperKey(lo, depth); compareKeys(hi, r.Origin) < 0 {
+		return nil
+	}
+	n, err := smt.load(hash)
+	if err != nil {
+		return err
+	}
+	if n.leaf {
+		if compareKeys(n.left, r.Origin) >= 0 {
+			r.Keys, r.Values = append(r.Keys, n.left), append(r.Values, n.right)
+		}
+		return nil
+	}
+	if err := smt.collect(n.left, depth+1, lo, r, max); err != nil {
+		return err
+	}
+	return smt.collect(n.right, depth+1, setBit(lo, depth), r, max)
+}
+
+// Append the subtrees under hash at depth that lie just outside of origin and limit
+func (smt *SparseMerkleTree) rangeProof(hash [32]byte, depth int, lo, origin, limit [32]byte, proof *[][]byte) error {
+	position := position(lo, depth, origin, limit)
+	if position == insideRange {
+		return nil
+	}
+	var n *node
+	if hash != ([32]byte{}) {
+		var err error
+		if n, err = smt.load(hash); err != nil {
+			return err
+		}
+	}
+	if position == outsideRange {
+		switch {
+		case n == nil:
+			*proof = append(*proof, nil)
+		case n.leaf:
+			*proof = append(*proof, append(n.left[:], n.right[:]...))
+		default:
+			*proof = append(*proof, hash[:])
+		}
+		return nil
+	}
+	//A lone leaf stands in for the subtree it heads, walk on as if it sat further down its path
+	var left, right [32]byte
+	switch {
+	case n == nil:
+	case n.leaf && bit(n.left, depth) == 1:
+		right = hash
+	case n.leaf:
+		left = hash
+	default:
+		left, right = n.left, n.right
+	}
+	if err := smt.rangeProof(left, depth+1, lo, origin, limit, proof); err != nil {
+		return err
+	}
+	return smt.rangeProof(right, depth+1, setBit(lo, depth), origin, limit, proof)
+}
+
+// Rebuild the subtree at depth whose keys start at lo from the leaves in it and the proof
+func rangeRoot(depth int, lo, origin, limit [32]byte, keys, values [][32]byte, proof *[][]byte) (subtree, error) {
+	switch position(lo, depth, origin, limit) {
+	case outsideRange:
+		if len(*proof) == 0 {
+			return subtree{}, errors.New("range proof is too short")
+		}
+		raw := (*proof)[0]
+		*proof = (*proof)[1:]
+		var s subtree
+		switch len(raw) {
+		case 0:
+		case 32:
+			copy(s.hash[:], raw)
+		case 64:
+			var key, value [32]byte
+			copy(key[:], raw[:32])
+			copy(value[:], raw[32:])
+			if commonPrefix(key, lo) < depth {
+				return subtree{}, errors.New("range proof leaf is off its path")
+			}
+			s = subtree{hash: leafHash(key, value), leaf: true}
+		default:
+			return subtree{}, errors.New("malformed range proof node")
+		}
+		return s, nil
+	case insideRange:
+		return subtreeRoot(depth, keys, values), nil
+	}
+	split := splitKeys(keys, depth)
+	left, err := rangeRoot(depth+1, lo, origin, limit, keys[:split], values[:split], proof)
+	if err != nil {
+		return subtree{}, err
+	}
+	right, err := rangeRoot(depth+1, setBit(lo, depth), origin, limit, keys[split:], values[split:], proof)
+	if err != nil {
+		return subtree{}, err
+	}
+	return joinSubtrees(left, right), nil
+}
+
+// The subtree at depth holding exactly the sorted keys
+func subtreeRoot(depth int, keys, values [][32]byte) subtree {
+	switch len(keys) {
+	case 0:
+		return subtree{}
+	case 1:
+		return subtree{hash: leafHash(keys[0], values[0]), leaf: true}
+	}
+	split := splitKeys(keys, depth)
+	return joinSubtrees(subtreeRoot(depth+1, keys[:split], values[:split]), subtreeRoot(depth+1, keys[split:], values[split:]))
+}
+
+// Index of the first of the sorted keys under the right child at depth
+func splitKeys(keys [][32]byte, depth int) int {
+	split := 0
+	for split < len(keys) && bit(keys[split], depth) == 0 {
+		split++
+	}
+	return split
+}
+
+const (
+	straddlesRange = iota
+	insideRange
+	outsideRange
+)
+
+// Where the subtree at depth whose keys start at lo sits relative to origin and limit
+func position(lo [32]byte, depth int, origin, limit [32]byte) int {
+	hi := upperKey(lo, depth)
+	if compareKeys(hi, origin) < 0 || compareKeys(lo, limit) > 0 {
+		return outsideRange
+	}
+	if compareKeys(lo, origin) >= 0 && compareKeys(hi, limit) <= 0 {
+		return insideRange
+	}
+	return straddlesRange
+}
+
+// The key a range ends at
+func lastKey(r *SparseRange) [32]byte {
+	if r.Last || len(r.Keys) == 0 {
+		return upperKey([32]byte{}, 0)
+	}
+	return r.Keys[len(r.Keys)-1]
+}
+
+// The last key of the subtree at depth whose keys start at lo
+func upperKey(lo [32]byte, depth int) [32]byte {
+	if depth < 256 {
+		lo[depth/8] |= 0xff >> (depth % 8)
+		for i := depth/8 + 1; i < len(lo); i++ {
+			lo[i] = 0xff
+		}
+	}
+	return lo
+}
+
+func setBit(key [32]byte, depth int) [32]byte {
+	key[depth/8] |= 1 << (7 - depth%8)
+	return key
+}
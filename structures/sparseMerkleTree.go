@@ -0,0 +1,416 @@
+package structures
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+/*
+Sparse Merkle Tree
+A binary tree over every possible 256-bit key, almost all of them empty. A key's path from the root is the bits of the
+key, most significant first, so the tree's shape, and with it the root, only depends on which keys hold which values
+and never on the order they were written in. Every key has a proof: of its value if it has one, or that it is absent.
+
+Design considerations:
+-A subtree holding a single leaf is that leaf, and an empty subtree hashes to zero, so a key's path is only as deep as
+it takes to tell it apart from its neighbours, about log2 of the number of keys, instead of 256 levels
+-Since a leaf can sit at any depth, leaves and interior nodes are hashed with different prefixes so one can't be passed
+off as the other
+-Nodes are stored by hash and never modified, an update writes a new path up to a new root. Every root written stays
+readable for as long as the store keeps its nodes, so a tree is cheap to copy and older versions can still be read
+-A zero value means the key is absent, writing one deletes the key
+*/
+
+const (
+	leafPrefix     = 0
+	interiorPrefix = 1
+)
+
+// Value Add stores, the merkleTree interface treats the tree as a set of keys
+var memberValue = [32]byte{31: 1}
+
+var errNodeNotFound = errors.New("tree node not found")
+
+// Compile time check that SparseMerkleTree can stand in for the generic tree
+var _ merkleTree = (*SparseMerkleTree)(nil)
+
+// A NodeStore holds tree nodes by hash, the nodes of SparseMerkleTrees, PatriciaTries and MountainRanges can share one
+type NodeStore interface {
+	//The node with the given hash, an error if it isn't stored
+	Get(hash [32]byte) ([]byte, error)
+	Put(hash [32]byte, node []byte) error
+}
+
+// MemNodeStore keeps nodes in memory
+type MemNodeStore struct {
+	mux   sync.RWMutex
+	nodes map[[32]byte][]byte
+}
+
+func NewMemNodeStore() *MemNodeStore {
+	return &MemNodeStore{nodes: map[[32]byte][]byte{}}
+}
+
+func (ms *MemNodeStore) Get(hash [32]byte) ([]byte, error) {
+	ms.mux.RLock()
+	defer ms.mux.RUnlock()
+	node, ok := ms.nodes[hash]
+	if !ok {
+		return nil, errNodeNotFound
+	}
+	return node, nil
+}
+
+func (ms *MemNodeStore) Put(hash [32]byte, node []byte) error {
+	ms.mux.Lock()
+	defer ms.mux.Unlock()
+	ms.nodes[hash] = node
+	return nil
+}
+
+// A SparseProof shows the value of a key in a SparseMerkleTree, or that the key is absent
+type SparseProof struct {
+	//Hashes of the siblings of the nodes on the key's path, from the bottom of the path up to the root
+	Siblings [][32]byte
+	//For an absent key whose path ends at another leaf, that leaf's key and value
+	LeafKey   [32]byte
+	LeafValue [32]byte
+}
+
+type SparseMerkleTree struct {
+	store NodeStore
+	root  [32]byte
+}
+
+// Open the tree with the given root in store, a zero root is the empty tree
+func NewSparseMerkleTree(store NodeStore, root [32]byte) *SparseMerkleTree {
+	return &SparseMerkleTree{store: store, root: root}
+}
+
+// Returns the current root
+func (smt *SparseMerkleTree) Root() [32]byte {
+	return smt.root
+}
+
+// A copy sharing the store, updates to either tree don't show in the other
+func (smt *SparseMerkleTree) Copy() *SparseMerkleTree {
+	return &SparseMerkleTree{store: smt.store, root: smt.root}
+}
+
+// The value of key, zero if the key is absent
+func (smt *SparseMerkleTree) Get(key [32]byte) ([32]byte, error) {
+	hash := smt.root
+	for depth := 0; hash != ([32]byte{}); depth++ {
+		n, err := smt.load(hash)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		if n.leaf {
+			if n.left != key {
+				return [32]byte{}, nil
+			}
+			return n.right, nil
+		}
+		hash = n.child(key, depth)
+	}
+	return [32]byte{}, nil
+}
+
+// Set the value of key and recompute the root, a zero value deletes the key
+func (smt *SparseMerkleTree) Update(key [32]byte, value [32]byte) error {
+	root, err := smt.update(smt.root, 0, key, value)
+	if err != nil {
+		return err
+	}
+	smt.root = root
+	return nil
+}
+
+// Proof of the value of key, which proves the key is absent if it has no value
+func (smt *SparseMerkleTree) ConstructProof(key [32]byte) (*SparseProof, error) {
+	proof := &SparseProof{}
+	hash := smt.root
+	for depth := 0; hash != ([32]byte{}); depth++ {
+		n, err := smt.load(hash)
+		if err != nil {
+			return nil, err
+		}
+		if n.leaf {
+			if n.left != key {
+				proof.LeafKey, proof.LeafValue = n.left, n.right
+			}
+			break
+		}
+		hash = n.child(key, depth)
+		sibling := n.right
+		if bit(key, depth) == 1 {
+			sibling = n.left
+		}
+		proof.Siblings = append(proof.Siblings, sibling)
+	}
+	//Collected from the root down
+	for i, j := 0, len(proof.Siblings)-1; i < j; i, j = i+1, j-1 {
+		proof.Siblings[i], proof.Siblings[j] = proof.Siblings[j], proof.Siblings[i]
+	}
+	return proof, nil
+}
+
+// Add key to the tree as a member of a set
+func (smt *SparseMerkleTree) Add(key [32]byte) error {
+	value, err := smt.Get(key)
+	if err != nil {
+		return err
+	}
+	if value != ([32]byte{}) {
+		return errors.New("key is already in the tree")
+	}
+	return smt.Update(key, memberValue)
+}
+
+// Delete key from the tree
+func (smt *SparseMerkleTree) Delete(key [32]byte) error {
+	value, err := smt.Get(key)
+	if err != nil {
+		return err
+	}
+	if value == ([32]byte{}) {
+		return errors.New("key was not found in the tree")
+	}
+	return smt.Update(key, [32]byte{})
+}
+
+// The root is recomputed on every update so there is nothing to construct
+func (smt *SparseMerkleTree) Construct() error {
+	return nil
+}
+
+// Verify the proof siblings that key was added to the tree
+func (smt *SparseMerkleTree) VerifyProof(key [32]byte, siblings [][32]byte) bool {
+	return VerifySparseProof(smt.root, key, memberValue, &SparseProof{Siblings: siblings})
+}
+
+// Reset to the empty tree, the nodes stay in the store
+func (smt *SparseMerkleTree) ResetTree() {
+	smt.root = [32]byte{}
+}
+
+// MarkNodes adds the hash of every node of the tree to marked. Subtrees whose root is already marked are skipped, so
+// marking several versions of a tree only visits the nodes they share once
+func (smt *SparseMerkleTree) MarkNodes(marked map[[32]byte]bool) error {
+	return smt.mark(smt.root, marked)
+}
+
+// VerifySparseProof checks proof shows key has value in the tree with the given root, a zero value checks key is absent
+func VerifySparseProof(root [32]byte, key [32]byte, value [32]byte, proof *SparseProof) bool {
+	depth := len(proof.Siblings)
+	if depth > 256 {
+		return false
+	}
+	var hash [32]byte
+	switch {
+	case value != ([32]byte{}):
+		if proof.LeafValue != ([32]byte{}) {
+			return false
+		}
+		hash = leafHash(key, value)
+	case proof.LeafValue != ([32]byte{}):
+		//The other leaf has to sit on the key's path
+		if proof.LeafKey == key || commonPrefix(proof.LeafKey, key) < depth {
+			return false
+		}
+		hash = leafHash(proof.LeafKey, proof.LeafValue)
+	}
+	//A leaf's sibling is never empty, or the two would have been one leaf further up
+	if hash != ([32]byte{}) && depth > 0 && proof.Siblings[0] == ([32]byte{}) {
+		return false
+	}
+	for i, sibling := range proof.Siblings {
+		if bit(key, depth-1-i) == 1 {
+			hash = interiorHash(sibling, hash)
+		} else {
+			hash = interiorHash(hash, sibling)
+		}
+	}
+	return hash == root
+}
+
+// Helper Methods
+// A stored node, an interior node's children or a leaf's key and value
+type node struct {
+	leaf        bool
+	left, right [32]byte
+}
+
+// The child on key's path of an interior node at depth
+func (n *node) child(key [32]byte, depth int) [32]byte {
+	if bit(key, depth) == 1 {
+		return n.right
+	}
+	return n.left
+}
+
+// Write value at key into the subtree at depth with the given root, returns the subtree's new root
+func (smt *SparseMerkleTree) update(hash [32]byte, depth int, key, value [32]byte) ([32]byte, error) {
+	if hash == ([32]byte{}) {
+		if value == ([32]byte{}) {
+			return [32]byte{}, nil
+		}
+		return smt.put(&node{leaf: true, left: key, right: value})
+	}
+	n, err := smt.load(hash)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if n.leaf {
+		if n.left == key {
+			return smt.update([32]byte{}, depth, key, value)
+		}
+		if value == ([32]byte{}) {
+			return hash, nil
+		}
+		//Two leaves share the subtree, push both down until their paths part
+		added, err := smt.put(&node{leaf: true, left: key, right: value})
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return smt.join(depth, n.left, hash, key, added)
+	}
+	left, right := n.left, n.right
+	if bit(key, depth) == 1 {
+		right, err = smt.update(right, depth+1, key, value)
+	} else {
+		left, err = smt.update(left, depth+1, key, value)
+	}
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return smt.interior(left, right)
+}
+
+// Root of the subtree at depth holding just the leaves a and b
+func (smt *SparseMerkleTree) join(depth int, keyA, a, keyB, b [32]byte) ([32]byte, error) {
+	bitA, bitB := bit(keyA, depth), bit(keyB, depth)
+	if bitA != bitB {
+		if bitA == 1 {
+			a, b = b, a
+		}
+		return smt.put(&node{left: a, right: b})
+	}
+	child, err := smt.join(depth+1, keyA, a, keyB, b)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if bitA == 1 {
+		return smt.put(&node{left: [32]byte{}, right: child})
+	}
+	return smt.put(&node{left: child, right: [32]byte{}})
+}
+
+// Root of a subtree with the given children, a lone leaf moves up to take the subtree's place
+func (smt *SparseMerkleTree) interior(left, right [32]byte) ([32]byte, error) {
+	if left == ([32]byte{}) || right == ([32]byte{}) {
+		only := left
+		if only == ([32]byte{}) {
+			only = right
+		}
+		if only == ([32]byte{}) {
+			return only, nil
+		}
+		n, err := smt.load(only)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		if n.leaf {
+			return only, nil
+		}
+	}
+	return smt.put(&node{left: left, right: right})
+}
+
+func (smt *SparseMerkleTree) mark(hash [32]byte, marked map[[32]byte]bool) error {
+	if hash == ([32]byte{}) || marked[hash] {
+		return nil
+	}
+	n, err := smt.load(hash)
+	if err != nil {
+		return err
+	}
+	marked[hash] = true
+	if n.leaf {
+		return nil
+	}
+	if err := smt.mark(n.left, marked); err != nil {
+		return err
+	}
+	return smt.mark(n.right, marked)
+}
+
+func (smt *SparseMerkleTree) load(hash [32]byte) (*node, error) {
+	data, err := smt.store.Get(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 65 || data[0] > interiorPrefix {
+		return nil, fmt.Errorf("tree node %x is corrupt", hash[:4])
+	}
+	n := &node{leaf: data[0] == leafPrefix}
+	copy(n.left[:], data[1:33])
+	copy(n.right[:], data[33:])
+	return n, nil
+}
+
+// Store a node and return its hash
+func (smt *SparseMerkleTree) put(n *node) ([32]byte, error) {
+	data := n.encode()
+	hash := sha3.Sum256(data)
+	return hash, smt.store.Put(hash, data)
+}
+
+func (n *node) encode() []byte {
+	data := make([]byte, 0, 65)
+	if n.leaf {
+		data = append(data, leafPrefix)
+	} else {
+		data = append(data, interiorPrefix)
+	}
+	data = append(data, n.left[:]...)
+	return append(data, n.right[:]...)
+}
+
+func leafHash(key, value [32]byte) [32]byte {
+	return sha3.Sum256((&node{leaf: true, left: key, right: value}).encode())
+}
+
+func interiorHash(left, right [32]byte) [32]byte {
+	return sha3.Sum256((&node{left: left, right: right}).encode())
+}
+
+// Bit of key at depth, most significant first
+func bit(key [32]byte, depth int) byte {
+	return key[depth/8] >> (7 - depth%8) & 1
+}
+
+// Number of leading bits a and b share
+func commonPrefix(a, b [32]byte) int {
+	if a == b {
+		return 256
+	}
+	i := 0
+	for a[i] == b[i] {
+		i++
+	}
+	n := i * 8
+	for bit(a, n) == bit(b, n) {
+		n++
+	}
+	return n
+}
+
+// Keys compare in the order of their leaves in the tree
+func compareKeys(a, b [32]byte) int {
+	return bytes.Compare(a[:], b[:])
+}
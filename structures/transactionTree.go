@@ -3,7 +3,6 @@ package structures
 import (
 	"errors"
 	"golang.org/x/crypto/sha3"
-	"math"
 )
 
 /*
@@ -13,19 +12,46 @@ MPT Benefits include:
 -Bounded Length: Prevents DoS attacks by disallowing attacks to generate too much tree depth
 -Much Faster recalculation in the event of a value change
 -Root calculation should be contingent on the data, not on the update order (not the case in Merkle Trees)
+Account state, which needs those, is kept in a SparseMerkleTree instead
 
 
 Design considerations:
 -Perserve the root so that you don't have to recalculate everytime, will be set to nil on unsafe Add/Delete
--Keep array for transactionHashes for ease of update and delete O(N) preserves order as well
--Keep Tree so we can construct MerkleProofs
+-Keep array for transactionHashes as the leaves, and a map from hash to index so membership checks are O(1)
+-Delete and SafeDelete keep the order of the rest, so leaf i is always transaction i and the root only depends on which
+transactions are in the tree, O(N) to shift the later leaves down
+-Keep every level of the Tree so we can construct MerkleProofs, and so SafeAdd/SafeDelete only rehash the nodes above
+the leaves that changed instead of the whole tree, O(log N) for SafeAdd and for a SafeDelete near the end
 -Block constructions will happen synchronously (per elected node) so no need for synchronization primitives
+-How nodes are hashed is set by the TreeMode, a root has to be checked with the mode it was built with
 */
 
+// A TreeMode is a way of hashing a TransactionTree, a tree's root is only meaningful under its mode
+type TreeMode uint8
+
+const (
+	//Leaves are the transaction hashes themselves and interior nodes hash the concatenation of their children, the last
+	//node of an odd level is paired with itself. An interior node can be passed off as a leaf, and a tree whose odd
+	//level ends in a duplicated node has the same root as the tree without the duplicate (CVE-2012-2459)
+	LegacyTree TreeMode = iota
+	//Leaves and interior nodes are hashed with different prefixes, and the last node of an odd level moves up a
+	//level unchanged instead of being paired with a copy of itself
+	SeparatedTree
+)
+
 type TransactionTree struct {
+	mode              TreeMode
 	merkleRoot        [32]byte
 	transactionHashes [][32]byte
-	tree              [][32]byte
+	//Index of each transaction in transactionHashes
+	indices map[[32]byte]int
+	//Every level of nodes from the leaves up, the last one holds only the root
+	tree [][][32]byte
+}
+
+// A tree hashed under mode, the zero TransactionTree is a LegacyTree
+func NewTransactionTree(mode TreeMode) *TransactionTree {
+	return &TransactionTree{mode: mode}
 }
 
 // Construct MerkleTree
@@ -33,17 +59,20 @@ func (tt *TransactionTree) Construct() error {
 	if len(tt.transactionHashes) == 0 {
 		return errors.New("tree contains no transactions")
 	}
-	//Ensure that we have an an even amount of leaves
-	if len(tt.transactionHashes)%2 != 0 {
-		tt.transactionHashes = append(tt.transactionHashes, tt.transactionHashes[len(tt.transactionHashes)-1])
-	}
-	//Need to generate a copy to ensure that there is no data loss modifying currentLevel
-	//We will use a make function to ensure that we are not overallocating capacity
 	currentLevel := make([][32]byte, len(tt.transactionHashes))
-	for len(currentLevel) > 1 {
-		//Need to copy every level of tree, to reconstruct proof later
-		tt.tree = append(tt.tree, currentLevel...)
-		hashPairs(&currentLevel)
+	for i, th := range tt.transactionHashes {
+		currentLevel[i] = tt.mode.leaf(th)
+	}
+	//Drop any previously constructed levels
+	tt.tree = append(tt.tree[:0], currentLevel)
+	for k := 0; !tt.mode.isRoot(k, len(currentLevel)); k++ {
+		parents := make([][32]byte, (len(currentLevel)+1)/2)
+		for i := 0; i < len(currentLevel); i += 2 {
+			parents[i/2] = tt.mode.parent(currentLevel, i)
+		}
+		//Need to keep every level of tree, to reconstruct proof later
+		tt.tree = append(tt.tree, parents)
+		currentLevel = parents
 	}
 	tt.merkleRoot = currentLevel[0]
 	return nil
@@ -57,20 +86,21 @@ func (tt *TransactionTree) ConstructProof(transactionHash [32]byte) (*[][32]byte
 	if !ok {
 		return nil, errors.New("transaction was not found in tree")
 	}
-	//If the Merkle Root was computed, this implies that transactionHashes reflects an even number of transactions and is therefore up to date
-	if len(tt.tree) == 0 {
+	//Add and Delete reset the root, the tree levels are stale until it is recomputed
+	if tt.merkleRoot == ([32]byte{}) {
 		return nil, errors.New("merkle root was never computed, proof could not be constructed")
 	}
-	proof := emptyProofArray(len(tt.transactionHashes))
-	//we can use the length of 'proof' here cause the make function populates the array with default values
-	totalSize := len(tt.transactionHashes)
-	for i := 0; i < len(proof); i++ {
-		//set path
-		proof[i] = tt.tree[ind^1]
-		//Calculate Parent Index
-		ind = tt.calculateParentInd(ind, totalSize)
-		//Set new totalsize for parent index calculation
-		totalSize += totalSize / 2
+	//One sibling per level below the root, a level's last odd node has none in a SeparatedTree
+	var proof [][32]byte
+	for _, level := range tt.levels() {
+		sibling := ind ^ 1
+		switch {
+		case sibling < len(level):
+			proof = append(proof, level[sibling])
+		case tt.mode == LegacyTree:
+			proof = append(proof, level[ind])
+		}
+		ind /= 2
 	}
 	return &proof, nil
 }
@@ -83,22 +113,31 @@ func (tt *TransactionTree) Add(transactionHash [32]byte) error {
 	if ok {
 		return errors.New("transaction is already in the tree")
 	}
+	if tt.indices == nil {
+		tt.indices = map[[32]byte]int{}
+	}
 	//Add element
+	tt.indices[transactionHash] = len(tt.transactionHashes)
 	tt.transactionHashes = append(tt.transactionHashes, transactionHash)
 	//Reset the Merkle root
 	tt.merkleRoot = [32]byte{}
 	return nil
 }
 
-// Delete a transaction from the transactionHashes (does not recalculate MerkleRoot)
+// Delete a transaction from the transactionHashes, preserving the order of the rest (does not recalculate MerkleRoot).
+// O(N) as every later transaction moves down a place and is reindexed
 func (tt *TransactionTree) Delete(transactionHash [32]byte) error {
 	//ensure membership before attempting delete
 	ind, ok := tt.verifyMembership(transactionHash)
 	if !ok {
 		return errors.New("transaction was not found in the tree")
 	}
-	//Remove the element
+	//Remove the element, every later one moves down a place
 	tt.transactionHashes = append(tt.transactionHashes[:ind], tt.transactionHashes[ind+1:]...)
+	delete(tt.indices, transactionHash)
+	for i := ind; i < len(tt.transactionHashes); i++ {
+		tt.indices[tt.transactionHashes[i]] = i
+	}
 	//Reset the Merkle root
 	tt.merkleRoot = [32]byte{}
 	return nil
@@ -107,95 +146,176 @@ func (tt *TransactionTree) Delete(transactionHash [32]byte) error {
 // Safe Methods for Add/Del (recalculates MerkleRoot)
 // Calls Add and Recalculates MerkleRoot
 func (tt *TransactionTree) SafeAdd(transactionHash [32]byte) error {
+	//The levels can only be patched if they were up to date before the add
+	current := tt.merkleRoot != ([32]byte{})
 	ok := tt.Add(transactionHash)
 	if ok != nil {
 		return ok
 	}
-	//Recalculate the MerkleRoot
-	err := tt.Construct()
-	if err != nil {
-		return err
+	if !current {
+		return tt.Construct()
 	}
+	//Only the path of the new last leaf changes
+	tt.updateFrom(len(tt.transactionHashes) - 1)
 	return nil
 }
 
+// Calls Delete and Recalculates MerkleRoot. Only a delete near the end is O(log N), every leaf after the deleted one
+// moves and is rehashed along with the nodes above it, so deleting the first of N leaves costs about as much as Construct
 func (tt *TransactionTree) SafeDelete(transactionHash [32]byte) error {
+	current := tt.merkleRoot != ([32]byte{})
+	ind, _ := tt.verifyMembership(transactionHash)
 	//Attempt to delete the transaction
 	err := tt.Delete(transactionHash)
 	if err != nil {
 		return err
 	}
-	//Recalculate the MerkleRoot
-	err = tt.Construct()
-	if err != nil {
-		return err
+	if !current || len(tt.transactionHashes) == 0 {
+		return tt.Construct()
 	}
+	//Every leaf after the deleted one moved down a place, the nodes left of them are unchanged
+	tt.updateFrom(ind)
 	return nil
 }
 
+// Check a proof from ConstructProof that th is in this tree. It takes th's position from the tree itself, so it only
+// checks leaves the tree already holds, VerifyTreeProof checks a proof against nothing but the root
 func (tt *TransactionTree) VerifyProof(th [32]byte, proof [][32]byte) (bool, error) {
-	if len(tt.merkleRoot) == 0 {
+	if tt.merkleRoot == ([32]byte{}) {
 		return false, errors.New("merkle root has not been computed yet")
 	}
-	for _, hash := range proof {
-		th = sha3.Sum256(append(th[:], hash[:]...))
+	ind, ok := tt.verifyMembership(th)
+	if !ok {
+		return false, nil
+	}
+	return VerifyTreeProof(tt.mode, tt.merkleRoot, th, ind, len(tt.transactionHashes), proof), nil
+}
+
+// VerifyTreeProof checks a proof from ConstructProof that th is leaf ind of the leafCount leaves of the tree with the
+// given root. As with VerifyMultiProof the mode comes from whoever committed to root
+func VerifyTreeProof(mode TreeMode, root [32]byte, th [32]byte, ind int, leafCount int, proof [][32]byte) bool {
+	if ind < 0 || ind >= leafCount {
+		return false
+	}
+	//The leaf's position says which side of each pair it is on
+	node := mode.leaf(th)
+	for k, size := 0, leafCount; !mode.isRoot(k, size); k, size = k+1, (size+1)/2 {
+		//The last node of an odd level moves up on its own
+		if ind^1 >= size && mode == SeparatedTree {
+			ind /= 2
+			continue
+		}
+		if len(proof) == 0 {
+			return false
+		}
+		if ind%2 == 1 {
+			node = mode.hashPair(proof[0], node)
+		} else {
+			node = mode.hashPair(node, proof[0])
+		}
+		proof, ind = proof[1:], ind/2
 	}
-	return (th == tt.merkleRoot), nil
+	return len(proof) == 0 && node == root
+}
+
+// Returns the last computed MerkleRoot
+func (tt *TransactionTree) Root() [32]byte {
+	return tt.merkleRoot
+}
+
+func (tt *TransactionTree) Mode() TreeMode {
+	return tt.mode
 }
 
 func (tt *TransactionTree) ResetTree() {
 	tt.merkleRoot = [32]byte{}
 	tt.transactionHashes = [][32]byte{}
-	tt.tree = [][32]byte{}
+	tt.indices = nil
+	tt.tree = [][][32]byte{}
 }
 
 // Db Methods
 // Helper Methods
 // Check if an element is in the transactionHashes array
 func (tt *TransactionTree) verifyMembership(transactionHash [32]byte) (int, bool) {
-	for i, hash := range tt.transactionHashes {
-		if hash == transactionHash {
-			return i, true
+	ind, ok := tt.indices[transactionHash]
+	if !ok {
+		return -1, false
+	}
+	return ind, true
+}
+
+// Rehash every leaf from ind on and the nodes above them up to the root, resizing the levels to fit the leaf count.
+// Only the nodes from ind>>k on change at level k, so the ones left of them are kept
+func (tt *TransactionTree) updateFrom(ind int) {
+	if len(tt.tree) == 0 {
+		tt.tree = append(tt.tree, nil)
+	}
+	tt.tree[0] = resizeLevel(tt.tree[0], len(tt.transactionHashes))
+	for i := ind; i < len(tt.transactionHashes); i++ {
+		tt.tree[0][i] = tt.mode.leaf(tt.transactionHashes[i])
+	}
+	k := 0
+	for ; !tt.mode.isRoot(k, len(tt.tree[k])); k++ {
+		if k+1 == len(tt.tree) {
+			tt.tree = append(tt.tree, nil)
+		}
+		level := tt.tree[k]
+		parents := resizeLevel(tt.tree[k+1], (len(level)+1)/2)
+		for i := ind &^ 1; i < len(level); i += 2 {
+			parents[i/2] = tt.mode.parent(level, i)
 		}
+		tt.tree[k+1] = parents
+		ind /= 2
 	}
-	return -1, false
+	//Levels above the root are left over from a bigger tree
+	tt.tree = tt.tree[:k+1]
+	tt.merkleRoot = tt.tree[k][0]
 }
 
-// Constructs an empty proof array with (num of levels) - 1 capacity omitting rootLevel
-func emptyProofArray(length int) [][32]byte {
-	return make([][32]byte, int(math.Sqrt(float64(length))))
+// Levels of the last constructed tree from the leaves up, without the root
+func (tt *TransactionTree) levels() [][][32]byte {
+	return tt.tree[:len(tt.tree)-1]
+}
+
+// A level grown or shrunk to size, a tree changes by at most one leaf at a time so a level grows by at most one node
+func resizeLevel(level [][32]byte, size int) [][32]byte {
+	if len(level) < size {
+		return append(level, [32]byte{})
+	}
+	return level[:size]
 }
 
-// calculates the parent index for a pair of hashes
-func (tt *TransactionTree) calculateParentInd(ind int, totalSize int) int {
-	siblingInd := ind ^ 1
-	origInd := ind
-	//Offset if we're not on level 0
-	if totalSize != len(tt.transactionHashes) {
-		siblingInd -= totalSize
-		origInd -= totalSize
+// The node a transaction hash is at the bottom of the tree
+func (m TreeMode) leaf(th [32]byte) [32]byte {
+	if m == LegacyTree {
+		return th
 	}
-	offset := int(math.Min(float64(origInd), float64(siblingInd)))/2 + 1
-	return totalSize - 1 + offset
+	return sha3.Sum256(append([]byte{leafPrefix}, th[:]...))
 }
 
-// Hash Pairs on the same level
-func hashPairs(children *[][32]byte) {
-	//Unwrap array
-	currLevel := *children
-	//Ensure that there are an even number of leaves
-	if len(currLevel)%2 != 0 {
-		currLevel = append(currLevel, currLevel[len(currLevel)-1])
+// The parent of the node at ind in level
+func (m TreeMode) parent(level [][32]byte, ind int) [32]byte {
+	left := level[ind&^1]
+	if ind|1 < len(level) {
+		return m.hashPair(left, level[ind|1])
 	}
-	//parentHashes array will always only be half the size of the currLevel
-	parentHashes := make([][32]byte, len(currLevel)/2)
-	for i := 0; i < len(currLevel); i += 2 {
-		//concatenate the pair
-		pair := append(currLevel[i][:], currLevel[i+1][:]...)
-		//hash and add to parentHashes
-		//append potentially resizes capacity so put it in the index
-		parentHashes[i/2] = sha3.Sum256(pair)
+	if m == LegacyTree {
+		return m.hashPair(left, left)
+	}
+	return left
+}
+
+// Whether a level of size nodes, k levels up from the leaves, is the root. A LegacyTree hashes a lone leaf with itself
+func (m TreeMode) isRoot(k int, size int) bool {
+	return size == 1 && (k > 0 || m != LegacyTree)
+}
+
+func (m TreeMode) hashPair(left, right [32]byte) [32]byte {
+	//concatenate the pair
+	pair := append(left[:], right[:]...)
+	if m != LegacyTree {
+		pair = append([]byte{interiorPrefix}, pair...)
 	}
-	//Cannot just reassign "children" to a new pointer value, modify underlying slice to pass value back
-	*children = parentHashes
+	return sha3.Sum256(pair)
 }
@@ -7,6 +7,14 @@ import (
 )
 
 // you can have init methods to setup tests, and consts prior to running tests
-func TestChainInit(t testing.T) {
+func TestChainInit(t *testing.T) {
+	genesis := core.NewBlock((&core.Block{}).Hash(), 0, make([]*core.Transaction, 0, 1000))
+	genesis.Finalize(core.NewState(nil))
+	require.Equal(t, genesis.Hash(), genesis.ID)
 
+	//Every node must derive the same genesis
+	a, b := core.NewGenesisBlock(), core.NewGenesisBlock()
+	a.Finalize(core.NewState(nil))
+	b.Finalize(core.NewState(nil))
+	require.Equal(t, a.ID, b.ID)
 }
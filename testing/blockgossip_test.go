@@ -0,0 +1,228 @@
+package core_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/p2p"
+	"github.com/stretchr/testify/require"
+)
+
+// devChain is a linear chain that validates imported blocks the way the node does, side chains are ignored
+type devChain struct {
+	*p2pChain
+	mux    sync.Mutex
+	blocks map[[32]byte]*core.Block
+	canon  []*core.Block
+	state  *core.State
+	pool   *core.MemPool
+	events *core.EventBus
+}
+
+func newDevChain() *devChain {
+	c := newP2PChain(1)
+	events := core.NewEventBus()
+	return &devChain{
+		p2pChain: c,
+		blocks:   map[[32]byte]*core.Block{c.genesis.ID: c.genesis},
+		canon:    []*core.Block{c.genesis},
+		state:    core.NewState(nil),
+		pool:     core.NewMemPool(nil, events),
+		events:   events,
+	}
+}
+
+func (dc *devChain) LastBlock() *core.Block {
+	dc.mux.Lock()
+	defer dc.mux.Unlock()
+	return dc.canon[len(dc.canon)-1]
+}
+
+// Every block has difficulty 1 so a linear chain's total difficulty is its length
+func (dc *devChain) TotalDifficulty() uint64 {
+	dc.mux.Lock()
+	defer dc.mux.Unlock()
+	return uint64(len(dc.canon))
+}
+
+func (dc *devChain) GetBlockByHeight(height uint64) (*core.Block, error) {
+	dc.mux.Lock()
+	defer dc.mux.Unlock()
+	if height >= uint64(len(dc.canon)) {
+		return nil, core.ErrNotFound
+	}
+	return dc.canon[height], nil
+}
+
+func (dc *devChain) GetBlockByHash(hash [32]byte) (*core.Block, error) {
+	dc.mux.Lock()
+	defer dc.mux.Unlock()
+	if b, ok := dc.blocks[hash]; ok {
+		return b, nil
+	}
+	return nil, core.ErrNotFound
+}
+
+func (dc *devChain) InsertBlock(b *core.Block) error {
+	dc.mux.Lock()
+	defer dc.mux.Unlock()
+	if _, ok := dc.blocks[b.ID]; ok {
+		return core.ErrKnownBlock
+	}
+	parent, ok := dc.blocks[b.ParentHash()]
+	if !ok {
+		return core.ErrUnknownParent
+	}
+	if err := b.VerifyHeader(parent); err != nil {
+		return err
+	}
+	if parent != dc.canon[len(dc.canon)-1] {
+		dc.blocks[b.ID] = b
+		return nil
+	}
+	state := dc.state.Copy()
+	if _, err := b.VerifyBody(state); err != nil {
+		return err
+	}
+	dc.blocks[b.ID], dc.state = b, state
+	dc.canon = append(dc.canon, b)
+	dc.pool.RemoveIncluded(b.Transactions())
+	dc.events.Publish(core.Event{Type: core.NewHeadEvent, Block: b})
+	return nil
+}
+
+func (dc *devChain) PoolTransaction(id [32]byte) *core.Transaction {
+	t, _ := dc.pool.GetTransaction(id)
+	return t
+}
+
+func (dc *devChain) PoolTransactions() []*core.Transaction { return dc.pool.Pending() }
+
+func (dc *devChain) TransactionIncluded(id [32]byte) bool {
+	dc.mux.Lock()
+	defer dc.mux.Unlock()
+	for _, b := range dc.canon {
+		for _, t := range b.Transactions() {
+			if t.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Seal a block on the head the way a producer would
+func (dc *devChain) produce(trans []*core.Transaction) *core.Block {
+	dc.mux.Lock()
+	defer dc.mux.Unlock()
+	head := dc.canon[len(dc.canon)-1]
+	b := core.NewBlock(head.ID, head.Height()+1, trans)
+	b.Finalize(dc.state)
+	dc.blocks[b.ID] = b
+	dc.canon = append(dc.canon, b)
+	dc.pool.RemoveIncluded(trans)
+	dc.events.Publish(core.Event{Type: core.NewHeadEvent, Block: b})
+	return b
+}
+
+func startBlockNode(t *testing.T) (*p2p.Server, *devChain) {
+	dc := newDevChain()
+	return startNode(t, dc, p2p.NewBlockGossip(dc, dc, dc.events)), dc
+}
+
+func TestBlockPropagation(t *testing.T) {
+	//A line of nodes, blocks only reach the far end by being validated and relayed along the way
+	nodes := make([]*p2p.Server, 5)
+	chains := make([]*devChain, 5)
+	for i := range nodes {
+		nodes[i], chains[i] = startBlockNode(t)
+		if i > 0 {
+			_, err := nodes[i].Dial(nodes[i-1].Addr())
+			require.Nil(t, err)
+		}
+	}
+	converged := func() bool {
+		head := chains[0].LastBlock().ID
+		for _, dc := range chains {
+			if dc.LastBlock().ID != head {
+				return false
+			}
+		}
+		return true
+	}
+	var alice [core.AddressLength]byte
+	alice[0] = 1
+	for i := 0; i < 3; i++ {
+		tx := core.NewTransaction(0, 0, core.TxGas, alice, [core.AddressLength]byte{2})
+		chains[0].produce([]*core.Transaction{tx})
+	}
+	waitFor(t, converged)
+	require.Equal(t, uint64(3), chains[4].LastBlock().Height())
+
+	//A node joining late fetches the head from its peer and then every missing ancestor
+	late, lateChain := startBlockNode(t)
+	_, err := late.Dial(nodes[2].Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return lateChain.LastBlock().ID == chains[0].LastBlock().ID })
+
+	//Blocks keep flowing from anywhere in the network
+	chains[4].produce(nil)
+	waitFor(t, converged)
+	waitFor(t, func() bool { return lateChain.LastBlock().Height() == 4 })
+}
+
+func TestBlockVerify(t *testing.T) {
+	var alice [core.AddressLength]byte
+	alice[0] = 1
+	alloc := map[[core.AddressLength]byte]uint64{alice: 10}
+	genesis := core.NewGenesisBlock()
+	genesis.Finalize(core.NewState(alloc))
+
+	b := core.NewBlock(genesis.ID, 1, []*core.Transaction{
+		core.NewTransaction(0, 4, core.TxGas, alice, [core.AddressLength]byte{2}),
+	})
+	b.Finalize(core.NewState(alloc))
+	require.Nil(t, b.VerifyHeader(genesis))
+	_, err := b.VerifyBody(core.NewState(alloc))
+	require.Nil(t, err)
+
+	//Executed against a state where alice can't pay the receipts no longer match
+	_, err = b.VerifyBody(core.NewState(nil))
+	require.NotNil(t, err)
+
+	//A header claiming the wrong height is rejected
+	msg := b.ConvertToBlockPbMsg()
+	msg.Height = 2
+	require.NotNil(t, core.NewBlockFromPbMsg(msg).VerifyHeader(genesis))
+}
+
+func TestCompactBlockRelay(t *testing.T) {
+	a, ca := startBlockNode(t)
+	b, cb := startBlockNode(t)
+	_, err := b.Dial(a.Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return a.PeerCount() == 1 })
+
+	//Both mempools hold the first transactions, the last is only known to the producer
+	var trans []*core.Transaction
+	for i := 0; i < 10; i++ {
+		tx := core.NewTransaction(0, 0, core.TxGas, [core.AddressLength]byte{byte(i)}, [core.AddressLength]byte{2})
+		trans = append(trans, tx)
+		if i < 8 {
+			require.Nil(t, ca.pool.AddTransactionToPool(tx))
+			//Each pool needs its own copy, the MemPool tracks heap positions on the transaction itself
+			require.Nil(t, cb.pool.AddTransactionToPool(core.NewTransactionFromPbMsg(tx.ConvertToTransactionPbMsg())))
+		}
+	}
+	//Rebuilt entirely from b's mempool
+	ca.produce(trans[:4])
+	waitFor(t, func() bool { return cb.LastBlock().ID == ca.LastBlock().ID })
+	require.Equal(t, 4, cb.pool.Size())
+
+	//Two transactions have to be fetched from a
+	head := ca.produce(trans[4:])
+	waitFor(t, func() bool { return cb.LastBlock().ID == head.ID })
+	require.Equal(t, head.ConvertToBlockPbMsg(), cb.LastBlock().ConvertToBlockPbMsg())
+	require.Equal(t, 0, cb.pool.Size())
+}
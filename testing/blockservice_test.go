@@ -0,0 +1,121 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/services"
+	"github.com/liangalv/goChain/core/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rangeChain is a fakeChain with n empty blocks on top of genesis
+type rangeChain struct {
+	*fakeChain
+	blocks []*core.Block
+}
+
+func newRangeChain(n int) *rangeChain {
+	rc := &rangeChain{fakeChain: newFakeChain(nil)}
+	rc.blocks = []*core.Block{rc.genesis}
+	for h := 1; h <= n; h++ {
+		b := core.NewBlock(rc.blocks[h-1].ID, uint64(h), nil)
+		b.Finalize(core.NewState(nil))
+		rc.blocks = append(rc.blocks, b)
+	}
+	return rc
+}
+
+func (rc *rangeChain) GetBlockByHeight(height uint64) (*core.Block, error) {
+	if height >= uint64(len(rc.blocks)) {
+		return nil, core.ErrNotFound
+	}
+	return rc.blocks[height], nil
+}
+func (rc *rangeChain) LastBlock() *core.Block { return rc.blocks[len(rc.blocks)-1] }
+func (rc *rangeChain) TotalDifficulty() uint64 {
+	var td uint64
+	for _, b := range rc.blocks {
+		td += b.Difficulty()
+	}
+	return td
+}
+
+// rangeStream collects what GetBlockRange sends, cancelling its context after cancelAfter blocks if set
+type rangeStream struct {
+	grpc.ServerStream
+	ctx         context.Context
+	cancel      context.CancelFunc
+	cancelAfter int
+	sent        []*types.BlockMsg
+}
+
+func newRangeStream(cancelAfter int) *rangeStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &rangeStream{ctx: ctx, cancel: cancel, cancelAfter: cancelAfter}
+}
+
+func (rs *rangeStream) Context() context.Context { return rs.ctx }
+func (rs *rangeStream) Send(msg *types.BlockMsg) error {
+	rs.sent = append(rs.sent, msg)
+	if len(rs.sent) == rs.cancelAfter {
+		rs.cancel()
+	}
+	return nil
+}
+
+func TestGetBlockRange(t *testing.T) {
+	rc := newRangeChain(services.MaxBlockRange + 10)
+	bs := services.NewBlockService(rc)
+	head := uint64(len(rc.blocks) - 1)
+
+	//Blocks are streamed in height order, both ends included
+	stream := newRangeStream(0)
+	require.Nil(t, bs.GetBlockRange(&types.GetBlockRangeRequest{From: 3, To: 7}, stream))
+	require.Len(t, stream.sent, 5)
+	for i, msg := range stream.sent {
+		require.Equal(t, uint64(3+i), msg.GetHeight())
+		require.Equal(t, rc.blocks[3+i].ID, core.NewBlockFromPbMsg(msg).ID)
+	}
+
+	invalid := []struct {
+		from, to uint64
+		code     codes.Code
+	}{
+		{5, 4, codes.InvalidArgument},
+		{0, head + 1, codes.OutOfRange},
+		{0, services.MaxBlockRange, codes.InvalidArgument},
+	}
+	for _, c := range invalid {
+		stream := newRangeStream(0)
+		err := bs.GetBlockRange(&types.GetBlockRangeRequest{From: c.from, To: c.to}, stream)
+		require.Equal(t, c.code, status.Code(err))
+		require.Empty(t, stream.sent)
+	}
+	//The longest range allowed
+	stream = newRangeStream(0)
+	require.Nil(t, bs.GetBlockRange(&types.GetBlockRangeRequest{From: 1, To: services.MaxBlockRange}, stream))
+	require.Len(t, stream.sent, services.MaxBlockRange)
+
+	//A client going away stops the stream
+	stream = newRangeStream(4)
+	err := bs.GetBlockRange(&types.GetBlockRangeRequest{From: 0, To: 100}, stream)
+	require.Equal(t, codes.Canceled, status.Code(err))
+	require.Len(t, stream.sent, 4)
+}
+
+func TestGetChainInfo(t *testing.T) {
+	rc := newRangeChain(5)
+	res, err := services.NewBlockService(rc).GetChainInfo(context.Background(), &types.GetChainInfoRequest{})
+	require.Nil(t, err)
+	require.Equal(t, types.Status_SUCCESS, res.GetStatus())
+	require.Equal(t, uint64(5), res.GetHeight())
+	require.Equal(t, rc.blocks[5].ID[:], res.GetHeadHash())
+	require.Equal(t, rc.ChainID(), res.GetChainID())
+	require.Equal(t, rc.TotalDifficulty(), res.GetTotalDifficulty())
+	require.NotZero(t, res.GetTotalDifficulty())
+}
@@ -0,0 +1,134 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockBloom(t *testing.T) {
+	var alice, bob, carol [core.AddressLength]byte
+	alice[0], bob[0], carol[0] = 1, 2, 3
+	alloc := map[[core.AddressLength]byte]uint64{alice: 100}
+	genesis := core.NewGenesisBlock()
+	genesis.Finalize(core.NewState(nil))
+	b := core.NewBlock(genesis.ID, 1, []*core.Transaction{core.NewTransaction(0, 10, core.TxGas, alice, bob)})
+	b.Finalize(core.NewState(alloc))
+
+	//Both ends of every transfer and the topics of its log go in the bloom
+	require.True(t, b.HasBloom())
+	bloom := b.Bloom()
+	var bobTopic [32]byte
+	copy(bobTopic[12:], bob[:])
+	for _, item := range [][]byte{alice[:], bob[:], core.TransferTopic[:], bobTopic[:]} {
+		require.True(t, bloom.Test(item))
+	}
+	require.False(t, bloom.Test(carol[:]))
+
+	//The bloom is part of the header, and has to match what the block's transactions produce
+	msg := b.ConvertToBlockPbMsg()
+	require.Equal(t, b.ID, core.NewBlockFromPbMsg(msg).ID)
+	_, err := core.NewBlockFromPbMsg(msg).VerifyBody(core.NewState(alloc))
+	require.Nil(t, err)
+	msg.Bloom[0] ^= 0xff
+	tampered := core.NewBlockFromPbMsg(msg)
+	require.NotEqual(t, b.ID, tampered.ID)
+	_, err = tampered.VerifyBody(core.NewState(alloc))
+	require.NotNil(t, err)
+
+	//Blocks from before blooms carry none, and may contain anything
+	require.False(t, genesis.HasBloom())
+	require.Nil(t, genesis.ConvertToBlockPbMsg().GetBloom())
+}
+
+// Commit a chain of n blocks on top of genesis where block h sends from alice to bob whenever h%every is 0
+func commitBloomChain(t *testing.T, store *core.BlockStore, n, every int) ([]*core.Block, []*core.Transaction) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	state := core.NewState(map[[core.AddressLength]byte]uint64{alice: 1 << 40})
+	genesis := core.NewGenesisBlock()
+	require.Nil(t, store.CommitBlock(genesis, genesis.Finalize(state)))
+	chain := []*core.Block{genesis}
+	var sent []*core.Transaction
+	for h := 1; h <= n; h++ {
+		var trans []*core.Transaction
+		if h%every == 0 {
+			trans = append(trans, core.NewTransaction(h, 1, core.TxGas+uint32(h), alice, bob))
+		}
+		sent = append(sent, trans...)
+		b := core.NewBlock(chain[h-1].ID, uint64(h), trans)
+		require.Nil(t, store.CommitBlock(b, b.Finalize(state)))
+		chain = append(chain, b)
+	}
+	return chain, sent
+}
+
+func TestBloomSearch(t *testing.T) {
+	var alice, bob, carol [core.AddressLength]byte
+	alice[0], bob[0], carol[0] = 1, 2, 3
+	store := core.NewMemBlockStore()
+	defer store.Close()
+	n := 2*core.BloomSectionSize + 100
+	chain, sent := commitBloomChain(t, store, n, 1000)
+	var sentIDs [][32]byte
+	for _, s := range sent {
+		sentIDs = append(sentIDs, s.ID)
+	}
+	var bobTopic [32]byte
+	copy(bobTopic[12:], bob[:])
+	byBob := &core.LogFilter{To: uint64(n), Topics: [][][32]byte{{core.TransferTopic}, nil, {bobTopic}}}
+	search := func() {
+		txIDs, err := store.FindTransactions(bob, 0, uint64(n), 100)
+		require.Nil(t, err)
+		require.Equal(t, sentIDs, txIDs)
+		txIDs, err = store.FindTransactions(carol, 0, uint64(n), 100)
+		require.Nil(t, err)
+		require.Empty(t, txIDs)
+		//Ranges that start and end inside a section
+		txIDs, err = store.FindTransactions(alice, 2500, 5000, 100)
+		require.Nil(t, err)
+		require.Equal(t, sentIDs[2:5], txIDs)
+
+		logs, err := store.GetLogs(byBob, 100)
+		require.Nil(t, err)
+		require.Len(t, logs, len(sent))
+		for i, l := range logs {
+			require.Equal(t, sent[i].ID, l.TxID)
+			require.Equal(t, uint64(1000*(i+1)), l.Height)
+			require.Equal(t, chain[l.Height].ID, l.BlockHash)
+		}
+		logs, err = store.GetLogs(&core.LogFilter{To: uint64(n), Addresses: [][core.AddressLength]byte{carol}}, 100)
+		require.Nil(t, err)
+		require.Empty(t, logs)
+		_, err = store.GetLogs(byBob, 3)
+		require.Equal(t, core.ErrTooManyResults, err)
+	}
+
+	//Searched header by header, then by the bloom bits of the sections once they are indexed
+	search()
+	require.NotNil(t, store.WriteBloomSection(1, chain[core.BloomSectionSize:2*core.BloomSectionSize]))
+	require.Nil(t, store.WriteBloomSection(0, chain[:core.BloomSectionSize]))
+	require.Nil(t, store.WriteBloomSection(1, chain[core.BloomSectionSize:2*core.BloomSectionSize]))
+	sections, err := store.ReadBloomSections()
+	require.Nil(t, err)
+	require.Equal(t, uint64(2), sections)
+	search()
+
+	//A reorg reaching into an indexed section rewinds the index to below it
+	fork := core.BloomSectionSize + 10
+	side := core.NewBlock(chain[fork-1].ID, uint64(fork), nil)
+	side.Finalize(core.NewState(nil))
+	require.Nil(t, store.WriteBlock(side, nil))
+	var removed []*core.Block
+	for h := n; h >= fork; h-- {
+		removed = append(removed, chain[h])
+	}
+	require.Nil(t, store.Reorg(removed, []*core.Block{side}))
+	sections, err = store.ReadBloomSections()
+	require.Nil(t, err)
+	require.Equal(t, uint64(1), sections)
+	txIDs, err := store.FindTransactions(bob, 0, uint64(fork), 100)
+	require.Nil(t, err)
+	require.Equal(t, sentIDs[:4], txIDs)
+}
@@ -0,0 +1,60 @@
+package core_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainFile(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	state := core.NewState(map[[core.AddressLength]byte]uint64{alice: 100})
+	genesis := core.NewGenesisBlock()
+	genesis.Finalize(state)
+	chain := []*core.Block{genesis}
+	for h := 1; h <= 5; h++ {
+		b := core.NewBlock(chain[h-1].ID, uint64(h), []*core.Transaction{core.NewTransaction(h, 1, core.TxGas+uint32(h), alice, bob)})
+		b.Finalize(state)
+		chain = append(chain, b)
+	}
+
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		cw := core.NewChainWriter(&buf, compress)
+		for _, b := range chain {
+			require.Nil(t, cw.WriteBlock(b))
+		}
+		require.Nil(t, cw.Close())
+		data := buf.Bytes()
+
+		//Blocks come back as they went in, compressed or not
+		cr, err := core.NewChainReader(bytes.NewReader(data))
+		require.Nil(t, err)
+		for _, b := range chain {
+			read, err := cr.ReadBlock()
+			require.Nil(t, err)
+			require.Equal(t, b.ID, read.ID)
+			require.Equal(t, len(b.Transactions()), len(read.Transactions()))
+		}
+		_, err = cr.ReadBlock()
+		require.Equal(t, io.EOF, err)
+
+		//A file cut short fails rather than ending early
+		cr, err = core.NewChainReader(bytes.NewReader(data[:len(data)-3]))
+		require.Nil(t, err)
+		for err == nil {
+			_, err = cr.ReadBlock()
+		}
+		require.NotEqual(t, io.EOF, err)
+	}
+
+	//An empty file holds no blocks
+	cr, err := core.NewChainReader(bytes.NewReader(nil))
+	require.Nil(t, err)
+	_, err = cr.ReadBlock()
+	require.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,44 @@
+package core_test
+
+import (
+	"github.com/liangalv/goChain/core"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMemPoolEvents(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	bus := core.NewEventBus()
+	sub := bus.Subscribe(4)
+	defer sub.Unsubscribe()
+	mp := core.NewMemPool(nil, bus)
+
+	trans := core.NewTransaction(0, 10, core.TxGas, alice, bob)
+	require.Nil(t, mp.AddTransactionToPool(trans))
+	require.NotNil(t, mp.AddTransactionToPool(trans))
+	require.Nil(t, mp.EvictTransaction(trans.ID))
+
+	e := <-sub.Events()
+	require.Equal(t, core.TxAddedEvent, e.Type)
+	require.Equal(t, trans.ID, e.Transaction.ID)
+	e = <-sub.Events()
+	require.Equal(t, core.TxEvictedEvent, e.Type)
+	require.Equal(t, 0, mp.Len())
+}
+
+func TestEventBusDropsLaggingSubscriber(t *testing.T) {
+	bus := core.NewEventBus()
+	slow := bus.Subscribe(1)
+	fast := bus.Subscribe(2)
+	bus.Publish(core.Event{Type: core.NewHeadEvent})
+	bus.Publish(core.Event{Type: core.NewHeadEvent})
+
+	<-slow.Events()
+	_, ok := <-slow.Events()
+	require.False(t, ok)
+	require.Equal(t, core.ErrSubscriberLagged, slow.Err())
+	require.Len(t, fast.Events(), 2)
+	require.Nil(t, fast.Err())
+	fast.Unsubscribe()
+}
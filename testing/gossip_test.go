@@ -0,0 +1,82 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/services"
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/p2p"
+	"github.com/stretchr/testify/require"
+)
+
+// A p2p node running transaction gossip over its own fakeChain
+func startGossipNode(t *testing.T) (*p2p.Server, *fakeChain) {
+	fc := newFakeChain(nil)
+	return startNode(t, fc, p2p.NewTxGossip(fc, services.NewTransactionService(fc), fc.events)), fc
+}
+
+func TestTxGossip(t *testing.T) {
+	//a - b - c, a and c only hear about each other's transactions through b
+	a, fa := startGossipNode(t)
+	b, fb := startGossipNode(t)
+	c, fc := startGossipNode(t)
+	_, err := a.Dial(b.Addr())
+	require.Nil(t, err)
+	_, err = c.Dial(b.Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return b.PeerCount() == 2 })
+
+	tx := core.NewTransaction(0, 5, core.TxGas, [core.AddressLength]byte{1}, [core.AddressLength]byte{2})
+	require.Nil(t, fa.AddTransaction(tx))
+	waitFor(t, func() bool { return fb.PoolTransaction(tx.ID) != nil && fc.PoolTransaction(tx.ID) != nil })
+	require.Equal(t, tx.ConvertToTransactionPbMsg(), fc.PoolTransaction(tx.ID).ConvertToTransactionPbMsg())
+
+	//Every pool holds exactly the one transaction, nothing was echoed back as a duplicate
+	tx2 := core.NewTransaction(0, 7, core.TxGas, [core.AddressLength]byte{3}, [core.AddressLength]byte{4})
+	require.Nil(t, fc.AddTransaction(tx2))
+	waitFor(t, func() bool { return fa.PoolTransaction(tx2.ID) != nil })
+	require.Equal(t, 2, fa.PendingTransactions())
+	require.Equal(t, 2, fb.PendingTransactions())
+	require.Equal(t, 2, fc.PendingTransactions())
+}
+
+func TestTxGossipSkipsIncluded(t *testing.T) {
+	a, fa := startGossipNode(t)
+	b, rec := startRecorderNode(t, p2p.TxCap)
+	p, err := b.Dial(a.Addr())
+	require.Nil(t, err)
+
+	//A late announcement of a transaction a block already sealed is never requested, only the new one is
+	sealed := core.NewTransaction(0, 5, core.TxGas, [core.AddressLength]byte{1}, [core.AddressLength]byte{2})
+	fresh := core.NewTransaction(1, 6, core.TxGas, [core.AddressLength]byte{1}, [core.AddressLength]byte{2})
+	fa.included[sealed.ID] = true
+	require.Nil(t, p.Send(&types.PeerMsg{Payload: &types.PeerMsg_TxAnnounce{TxAnnounce: &types.TxAnnounce{
+		Hashes: [][]byte{sealed.ID[:], fresh.ID[:]},
+	}}}))
+	waitFor(t, func() bool { return len(rec.received()) > 0 })
+	require.Equal(t, [][]byte{fresh.ID[:]}, rec.received()[0].GetTxRequest().GetHashes())
+}
+
+func TestSendTransactionsRejectsInvalidBatch(t *testing.T) {
+	fc := newFakeChain(nil)
+	ts := services.NewTransactionService(fc)
+	good := core.NewTransaction(0, 1, core.TxGas, [core.AddressLength]byte{1}, [core.AddressLength]byte{2})
+	bad := core.NewTransaction(0, 1, core.TxGas-1, [core.AddressLength]byte{1}, [core.AddressLength]byte{2})
+
+	res, err := ts.SendTransactions(context.Background(), &types.TransactionBatch{Batch: []*types.TransactionMsg{
+		good.ConvertToTransactionPbMsg(), bad.ConvertToTransactionPbMsg(),
+	}})
+	require.Nil(t, err)
+	require.Equal(t, types.Status_INVALID_REQUEST, res.GetStatus())
+	require.Equal(t, 0, fc.PendingTransactions())
+
+	//Resending a transaction the pool already has isn't an error
+	batch := &types.TransactionBatch{Batch: []*types.TransactionMsg{good.ConvertToTransactionPbMsg()}}
+	res, _ = ts.SendTransactions(context.Background(), batch)
+	require.Equal(t, types.Status_SUCCESS, res.GetStatus())
+	res, _ = ts.SendTransactions(context.Background(), batch)
+	require.Equal(t, types.Status_SUCCESS, res.GetStatus())
+	require.Equal(t, 1, fc.PendingTransactions())
+}
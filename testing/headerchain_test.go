@@ -0,0 +1,127 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/structures"
+	"github.com/stretchr/testify/require"
+)
+
+// The MountainRange of chain, which starts at genesis, as the block after it commits to
+func historyOf(t *testing.T, chain []*core.Block) *structures.MountainRange {
+	history := structures.NewMountainRange(structures.NewMemNodeStore())
+	for _, b := range chain {
+		require.Nil(t, history.Append(b.ID))
+	}
+	return history
+}
+
+// n headers on top of the last block of chain, each committing to the history before it
+func headerBranch(t *testing.T, chain []*core.Block, n int) []*core.Block {
+	history := historyOf(t, chain)
+	parent := chain[len(chain)-1]
+	var headers []*core.Block
+	for i := 0; i < n; i++ {
+		b := core.NewBlock(parent.ID, parent.Height()+1, nil)
+		b.SetHistoryRoot(history.Root())
+		b.Finalize(core.NewState(nil))
+		require.Nil(t, history.Append(b.ID))
+		headers = append(headers, b)
+		parent = b
+	}
+	return headers
+}
+
+func join(chains ...[]*core.Block) []*core.Block {
+	var joined []*core.Block
+	for _, c := range chains {
+		joined = append(joined, c...)
+	}
+	return joined
+}
+
+func requireCanonical(t *testing.T, hc *core.HeaderChain, chain []*core.Block) {
+	require.Equal(t, chain[len(chain)-1].ID, hc.LastBlock().ID)
+	for _, b := range chain {
+		canon, err := hc.GetBlockByHeight(b.Height())
+		require.Nil(t, err)
+		require.Equal(t, b.ID, canon.ID)
+	}
+	_, err := hc.GetBlockByHeight(uint64(len(chain)))
+	require.Equal(t, core.ErrNotFound, err)
+	require.Equal(t, uint64(len(chain))*core.DIFFICULTY, hc.TotalDifficulty())
+}
+
+func TestHeaderChainReorg(t *testing.T) {
+	path := t.TempDir()
+	store, err := core.OpenBlockStore(path)
+	require.Nil(t, err)
+	bus := core.NewEventBus()
+	sub := bus.Subscribe(64)
+	defer sub.Unsubscribe()
+	genesis := core.NewGenesisBlock()
+	genesis.Finalize(core.NewState(nil))
+	hc, err := core.NewHeaderChain(store, bus, genesis)
+	require.Nil(t, err)
+	require.Equal(t, genesis.ID, hc.GenesisHash())
+
+	//Extending the head makes each header the new head in turn
+	main := join([]*core.Block{genesis}, headerBranch(t, []*core.Block{genesis}, 5))
+	require.Nil(t, hc.InsertHeaders(main[1:]))
+	requireCanonical(t, hc, main)
+	for _, b := range main[1:] {
+		e := <-sub.Events()
+		require.Equal(t, core.NewHeadEvent, e.Type)
+		require.Equal(t, b.ID, e.Block.ID)
+	}
+
+	//A side branch with less total difficulty is kept but not made canonical
+	side := headerBranch(t, main[:3], 2)
+	require.Nil(t, hc.InsertHeaders(side))
+	requireCanonical(t, hc, main)
+	stored, err := hc.GetBlockByHash(side[1].ID)
+	require.Nil(t, err)
+	require.Equal(t, side[1].ID, stored.ID)
+
+	//A side branch has to commit to its own history, not the canonical chain's
+	bad := core.NewBlock(side[1].ID, side[1].Height()+1, nil)
+	bad.SetHistoryRoot(historyOf(t, main[:5]).Root())
+	bad.Finalize(core.NewState(nil))
+	err = hc.InsertHeaders([]*core.Block{bad})
+	require.IsType(t, &core.BlockError{}, err)
+	require.Contains(t, err.Error(), "history root")
+	requireCanonical(t, hc, main)
+
+	//Growing the side branch past the canonical chain's total difficulty reorgs onto it, found from its stored headers
+	ext := headerBranch(t, join(main[:3], side), 3)
+	require.Nil(t, hc.InsertHeaders(ext))
+	reorged := join(main[:3], side, ext)
+	requireCanonical(t, hc, reorged)
+	e := <-sub.Events()
+	require.Equal(t, core.ReorgEvent, e.Type)
+	require.Equal(t, []*core.Block{main[5], main[4], main[3]}, e.Removed)
+	require.Equal(t, join(side, ext), e.Added)
+	select {
+	case e := <-sub.Events():
+		t.Fatalf("unexpected event %d after the reorg", e.Type)
+	default:
+	}
+
+	unknown := headerBranch(t, main, 1)
+	unknown[0] = core.NewBlock([32]byte{1}, 9, nil)
+	unknown[0].Finalize(core.NewState(nil))
+	require.Equal(t, core.ErrUnknownParent, hc.InsertHeaders(unknown))
+
+	//A restart reads the canonical headers and their history back, and carries on from the head
+	require.Nil(t, store.Close())
+	store, err = core.OpenBlockStore(path)
+	require.Nil(t, err)
+	defer store.Close()
+	hc, err = core.NewHeaderChain(store, core.NewEventBus(), genesis)
+	require.Nil(t, err)
+	requireCanonical(t, hc, reorged)
+	next := headerBranch(t, reorged, 1)
+	require.Nil(t, hc.InsertHeaders(next))
+	requireCanonical(t, hc, join(reorged, next))
+}
@@ -0,0 +1,152 @@
+package core_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/jsonrpc"
+	"github.com/liangalv/goChain/core/services"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain backs the services with a single genesis block and a MemPool, included stands in for the transactions
+// of blocks it doesn't have
+type fakeChain struct {
+	genesis  *core.Block
+	pool     *core.MemPool
+	state    *core.State
+	events   *core.EventBus
+	included map[[32]byte]bool
+}
+
+func newFakeChain(alloc map[[core.AddressLength]byte]uint64) *fakeChain {
+	genesis := core.NewGenesisBlock()
+	genesis.Finalize(core.NewState(nil))
+	events := core.NewEventBus()
+	return &fakeChain{
+		genesis:  genesis,
+		pool:     core.NewMemPool(nil, events),
+		state:    core.NewState(alloc),
+		events:   events,
+		included: map[[32]byte]bool{},
+	}
+}
+
+func (fc *fakeChain) GetReceipt(txID [32]byte) (*core.Receipt, error) { return nil, core.ErrNotFound }
+func (fc *fakeChain) GetTransaction(txID [32]byte) (*core.Transaction, *core.TxLookup, error) {
+	return nil, nil, core.ErrNotFound
+}
+func (fc *fakeChain) GetBlockByHash(hash [32]byte) (*core.Block, error) {
+	if hash != fc.genesis.ID {
+		return nil, core.ErrNotFound
+	}
+	return fc.genesis, nil
+}
+func (fc *fakeChain) GetBlockByHeight(height uint64) (*core.Block, error) {
+	if height != 0 {
+		return nil, core.ErrNotFound
+	}
+	return fc.genesis, nil
+}
+func (fc *fakeChain) LastBlock() *core.Block  { return fc.genesis }
+func (fc *fakeChain) ChainID() uint64         { return 1 }
+func (fc *fakeChain) TotalDifficulty() uint64 { return fc.genesis.Difficulty() }
+func (fc *fakeChain) AddTransaction(t *core.Transaction) error {
+	return fc.pool.AddTransactionToPool(t)
+}
+func (fc *fakeChain) PendingTransactions() int { return fc.pool.Size() }
+func (fc *fakeChain) PoolTransaction(id [32]byte) *core.Transaction {
+	t, _ := fc.pool.GetTransaction(id)
+	return t
+}
+func (fc *fakeChain) PoolTransactions() []*core.Transaction { return fc.pool.Pending() }
+func (fc *fakeChain) TransactionIncluded(id [32]byte) bool  { return fc.included[id] }
+func (fc *fakeChain) GenesisHash() [32]byte                 { return fc.genesis.ID }
+func (fc *fakeChain) GetAddressHistory(address [core.AddressLength]byte) ([][32]byte, error) {
+	return nil, nil
+}
+func (fc *fakeChain) GetBalance(address [core.AddressLength]byte) (uint64, error) {
+	return fc.state.Balance(address), nil
+}
+func (fc *fakeChain) GetLogs(filter *core.LogFilter) ([]*core.FilteredLog, error) {
+	return []*core.FilteredLog{}, nil
+}
+func (fc *fakeChain) FindTransactions(address [core.AddressLength]byte, from, to uint64) ([][32]byte, error) {
+	return [][32]byte{}, nil
+}
+
+func newGateway(fc services.Chain) *httptest.Server {
+	return httptest.NewServer(jsonrpc.NewServer(
+		services.NewTransactionService(fc),
+		services.NewAccountService(fc),
+		services.NewBlockService(fc),
+	))
+}
+
+func postJSON(t *testing.T, url string, body string) *http.Response {
+	res, err := http.Post(url, "application/json", strings.NewReader(body))
+	require.Nil(t, err)
+	return res
+}
+
+func TestJSONRPCBatch(t *testing.T) {
+	var alice [core.AddressLength]byte
+	alice[0] = 1
+	fc := newFakeChain(map[[core.AddressLength]byte]uint64{alice: 42})
+	srv := newGateway(fc)
+	defer srv.Close()
+
+	body := `[
+		{"jsonrpc":"2.0","id":1,"method":"chain_getBlockByNumber","params":[0]},
+		{"jsonrpc":"2.0","id":2,"method":"account_getBalance","params":["0x0100000000000000000000000000000000000000"]},
+		{"jsonrpc":"2.0","id":3,"method":"tx_send","params":[{"from":"0x0100000000000000000000000000000000000000","to":"0x0200000000000000000000000000000000000000","value":5,"gas":21000}]},
+		{"jsonrpc":"2.0","id":4,"method":"mempool_status","params":[]},
+		{"jsonrpc":"2.0","id":5,"method":"nope"},
+		{"jsonrpc":"2.0","method":"mempool_status"}
+	]`
+	res := postJSON(t, srv.URL, body)
+	defer res.Body.Close()
+	var out []struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *jsonrpc.Error  `json:"error"`
+	}
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&out))
+	//The notification gets no response
+	require.Len(t, out, 5)
+
+	var block map[string]any
+	require.Nil(t, json.Unmarshal(out[0].Result, &block))
+	require.Equal(t, "0x"+hex.EncodeToString(fc.genesis.ID[:]), block["hash"])
+	require.Equal(t, "42", string(out[1].Result))
+	require.Nil(t, out[2].Error)
+	require.Contains(t, string(out[3].Result), `"pending":1`)
+	require.Equal(t, -32601, out[4].Error.Code)
+}
+
+func TestJSONRPCErrors(t *testing.T) {
+	srv := newGateway(newFakeChain(nil))
+	defer srv.Close()
+
+	res := postJSON(t, srv.URL, `{"jsonrpc":"2.0","id":1,"method":"account_getBalance","params":["0x01"]}`)
+	var out struct {
+		Error *jsonrpc.Error `json:"error"`
+	}
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&out))
+	res.Body.Close()
+	require.Equal(t, -32602, out.Error.Code)
+
+	res = postJSON(t, srv.URL, `{not json`)
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&out))
+	res.Body.Close()
+	require.Equal(t, -32700, out.Error.Code)
+
+	res = postJSON(t, srv.URL, `{"jsonrpc":"2.0","method":"mempool_status"}`)
+	res.Body.Close()
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+}
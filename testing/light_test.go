@@ -0,0 +1,193 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/p2p"
+	"github.com/stretchr/testify/require"
+)
+
+// proofChain lets a devChain serve proofs to light clients, receipts and states are found by replaying the chain
+// from alloc
+type proofChain struct {
+	*devChain
+	alloc map[[core.AddressLength]byte]uint64
+}
+
+func newProofChain(alloc map[[core.AddressLength]byte]uint64) *proofChain {
+	pc := &proofChain{devChain: newDevChain(), alloc: alloc}
+	pc.state = core.NewState(alloc)
+	return pc
+}
+
+func (pc *proofChain) TxProof(txID [32]byte) (*core.TxProof, error) {
+	pc.mux.Lock()
+	defer pc.mux.Unlock()
+	state := core.NewState(pc.alloc)
+	for _, b := range pc.canon {
+		receipts := b.Execute(state)
+		for i, t := range b.Transactions() {
+			if t.ID == txID {
+				return core.NewTxProof(b, receipts, uint32(i))
+			}
+		}
+	}
+	return nil, core.ErrNotFound
+}
+
+func (pc *proofChain) AccountProof(root [32]byte, address [core.AddressLength]byte) (*core.AccountProof, error) {
+	pc.mux.Lock()
+	defer pc.mux.Unlock()
+	state := core.NewState(pc.alloc)
+	for _, b := range pc.canon {
+		b.Execute(state)
+		if b.StateRoot() == root {
+			return state.ProveAccount(address)
+		}
+	}
+	return nil, core.ErrNotFound
+}
+
+// headerChain is a devChain that only ever takes headers, extending its head
+type headerChain struct {
+	*devChain
+}
+
+func (hc *headerChain) InsertHeaders(headers []*core.Block) error {
+	hc.mux.Lock()
+	defer hc.mux.Unlock()
+	for _, h := range headers {
+		head := hc.canon[len(hc.canon)-1]
+		if h.ParentHash() != head.ID {
+			return core.ErrUnknownParent
+		}
+		if err := h.VerifyHeader(head); err != nil {
+			return err
+		}
+		hc.blocks[h.ID] = h
+		hc.canon = append(hc.canon, h)
+	}
+	return nil
+}
+
+func TestTxProofMsg(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	state := core.NewState(map[[core.AddressLength]byte]uint64{alice: 100})
+	var trans []*core.Transaction
+	for i := 0; i < 5; i++ {
+		trans = append(trans, core.NewTransaction(i, 10, core.TxGas+uint32(i), alice, bob))
+	}
+	b := core.NewBlock([32]byte{}, 1, trans)
+	receipts := b.Finalize(state)
+
+	tp, err := core.NewTxProof(b, receipts, 3)
+	require.Nil(t, err)
+	require.Nil(t, tp.Verify(b))
+	//Survives the trip over the wire, the receipt's block hash comes from the proof
+	msg := tp.ConvertToTxProofPbMsg()
+	decoded, err := core.NewTxProofFromPbMsg(msg)
+	require.Nil(t, err)
+	require.Nil(t, decoded.Verify(b))
+	require.Equal(t, b.ID, decoded.Receipt.BlockHash)
+	require.Equal(t, trans[3].ID, decoded.Transaction.ID)
+
+	//A receipt for another transaction, or proven against another block, doesn't verify
+	decoded, _ = core.NewTxProofFromPbMsg(msg)
+	decoded.Transaction = trans[2]
+	require.NotNil(t, decoded.Verify(b))
+	decoded, _ = core.NewTxProofFromPbMsg(msg)
+	decoded.Receipt.Status = core.ReceiptFailed
+	require.NotNil(t, decoded.Verify(b))
+	other := core.NewBlock([32]byte{}, 1, trans)
+	other.Finalize(core.NewState(nil))
+	require.NotNil(t, tp.Verify(other))
+
+	//A peer without the transaction replies with an empty proof
+	_, err = core.NewTxProofFromPbMsg(&types.TxProof{RequestID: 1})
+	require.Equal(t, core.ErrNotFound, err)
+}
+
+func TestAccountProofMsg(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	state := core.NewState(map[[core.AddressLength]byte]uint64{alice: 100})
+
+	ap, err := state.ProveAccount(alice)
+	require.Nil(t, err)
+	decoded, err := core.NewAccountProofFromPbMsg(ap.ConvertToAccountProofPbMsg())
+	require.Nil(t, err)
+	require.Nil(t, decoded.Verify(state.Root()))
+	require.Equal(t, uint64(100), decoded.Account.Balance)
+	decoded.Account.Balance = 1000
+	require.NotNil(t, decoded.Verify(state.Root()))
+
+	//An account that isn't in the state is proven absent, with a zero balance
+	ap, err = state.ProveAccount(bob)
+	require.Nil(t, err)
+	decoded, err = core.NewAccountProofFromPbMsg(ap.ConvertToAccountProofPbMsg())
+	require.Nil(t, err)
+	require.Nil(t, decoded.Verify(state.Root()))
+	require.Equal(t, uint64(0), decoded.Account.Balance)
+	decoded.Account.Balance = 1
+	require.NotNil(t, decoded.Verify(state.Root()))
+
+	_, err = core.NewAccountProofFromPbMsg(&types.AccountProof{Unavailable: true})
+	require.Equal(t, core.ErrNotFound, err)
+}
+
+func TestLightClient(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	source := newProofChain(map[[core.AddressLength]byte]uint64{alice: 1000})
+	var sent []*core.Transaction
+	for i := 0; i < 250; i++ {
+		var trans []*core.Transaction
+		if i%10 == 0 {
+			trans = append(trans, core.NewTransaction(i, 1, core.TxGas+uint32(i), alice, bob))
+		}
+		sent = append(sent, trans...)
+		source.produce(trans)
+	}
+	full := startNode(t, source, p2p.NewSyncer(source))
+
+	//The light client downloads every header and no bodies
+	light := &headerChain{devChain: newDevChain()}
+	syncer := p2p.NewLightSyncer(light)
+	node := startNode(t, light, syncer)
+	require.Contains(t, syncer.Capabilities(), p2p.LightCap)
+	_, err := node.Dial(full.Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return light.LastBlock().ID == source.LastBlock().ID })
+	for _, h := range light.canon[1:] {
+		require.Empty(t, h.Transactions())
+	}
+
+	//Headers the full node produces after the light client caught up are picked up without any block gossip
+	source.produce(nil)
+	waitFor(t, func() bool { return light.LastBlock().ID == source.LastBlock().ID })
+
+	//Transactions are proven against the light client's own headers
+	tp, err := syncer.RequestTxProof(sent[7].ID)
+	require.Nil(t, err)
+	require.Equal(t, sent[7].ID, tp.Transaction.ID)
+	require.Equal(t, core.ReceiptSucceeded, tp.Receipt.Status)
+	header, err := light.GetBlockByHeight(71)
+	require.Nil(t, err)
+	require.Equal(t, header.ID, tp.BlockHash)
+	_, err = syncer.RequestTxProof([32]byte{1})
+	require.Equal(t, core.ErrNotFound, err)
+
+	//Balances are proven against the state root of the head
+	root := light.LastBlock().StateRoot()
+	ap, err := syncer.RequestAccountProof(root, bob)
+	require.Nil(t, err)
+	require.Equal(t, uint64(len(sent)), ap.Account.Balance)
+	ap, err = syncer.RequestAccountProof(root, [core.AddressLength]byte{9})
+	require.Nil(t, err)
+	require.Equal(t, uint64(0), ap.Account.Balance)
+	_, err = syncer.RequestAccountProof([32]byte{1}, bob)
+	require.Equal(t, core.ErrNotFound, err)
+}
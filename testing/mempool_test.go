@@ -4,6 +4,25 @@ import (
 	"github.com/liangalv/goChain/core"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
-func TestMemPoolInit(t testing.T) {}
+func TestMemPoolInit(t *testing.T) {
+	mp := core.NewMemPool(nil, nil)
+	require.Equal(t, 0, mp.Len())
+}
+
+func TestMemPoolExpire(t *testing.T) {
+	mp := core.NewMemPool(nil, nil)
+	tx := core.NewTransaction(0, 1, core.TxGas, [core.AddressLength]byte{1}, [core.AddressLength]byte{2})
+	require.Nil(t, mp.AddTransactionToPool(tx))
+	require.Equal(t, 0, mp.Expire(time.Hour))
+	require.Equal(t, 1, mp.Size())
+
+	//Anything older than the cutoff goes
+	time.Sleep(time.Millisecond)
+	require.Equal(t, 1, mp.Expire(0))
+	require.Equal(t, 0, mp.Size())
+	_, ok := mp.GetTransaction(tx.ID)
+	require.False(t, ok)
+}
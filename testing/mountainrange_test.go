@@ -0,0 +1,84 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/structures"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestMountainRange(t *testing.T) {
+	mr := structures.NewMountainRange(structures.NewMemNodeStore())
+	require.Equal(t, [32]byte{}, mr.Root())
+	var leaves [][32]byte
+	var roots [][32]byte
+	for i := 0; i < 37; i++ {
+		leaves = append(leaves, sha3.Sum256([]byte{byte(i)}))
+		require.Nil(t, mr.Append(leaves[i]))
+		roots = append(roots, mr.Root())
+		//Every leaf so far is provable under the new root
+		for j := range leaves {
+			proof, err := mr.ConstructProof(uint64(j))
+			require.Nil(t, err)
+			require.True(t, structures.VerifyMountainProof(mr.Root(), leaves[j], proof), "leaf %d of %d", j, i+1)
+		}
+	}
+	require.Equal(t, uint64(37), mr.Size())
+
+	proof, err := mr.ConstructProof(20)
+	require.Nil(t, err)
+	root := mr.Root()
+	//The wrong leaf, the wrong position, or an older root
+	require.False(t, structures.VerifyMountainProof(root, leaves[21], proof))
+	moved := *proof
+	moved.Index = 21
+	require.False(t, structures.VerifyMountainProof(root, leaves[20], &moved))
+	require.False(t, structures.VerifyMountainProof(roots[30], leaves[20], proof))
+	short := *proof
+	short.Siblings = proof.Siblings[1:]
+	require.False(t, structures.VerifyMountainProof(root, leaves[20], &short))
+	_, err = mr.ConstructProof(37)
+	require.NotNil(t, err)
+
+	//A copy grows on its own
+	fork := mr.Copy()
+	require.Nil(t, fork.Append(sha3.Sum256([]byte("fork"))))
+	require.Equal(t, root, mr.Root())
+	require.NotEqual(t, root, fork.Root())
+}
+
+func TestBlockHistory(t *testing.T) {
+	history := structures.NewMountainRange(structures.NewMemNodeStore())
+	var chain []*core.Block
+	parent := core.NewGenesisBlock()
+	parent.Finalize(core.NewState(nil))
+	chain = append(chain, parent)
+	require.Nil(t, history.Append(parent.ID))
+	for h := uint64(1); h < 10; h++ {
+		b := core.NewBlock(parent.ID, h, nil)
+		b.SetHistoryRoot(history.Root())
+		b.Finalize(core.NewState(nil))
+		//The commitment survives the trip through the wire format and is part of the block hash
+		decoded := core.NewBlockFromPbMsg(b.ConvertToBlockPbMsg())
+		require.Equal(t, b.ID, decoded.ID)
+		require.Nil(t, decoded.VerifyHistory(history.Root()))
+		require.Nil(t, history.Append(b.ID))
+		require.NotNil(t, decoded.VerifyHistory(history.Root()))
+		chain = append(chain, b)
+		parent = b
+	}
+	//Any earlier block is provable against the history root a later header commits to
+	head := core.NewBlock(parent.ID, 10, nil)
+	head.SetHistoryRoot(history.Root())
+	for h, b := range chain {
+		proof, err := history.ConstructProof(uint64(h))
+		require.Nil(t, err)
+		require.True(t, structures.VerifyMountainProof(head.HistoryRoot(), b.ID, proof))
+	}
+	//Blocks from before the history existed don't commit to it
+	msg := chain[3].ConvertToBlockPbMsg()
+	msg.Version, msg.HistoryRoot = 1, nil
+	require.Nil(t, core.NewBlockFromPbMsg(msg).VerifyHistory(history.Root()))
+}
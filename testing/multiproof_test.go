@@ -0,0 +1,76 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/liangalv/goChain/structures"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestMultiProof(t *testing.T) {
+	for _, mode := range []structures.TreeMode{structures.LegacyTree, structures.SeparatedTree} {
+		testMultiProof(t, mode)
+	}
+}
+
+func testMultiProof(t *testing.T, mode structures.TreeMode) {
+	for _, size := range []int{1, 2, 3, 7, 64, 101} {
+		tt := structures.NewTransactionTree(mode)
+		leaves := make([][32]byte, size)
+		for i := range leaves {
+			leaves[i] = sha3.Sum256([]byte{byte(i), byte(size)})
+			require.Nil(t, tt.Add(leaves[i]))
+		}
+		require.Nil(t, tt.Construct())
+		root := tt.Root()
+
+		//Every other leaf, in no particular order
+		var proven [][32]byte
+		for i := size - 1; i >= 0; i -= 2 {
+			proven = append(proven, leaves[i])
+		}
+		proof, err := tt.ConstructMultiProof(proven)
+		require.Nil(t, err)
+		require.True(t, structures.VerifyMultiProof(mode, root, proven, proof), "mode %d size %d", mode, size)
+
+		//Proving every leaf needs nothing else
+		proof, err = tt.ConstructMultiProof(leaves)
+		require.Nil(t, err)
+		require.Equal(t, 0, len(proof.Hashes))
+		require.True(t, structures.VerifyMultiProof(mode, root, leaves, proof))
+	}
+
+	tt := structures.NewTransactionTree(mode)
+	leaves := make([][32]byte, 200)
+	for i := range leaves {
+		leaves[i] = sha3.Sum256([]byte{byte(i), byte(i >> 8)})
+		require.Nil(t, tt.Add(leaves[i]))
+	}
+	require.Nil(t, tt.Construct())
+	root := tt.Root()
+	proven := leaves[20:70]
+	proof, err := tt.ConstructMultiProof(proven)
+	require.Nil(t, err)
+	require.True(t, structures.VerifyMultiProof(mode, root, proven, proof))
+	//50 neighbouring transactions share almost every hash a proof of each would hold
+	require.True(t, len(proof.Hashes) < 20)
+
+	tampered := append([][32]byte{}, proven...)
+	tampered[7][0]++
+	require.False(t, structures.VerifyMultiProof(mode, root, tampered, proof))
+	//Claiming leaves sit somewhere else
+	moved := *proof
+	moved.Indices = append([]int{}, proof.Indices...)
+	moved.Indices[0], moved.Indices[1] = moved.Indices[1], moved.Indices[0]
+	require.False(t, structures.VerifyMultiProof(mode, root, proven, &moved))
+	short := *proof
+	short.Hashes = proof.Hashes[1:]
+	require.False(t, structures.VerifyMultiProof(mode, root, proven, &short))
+
+	_, err = tt.ConstructMultiProof([][32]byte{sha3.Sum256([]byte("missing"))})
+	require.NotNil(t, err)
+	require.Nil(t, tt.Add(sha3.Sum256([]byte("new"))))
+	_, err = tt.ConstructMultiProof(proven)
+	require.NotNil(t, err)
+}
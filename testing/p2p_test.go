@@ -0,0 +1,304 @@
+package core_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/p2p"
+	"github.com/stretchr/testify/require"
+)
+
+// p2pChain is the smallest chain a p2p.Server can handshake with
+type p2pChain struct {
+	chainID uint64
+	genesis *core.Block
+}
+
+func newP2PChain(chainID uint64) *p2pChain {
+	genesis := core.NewGenesisBlock()
+	genesis.Finalize(core.NewState(nil))
+	return &p2pChain{chainID: chainID, genesis: genesis}
+}
+
+func (c *p2pChain) ChainID() uint64         { return c.chainID }
+func (c *p2pChain) GenesisHash() [32]byte   { return c.genesis.ID }
+func (c *p2pChain) LastBlock() *core.Block  { return c.genesis }
+func (c *p2pChain) TotalDifficulty() uint64 { return c.genesis.Difficulty() }
+
+// A p2p node over chain running protocols, the node and every protocol that can be stopped are stopped when the test ends
+func startNode(t *testing.T, chain p2p.Chain, protocols ...p2p.Protocol) *p2p.Server {
+	return startNodeConfig(t, p2p.Config{}, chain, protocols...)
+}
+
+// startNode with a config of its own, listening on a free local port unless the config says otherwise
+func startNodeConfig(t *testing.T, cfg p2p.Config, chain p2p.Chain, protocols ...p2p.Protocol) *p2p.Server {
+	if cfg.ListenAddr == "" {
+		cfg.ListenAddr = "127.0.0.1:0"
+	}
+	node := p2p.NewServer(cfg, chain)
+	for _, proto := range protocols {
+		node.RegisterProtocol(proto)
+	}
+	require.Nil(t, node.Start())
+	//Stopping the node stops its protocols too
+	t.Cleanup(node.Stop)
+	return node
+}
+
+// recorder advertises capabilities and keeps every message it is handed, for nodes whose messages are sent by hand
+type recorder struct {
+	caps []p2p.Capability
+	mux  sync.Mutex
+	msgs []*types.PeerMsg
+}
+
+func (r *recorder) Capabilities() []p2p.Capability { return r.caps }
+func (r *recorder) AddPeer(p *p2p.Peer)            {}
+func (r *recorder) RemovePeer(p *p2p.Peer)         {}
+
+func (r *recorder) HandleMsg(p *p2p.Peer, msg *types.PeerMsg) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.msgs = append(r.msgs, msg)
+	return nil
+}
+
+func (r *recorder) received() []*types.PeerMsg {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return append([]*types.PeerMsg{}, r.msgs...)
+}
+
+func startRecorderNode(t *testing.T, caps ...p2p.Capability) (*p2p.Server, *recorder) {
+	r := &recorder{caps: caps}
+	return startNode(t, newP2PChain(1), r), r
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		require.True(t, time.Now().Before(deadline), "condition was never met")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPeerHandshake(t *testing.T) {
+	a := startNode(t, newP2PChain(1))
+	b := startNode(t, newP2PChain(1))
+
+	p, err := a.Dial(b.Addr())
+	require.Nil(t, err)
+	require.Equal(t, b.NodeID(), p.ID())
+	require.Equal(t, uint32(p2p.ProtocolVersion), p.ProtocolVersion())
+	waitFor(t, func() bool { return b.PeerCount() == 1 })
+	require.True(t, b.Peers()[0].Inbound())
+
+	//A second connection to the same node is refused
+	_, err = a.Dial(b.Addr())
+	require.Equal(t, &p2p.DisconnectError{Reason: types.DisconnectReason_ALREADY_CONNECTED}, err)
+
+	p.Disconnect(types.DisconnectReason_REQUESTED)
+	waitFor(t, func() bool { return a.PeerCount() == 0 && b.PeerCount() == 0 })
+}
+
+func TestSimultaneousDial(t *testing.T) {
+	//Repeated as the dials only overlap on some runs
+	for i := 0; i < 5; i++ {
+		a := startNode(t, newP2PChain(1))
+		b := startNode(t, newP2PChain(1))
+		var wg sync.WaitGroup
+		dial := func(from, to *p2p.Server) {
+			defer wg.Done()
+			_, err := from.Dial(to.Addr())
+			if err != nil {
+				require.Equal(t, &p2p.DisconnectError{Reason: types.DisconnectReason_ALREADY_CONNECTED}, err)
+			}
+		}
+		wg.Add(2)
+		go dial(a, b)
+		go dial(b, a)
+		wg.Wait()
+
+		//Both sides keep the same connection, the one dialed by the node with the lower ID
+		aID, bID := a.NodeID(), b.NodeID()
+		aDialed := bytes.Compare(aID[:], bID[:]) < 0
+		settled := func() bool {
+			pa, pb := a.Peers(), b.Peers()
+			return len(pa) == 1 && len(pb) == 1 && pa[0].Inbound() != aDialed && pb[0].Inbound() == aDialed
+		}
+		waitFor(t, settled)
+		time.Sleep(100 * time.Millisecond)
+		require.True(t, settled())
+	}
+}
+
+func TestPeerGenesisMismatch(t *testing.T) {
+	a := startNode(t, newP2PChain(1))
+	other := newP2PChain(1)
+	other.genesis = core.NewBlock([32]byte{1}, 0, nil)
+	other.genesis.Finalize(core.NewState(nil))
+	b := startNode(t, other)
+
+	_, err := a.Dial(b.Addr())
+	require.Equal(t, &p2p.DisconnectError{Reason: types.DisconnectReason_GENESIS_MISMATCH}, err)
+	require.Equal(t, 0, a.PeerCount())
+	require.Equal(t, 0, b.PeerCount())
+}
+
+func TestMaxPeers(t *testing.T) {
+	hub := startNodeConfig(t, p2p.Config{MaxPeers: 1}, newP2PChain(1))
+	a := startNode(t, newP2PChain(1))
+	b := startNode(t, newP2PChain(1))
+
+	_, err := a.Dial(hub.Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return hub.PeerCount() == 1 })
+	_, err = b.Dial(hub.Addr())
+	require.Equal(t, &p2p.DisconnectError{Reason: types.DisconnectReason_TOO_MANY_PEERS}, err)
+}
+
+func TestPeerDiscovery(t *testing.T) {
+	start := func(bootnodes ...string) *p2p.Server {
+		return startNodeConfig(t, p2p.Config{
+			MinPeers:          2,
+			Bootnodes:         bootnodes,
+			DiscoveryInterval: 50 * time.Millisecond,
+		}, newP2PChain(1))
+	}
+	boot := start()
+	a := start(boot.Addr())
+	b := start(boot.Addr())
+
+	//a and b only know the bootnode but learn about each other through it
+	connected := func(x, y *p2p.Server) bool {
+		for _, p := range x.Peers() {
+			if p.ID() == y.NodeID() {
+				return true
+			}
+		}
+		return false
+	}
+	waitFor(t, func() bool { return connected(a, b) && connected(b, a) })
+	require.Equal(t, 2, boot.PeerCount())
+}
+
+func TestPeerBook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peers.json")
+	book, err := p2p.NewPeerBook(path)
+	require.Nil(t, err)
+	book.Add("10.0.0.1:30303")
+	book.Add("10.0.0.2:30303")
+	book.MarkSeen("10.0.0.2:30303")
+	book.MarkFailed("10.0.0.1:30303")
+	require.Equal(t, []string{"10.0.0.2:30303", "10.0.0.1:30303"}, book.Addresses(10))
+	require.Nil(t, book.Save())
+
+	reloaded, err := p2p.NewPeerBook(path)
+	require.Nil(t, err)
+	rec, ok := reloaded.Get("10.0.0.1:30303")
+	require.True(t, ok)
+	require.Equal(t, 1, rec.Failures)
+	rec, ok = reloaded.Get("10.0.0.2:30303")
+	require.True(t, ok)
+	require.False(t, rec.LastSeen.IsZero())
+
+	//Addresses that keep failing are forgotten
+	for i := 0; i < 4; i++ {
+		reloaded.MarkFailed("10.0.0.1:30303")
+	}
+	_, ok = reloaded.Get("10.0.0.1:30303")
+	require.False(t, ok)
+	require.Equal(t, 1, reloaded.Len())
+}
+
+func TestNodeIdentity(t *testing.T) {
+	//The node key, and so the node ID, survives a restart
+	path := filepath.Join(t.TempDir(), "nodekey")
+	key, err := p2p.LoadNodeKey(path)
+	require.Nil(t, err)
+	reloaded, err := p2p.LoadNodeKey(path)
+	require.Nil(t, err)
+	require.Equal(t, key, reloaded)
+
+	member := startNodeConfig(t, p2p.Config{NodeKey: key}, newP2PChain(1))
+	require.Equal(t, p2p.NodeIDFromKey(key), member.NodeID())
+
+	//A permissioned node only lets in the node IDs on its allowlist
+	gate := startNodeConfig(t, p2p.Config{AllowedNodes: [][32]byte{member.NodeID()}}, newP2PChain(1))
+
+	p, err := member.Dial(gate.Addr())
+	require.Nil(t, err)
+	require.Equal(t, gate.NodeID(), p.ID())
+
+	outsider := startNode(t, newP2PChain(1))
+	_, err = outsider.Dial(gate.Addr())
+	require.Equal(t, &p2p.DisconnectError{Reason: types.DisconnectReason_NOT_ALLOWED}, err)
+	require.Equal(t, 1, gate.PeerCount())
+}
+
+func TestCapabilityNegotiation(t *testing.T) {
+	a, ca := startBlockNode(t)
+	//b speaks the same blocks protocol but only a newer version of compact blocks than a has
+	b, rec := startRecorderNode(t, p2p.BlocksCap, p2p.Capability{Name: p2p.CompactCap.Name, Version: p2p.CompactCap.Version + 1})
+	p, err := b.Dial(a.Addr())
+	require.Nil(t, err)
+	require.Equal(t, []p2p.Capability{p2p.BlocksCap}, p.Capabilities())
+	require.False(t, p.Supports(p2p.CompactCap.Name))
+	//Messages of capabilities the peer doesn't share are never sent
+	require.NotNil(t, p.Send(&types.PeerMsg{Payload: &types.PeerMsg_TxAnnounce{TxAnnounce: &types.TxAnnounce{}}}))
+
+	//a falls back to pushing its new heads in full
+	waitFor(t, func() bool { return a.PeerCount() == 1 })
+	b1 := ca.produce(nil)
+	waitFor(t, func() bool {
+		for _, msg := range rec.received() {
+			if nb := msg.GetNewBlock(); nb != nil && core.NewBlockFromPbMsg(nb).ID == b1.ID {
+				return true
+			}
+		}
+		return false
+	})
+	for _, msg := range rec.received() {
+		require.Nil(t, msg.GetCompactBlock())
+	}
+
+	//Nodes with nothing in common have no reason to stay connected
+	c, _ := startRecorderNode(t, p2p.Capability{Name: "other", Version: 1})
+	_, err = c.Dial(a.Addr())
+	require.Equal(t, &p2p.DisconnectError{Reason: types.DisconnectReason_NO_SHARED_CAPABILITIES}, err)
+}
+
+// stoppable is a recorder that remembers being stopped
+type stoppable struct {
+	recorder
+	stopped bool
+}
+
+func (s *stoppable) Stop() { s.stopped = true }
+
+func TestServerStopsProtocols(t *testing.T) {
+	dc := newDevChain()
+	r := &stoppable{recorder: recorder{caps: []p2p.Capability{p2p.TxCap}}}
+	syncer, gossip := p2p.NewSyncer(dc), p2p.NewBlockGossip(dc, dc, dc.events)
+	node := p2p.NewServer(p2p.Config{ListenAddr: "127.0.0.1:0"}, dc)
+	for _, proto := range []p2p.Protocol{r, syncer, gossip} {
+		node.RegisterProtocol(proto)
+	}
+	require.Nil(t, node.Start())
+	peer, _ := startBlockNode(t)
+	_, err := peer.Dial(node.Addr())
+	require.Nil(t, err)
+
+	//Nothing is left running against the chain once the node has stopped, its peers included
+	node.Stop()
+	require.True(t, r.stopped)
+	require.Equal(t, 0, node.PeerCount())
+	//Stopping a protocol the server already stopped does nothing
+	syncer.Stop()
+	gossip.Stop()
+}
@@ -0,0 +1,147 @@
+package core_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatState(t *testing.T) {
+	var alice, bob, carol [core.AddressLength]byte
+	alice[0], bob[0], carol[0] = 1, 2, 3
+	store := core.NewMemBlockStore()
+	defer store.Close()
+	_, _, err := store.ReadFlatState()
+	require.Equal(t, core.ErrNotFound, err)
+
+	genesis := core.NewGenesisBlock()
+	state := core.NewStateWithNodes(store.Nodes(), map[[core.AddressLength]byte]uint64{alice: 100})
+	genesis.Finalize(state)
+	require.Nil(t, store.WriteFlatState(genesis, state, nil))
+	b := core.NewBlock(genesis.ID, 1, []*core.Transaction{core.NewTransaction(0, 40, core.TxGas, alice, bob)})
+	parent := state.Copy()
+	b.Finalize(state)
+	require.Nil(t, store.WriteFlatState(b, state, []*core.Block{b}))
+
+	//The flat accounts hold the same state the tree commits to
+	hash, flat, err := store.ReadFlatState()
+	require.Nil(t, err)
+	require.Equal(t, b.ID, hash)
+	require.Equal(t, b.StateRoot(), flat.Root())
+	require.Equal(t, uint64(60), flat.Balance(alice))
+	require.Equal(t, uint64(40), flat.Balance(bob))
+
+	//Undoing a block rewrites the accounts it touched, one it created is deleted
+	side := core.NewBlock(genesis.ID, 1, []*core.Transaction{core.NewTransaction(0, 10, core.TxGas, alice, carol)})
+	side.Finalize(parent)
+	require.Nil(t, store.WriteFlatState(side, parent, []*core.Block{b, side}))
+	hash, flat, err = store.ReadFlatState()
+	require.Nil(t, err)
+	require.Equal(t, side.ID, hash)
+	require.Equal(t, side.StateRoot(), flat.Root())
+	require.Equal(t, uint64(0), flat.Balance(bob))
+
+	//Rewriting every account gives the same state
+	require.Nil(t, store.WriteFlatState(side, parent, nil))
+	_, flat, err = store.ReadFlatState()
+	require.Nil(t, err)
+	require.Equal(t, side.StateRoot(), flat.Root())
+}
+
+func TestPruneState(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	store := core.NewMemBlockStore()
+	defer store.Close()
+	_, err := store.ReadStateConfig()
+	require.Equal(t, core.ErrNotFound, err)
+	cfg := core.StateConfig{Mode: core.PrunedState, History: 200, Checkpoint: 2048}
+	require.Nil(t, store.WriteStateConfig(cfg))
+	read, err := store.ReadStateConfig()
+	require.Nil(t, err)
+	require.Equal(t, cfg, read)
+
+	//A state per block, every block sends bob one more
+	state := core.NewStateWithNodes(store.Nodes(), map[[core.AddressLength]byte]uint64{alice: 1000})
+	var roots [][32]byte
+	parent := core.NewGenesisBlock()
+	parent.Finalize(state)
+	roots = append(roots, parent.StateRoot())
+	for h := 1; h <= 20; h++ {
+		b := core.NewBlock(parent.ID, uint64(h), []*core.Transaction{core.NewTransaction(h, 1, core.TxGas+uint32(h), alice, bob)})
+		b.Finalize(state)
+		roots = append(roots, b.StateRoot())
+		parent = b
+	}
+	for h, root := range roots {
+		ap, err := core.ProveAccountAt(store.Nodes(), root, bob)
+		require.Nil(t, err)
+		require.Equal(t, uint64(h), ap.Account.Balance)
+		require.Nil(t, ap.Verify(root))
+	}
+
+	//Only the states kept can still be read and proven
+	kept := map[int]bool{0: true, 10: true, 19: true, 20: true}
+	var keep [][32]byte
+	for h := range kept {
+		keep = append(keep, roots[h])
+	}
+	var missing [32]byte
+	missing[0] = 1
+	//A block imported while the sweep runs, after the kept states were first marked
+	guard := &heldLocker{}
+	var late *core.Block
+	keepRoots := func() [][32]byte {
+		require.True(t, guard.held)
+		if late == nil {
+			late = core.NewBlock(parent.ID, 21, []*core.Transaction{core.NewTransaction(21, 1, core.TxGas+21, alice, bob)})
+			late.Finalize(state.Copy())
+			return append(keep, missing)
+		}
+		return append(keep, missing, late.StateRoot())
+	}
+	pruned, err := store.PruneState(keepRoots, guard)
+	require.Nil(t, err)
+	require.True(t, pruned > 0)
+	ap, err := core.ProveAccountAt(store.Nodes(), late.StateRoot(), bob)
+	require.Nil(t, err)
+	require.Equal(t, uint64(21), ap.Account.Balance)
+	for h, root := range roots {
+		ap, err := core.ProveAccountAt(store.Nodes(), root, bob)
+		if !kept[h] {
+			require.Equal(t, core.ErrNotFound, err)
+			continue
+		}
+		require.Nil(t, err)
+		require.Equal(t, uint64(h), ap.Account.Balance)
+		require.Nil(t, ap.Verify(root))
+	}
+	//Nothing is left to prune, and the head state carries on from its kept tree
+	keep = append(keep, late.StateRoot())
+	pruned, err = store.PruneState(func() [][32]byte { return keep }, guard)
+	require.Nil(t, err)
+	require.Equal(t, 0, pruned)
+	b := core.NewBlock(parent.ID, 21, []*core.Transaction{core.NewTransaction(21, 1, core.TxGas, alice, bob)})
+	b.Finalize(state)
+	ap, err = core.ProveAccountAt(store.Nodes(), b.StateRoot(), bob)
+	require.Nil(t, err)
+	require.Equal(t, uint64(21), ap.Account.Balance)
+}
+
+// heldLocker is a sync.Locker that says whether it is held
+type heldLocker struct {
+	mux  sync.Mutex
+	held bool
+}
+
+func (l *heldLocker) Lock() {
+	l.mux.Lock()
+	l.held = true
+}
+
+func (l *heldLocker) Unlock() {
+	l.held = false
+	l.mux.Unlock()
+}
@@ -0,0 +1,95 @@
+package core_test
+
+import (
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/structures"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBlockReceipts(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	trans := []*core.Transaction{
+		core.NewTransaction(0, 10, core.TxGas, alice, bob),
+		core.NewTransaction(1, 5, core.TxGas-1, bob, alice),
+		core.NewTransaction(2, 100, core.TxGas, alice, bob),
+	}
+	state := core.NewState(map[[core.AddressLength]byte]uint64{alice: 100})
+	b := core.NewBlock([32]byte{}, 0, trans)
+	receipts := b.Finalize(state)
+
+	require.Len(t, receipts, 3)
+	require.Equal(t, core.ReceiptSucceeded, receipts[0].Status)
+	require.Len(t, receipts[0].Logs, 1)
+	//Out of gas
+	require.Equal(t, core.ReceiptFailed, receipts[1].Status)
+	require.Equal(t, uint64(2*core.TxGas-1), receipts[1].CumulativeGasUsed)
+	//Insufficient balance
+	require.Equal(t, core.ReceiptFailed, receipts[2].Status)
+	require.Empty(t, receipts[2].Logs)
+	require.Equal(t, b.ID, receipts[2].BlockHash)
+	require.Equal(t, uint64(90), state.Balance(alice))
+	require.Equal(t, uint64(10), state.Balance(bob))
+	require.Equal(t, core.ReceiptsRoot(receipts), b.ReceiptsRoot())
+	require.NotEqual(t, [32]byte{}, b.ReceiptsRoot())
+}
+
+func TestReceiptStore(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	b := core.NewBlock([32]byte{}, 0, []*core.Transaction{core.NewTransaction(0, 10, core.TxGas, alice, bob)})
+	receipts := b.Finalize(core.NewState(nil))
+
+	store := core.NewMemBlockStore()
+	defer store.Close()
+	require.Nil(t, store.CommitBlock(b, receipts))
+	read, err := store.ReadReceipts(b.ID)
+	require.Nil(t, err)
+	require.Equal(t, receipts, read)
+
+	_, err = store.ReadReceipts([32]byte{1})
+	require.Equal(t, core.ErrNotFound, err)
+}
+
+func TestBlockVersions(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	genesis := core.NewGenesisBlock()
+	genesis.Finalize(core.NewState(nil))
+	require.Equal(t, uint32(0), genesis.Version())
+	trans := []*core.Transaction{core.NewTransaction(0, 10, core.TxGas, alice, bob), core.NewTransaction(1, 5, core.TxGas, alice, bob)}
+	alloc := map[[core.AddressLength]byte]uint64{alice: 100}
+	b := core.NewBlock(genesis.ID, 1, trans)
+	receipts := b.Finalize(core.NewState(alloc))
+	require.Equal(t, uint32(core.BlockVersion), b.Version())
+	require.Nil(t, b.VerifyHeader(genesis))
+
+	//A block in the genesis format commits to its receipts in a legacy tree, its body still verifies against that
+	//tree but no block after genesis may use the format
+	tt := structures.NewTransactionTree(structures.LegacyTree)
+	for _, r := range receipts {
+		require.Nil(t, tt.Add(r.Hash()))
+	}
+	require.Nil(t, tt.Construct())
+	root := tt.Root()
+	msg := b.ConvertToBlockPbMsg()
+	msg.Version, msg.ReceiptsRoot = 0, root[:]
+	old := core.NewBlockFromPbMsg(msg)
+	require.NotNil(t, old.VerifyHeader(genesis))
+	_, err := old.VerifyBody(core.NewState(alloc))
+	require.Nil(t, err)
+	//The same block claiming the current version has to use the current tree
+	msg.Version = core.BlockVersion
+	_, err = core.NewBlockFromPbMsg(msg).VerifyBody(core.NewState(alloc))
+	require.NotNil(t, err)
+
+	//Every block after genesis is in the current version, whatever its parent's version
+	child := core.NewBlock(b.ID, 2, nil)
+	child.Finalize(core.NewState(alloc))
+	msg = child.ConvertToBlockPbMsg()
+	msg.Version = 0
+	require.NotNil(t, core.NewBlockFromPbMsg(msg).VerifyHeader(b))
+	msg.Version = core.BlockVersion + 1
+	require.NotNil(t, core.NewBlockFromPbMsg(msg).VerifyHeader(b))
+}
@@ -0,0 +1,167 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/services"
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/p2p"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitBan(t *testing.T) {
+	a, _ := startRecorderNode(t, p2p.TxCap)
+	b, _ := startRecorderNode(t, p2p.TxCap)
+	p, err := b.Dial(a.Addr())
+	require.Nil(t, err)
+
+	//Flood a with announcements far faster than it allows until it bans us
+	flood := &types.PeerMsg{Payload: &types.PeerMsg_TxAnnounce{TxAnnounce: &types.TxAnnounce{}}}
+	waitFor(t, func() bool {
+		for i := 0; i < 50; i++ {
+			p.Send(flood)
+		}
+		select {
+		case <-p.Closed():
+			return true
+		default:
+			return false
+		}
+	})
+	waitFor(t, func() bool { return a.PeerCount() == 0 })
+
+	admin := services.NewAdminService(a)
+	res, err := admin.ListPeers(context.Background(), &types.ListPeersRequest{})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(res.GetBanned()))
+	banned := res.GetBanned()[0]
+	id := b.NodeID()
+	require.Equal(t, id[:], banned.GetNodeID())
+	require.Equal(t, uint32(1), banned.GetBans())
+	//b dialed in, so the listen address it advertised was never confirmed and has no ban of its own
+	rec, _ := a.PeerBook().Get(b.Addr())
+	require.True(t, rec.BannedUntil.IsZero())
+
+	_, err = b.Dial(a.Addr())
+	require.Equal(t, &p2p.DisconnectError{Reason: types.DisconnectReason_BANNED}, err)
+
+	unban, err := admin.Unban(context.Background(), &types.UnbanRequest{Addr: banned.GetAddr()})
+	require.Nil(t, err)
+	require.Equal(t, types.Status_SUCCESS, unban.GetStatus())
+	_, err = b.Dial(a.Addr())
+	require.Nil(t, err)
+}
+
+func TestHostBans(t *testing.T) {
+	//Floods a from a new node until it bans it
+	ban := func(a *p2p.Server) {
+		b, _ := startRecorderNode(t, p2p.TxCap)
+		p, err := b.Dial(a.Addr())
+		require.Nil(t, err)
+		flood := &types.PeerMsg{Payload: &types.PeerMsg_TxAnnounce{TxAnnounce: &types.TxAnnounce{}}}
+		waitFor(t, func() bool {
+			for i := 0; i < 50; i++ {
+				p.Send(flood)
+			}
+			select {
+			case <-p.Closed():
+				return true
+			default:
+				return false
+			}
+		})
+		waitFor(t, func() bool { return a.PeerCount() == 0 })
+		require.Equal(t, 1, len(a.PeerBook().BannedRecords()))
+	}
+
+	//Every node of a devnet shares 127.0.0.1, the honest ones still connect to a either way once b is banned
+	a, _ := startRecorderNode(t, p2p.TxCap)
+	ban(a)
+	c, _ := startRecorderNode(t, p2p.TxCap)
+	_, err := c.Dial(a.Addr())
+	require.Nil(t, err)
+	d, _ := startRecorderNode(t, p2p.TxCap)
+	_, err = a.Dial(d.Addr())
+	require.Nil(t, err)
+
+	//With host bans on, nothing else can dial in from b's host, though a can still dial out to it
+	a = startNodeConfig(t, p2p.Config{BanHosts: true}, newP2PChain(1), &recorder{caps: []p2p.Capability{p2p.TxCap}})
+	ban(a)
+	_, err = c.Dial(a.Addr())
+	require.Equal(t, &p2p.DisconnectError{Reason: types.DisconnectReason_BANNED}, err)
+	_, err = a.Dial(d.Addr())
+	require.Nil(t, err)
+}
+
+func TestInvalidBlockBan(t *testing.T) {
+	a, ca := startBlockNode(t)
+	b, _ := startRecorderNode(t, p2p.BlocksCap)
+	p, err := b.Dial(a.Addr())
+	require.Nil(t, err)
+
+	//A block on top of genesis whose receipts root commits to nothing it executed
+	block := core.NewBlock(ca.LastBlock().ID, 1, nil)
+	msg := block.ConvertToBlockPbMsg()
+	msg.ReceiptsRoot = []byte{1}
+	require.Nil(t, p.Send(&types.PeerMsg{Payload: &types.PeerMsg_NewBlock{NewBlock: msg}}))
+
+	//One invalid block is enough
+	<-p.Closed()
+	require.True(t, a.PeerBook().Banned(b.Addr(), b.NodeID()))
+	require.Equal(t, uint64(0), ca.LastBlock().Height())
+}
+
+func TestPeerBookBans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peers.json")
+	book, err := p2p.NewPeerBook(path)
+	require.Nil(t, err)
+	book.Add("10.0.0.1:30303")
+	book.Add("10.0.0.2:30303")
+	id := [32]byte{7}
+
+	//Each ban lasts twice as long as the last
+	first := book.Ban(id, "10.0.0.1:30303", "10.0.0.1:30303")
+	require.True(t, book.Unban("10.0.0.1:30303"))
+	require.Equal(t, 2*first, book.Ban(id, "10.0.0.1:30303", "10.0.0.1:30303"))
+	require.Equal(t, []string{"10.0.0.2:30303"}, book.Addresses(10))
+
+	//The node ID is banned under any address
+	require.True(t, book.Banned("10.0.0.3:30303", id))
+	require.False(t, book.Banned("10.0.0.3:30303", [32]byte{8}))
+
+	//A peer that dialed in is banned by its node ID and host, never by the address it claims to listen on
+	other := [32]byte{9}
+	book.Ban(other, "10.0.0.9:51234", "")
+	require.True(t, book.Banned("10.0.0.9:40000", other))
+	require.False(t, book.Banned("10.0.0.9:40000", [32]byte{10}))
+	require.True(t, book.BannedHost("10.0.0.9:40000"))
+	require.False(t, book.BannedHost("10.0.0.2:30303"))
+	require.False(t, book.Banned("10.0.0.2:30303", [32]byte{}))
+	require.Equal(t, []string{"10.0.0.2:30303"}, book.Addresses(10))
+
+	require.Nil(t, book.Save())
+	reloaded, err := p2p.NewPeerBook(path)
+	require.Nil(t, err)
+	require.True(t, reloaded.Banned("10.0.0.1:30303", [32]byte{}))
+	require.True(t, reloaded.Banned("10.0.0.9:40000", other))
+	rec, _ := reloaded.Get("10.0.0.1:30303")
+	require.Equal(t, 2, rec.Bans)
+	require.True(t, rec.BannedUntil.After(time.Now().Add(first)))
+}
+
+func TestPeerBookBanCap(t *testing.T) {
+	book, err := p2p.NewPeerBook("")
+	require.Nil(t, err)
+	//Every ban is of a different node on a different host, past the cap the one closest to running out makes room
+	for i := 0; i < 1100; i++ {
+		book.Ban([32]byte{byte(i), byte(i >> 8)}, fmt.Sprintf("10.%d.%d.1:30303", i>>8, i&0xff), "")
+	}
+	require.Equal(t, 1000, book.Len())
+	require.True(t, book.BannedHost("10.4.75.1:30303"))
+	require.False(t, book.BannedHost("10.0.0.1:30303"))
+}
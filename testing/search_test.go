@@ -0,0 +1,168 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/jsonrpc"
+	"github.com/liangalv/goChain/core/services"
+	"github.com/liangalv/goChain/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// searchChain is a fakeChain that keeps the last search it was asked for and answers every search with err, or the
+// logs and transactions it holds
+type searchChain struct {
+	*fakeChain
+	filter   *core.LogFilter
+	address  [core.AddressLength]byte
+	from, to uint64
+	logs     []*core.FilteredLog
+	txIDs    [][32]byte
+	err      error
+}
+
+func (sc *searchChain) GetLogs(filter *core.LogFilter) ([]*core.FilteredLog, error) {
+	sc.filter = filter
+	return sc.logs, sc.err
+}
+
+func (sc *searchChain) FindTransactions(address [core.AddressLength]byte, from, to uint64) ([][32]byte, error) {
+	sc.address, sc.from, sc.to = address, from, to
+	return sc.txIDs, sc.err
+}
+
+func TestGetLogsService(t *testing.T) {
+	sc := &searchChain{fakeChain: newFakeChain(nil)}
+	bs := services.NewBlockService(sc)
+	address := [core.AddressLength]byte{1}
+	t1, t2, t3 := [32]byte{1}, [32]byte{2}, [32]byte{3}
+	sc.logs = []*core.FilteredLog{{Log: &core.Log{Address: address, Topics: [][32]byte{t1}}, Height: 4, TxIndex: 1}}
+
+	//Topic positions reach the chain in order, an empty position included
+	res, err := bs.GetLogs(context.Background(), &types.GetLogsRequest{
+		From:      2,
+		To:        9,
+		Addresses: [][]byte{address[:]},
+		Topics:    []*types.TopicSet{{Topics: [][]byte{t1[:]}}, {}, {Topics: [][]byte{t2[:], t3[:]}}},
+	})
+	require.Nil(t, err)
+	require.Equal(t, types.Status_SUCCESS, res.GetStatus())
+	require.Equal(t, &core.LogFilter{
+		From:      2,
+		To:        9,
+		Addresses: [][core.AddressLength]byte{address},
+		Topics:    [][][32]byte{{t1}, nil, {t2, t3}},
+	}, sc.filter)
+	require.Len(t, res.GetLogs(), 1)
+	require.Equal(t, uint64(4), res.GetLogs()[0].GetHeight())
+	require.Equal(t, address[:], res.GetLogs()[0].GetLog().GetAddress())
+
+	invalid := []*types.GetLogsRequest{
+		{From: 5, To: 4},
+		{Addresses: [][]byte{{1, 2}}},
+		{Topics: []*types.TopicSet{{Topics: [][]byte{{1}}}}},
+	}
+	for _, req := range invalid {
+		sc.filter = nil
+		res, err := bs.GetLogs(context.Background(), req)
+		require.Nil(t, err)
+		require.Equal(t, types.Status_INVALID_REQUEST, res.GetStatus())
+		require.Nil(t, sc.filter)
+	}
+
+	//A range with too many logs has to be narrowed by the caller, anything else is the node's failure
+	sc.err = core.ErrTooManyResults
+	res, _ = bs.GetLogs(context.Background(), &types.GetLogsRequest{To: 9})
+	require.Equal(t, types.Status_INVALID_REQUEST, res.GetStatus())
+	sc.err = errors.New("disk on fire")
+	res, _ = bs.GetLogs(context.Background(), &types.GetLogsRequest{To: 9})
+	require.Equal(t, types.Status_FAILURE, res.GetStatus())
+}
+
+func TestFindTransactionsService(t *testing.T) {
+	sc := &searchChain{fakeChain: newFakeChain(nil), txIDs: [][32]byte{{7}, {8}}}
+	as := services.NewAccountService(sc)
+	address := [core.AddressLength]byte{1}
+
+	res, err := as.FindTransactions(context.Background(), &types.FindTransactionsRequest{Address: address[:], From: 3, To: 6})
+	require.Nil(t, err)
+	require.Equal(t, types.Status_SUCCESS, res.GetStatus())
+	require.Equal(t, [][]byte{sc.txIDs[0][:], sc.txIDs[1][:]}, res.GetTxIDs())
+	require.Equal(t, address, sc.address)
+	require.Equal(t, uint64(3), sc.from)
+	require.Equal(t, uint64(6), sc.to)
+
+	for _, req := range []*types.FindTransactionsRequest{
+		{Address: address[:], From: 6, To: 3},
+		{Address: []byte{1}, To: 3},
+	} {
+		res, err := as.FindTransactions(context.Background(), req)
+		require.Nil(t, err)
+		require.Equal(t, types.Status_INVALID_REQUEST, res.GetStatus())
+	}
+
+	sc.err = core.ErrTooManyResults
+	res, _ = as.FindTransactions(context.Background(), &types.FindTransactionsRequest{Address: address[:], To: 6})
+	require.Equal(t, types.Status_INVALID_REQUEST, res.GetStatus())
+	sc.err = errors.New("disk on fire")
+	res, _ = as.FindTransactions(context.Background(), &types.FindTransactionsRequest{Address: address[:], To: 6})
+	require.Equal(t, types.Status_FAILURE, res.GetStatus())
+}
+
+func TestJSONRPCSearch(t *testing.T) {
+	sc := &searchChain{fakeChain: newFakeChain(nil), txIDs: [][32]byte{{7}}}
+	srv := newGateway(sc)
+	defer srv.Close()
+	call := func(method, params string) (json.RawMessage, *jsonrpc.Error) {
+		res := postJSON(t, srv.URL, fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":%q,"params":%s}`, method, params))
+		defer res.Body.Close()
+		var out struct {
+			Result json.RawMessage `json:"result"`
+			Error  *jsonrpc.Error  `json:"error"`
+		}
+		require.Nil(t, json.NewDecoder(res.Body).Decode(&out))
+		return out.Result, out.Error
+	}
+	address := "0x0100000000000000000000000000000000000000"
+	t1 := "0x0100000000000000000000000000000000000000000000000000000000000000"
+	t2 := "0x0200000000000000000000000000000000000000000000000000000000000000"
+
+	//Each inner list is a topic position, an empty one matching anything
+	result, rpcErr := call("chain_getLogs", fmt.Sprintf(`[{"fromBlock":1,"toBlock":3,"addresses":[%q],"topics":[[],[%q,%q]]}]`, address, t1, t2))
+	require.Nil(t, rpcErr)
+	require.Equal(t, "[]", string(result))
+	require.Equal(t, [][][32]byte{nil, {{1}, {2}}}, sc.filter.Topics)
+	require.Equal(t, [][core.AddressLength]byte{{1}}, sc.filter.Addresses)
+	require.Equal(t, uint64(1), sc.filter.From)
+	require.Equal(t, uint64(3), sc.filter.To)
+
+	result, rpcErr = call("account_findTransactions", fmt.Sprintf(`[%q, 2, 5]`, address))
+	require.Nil(t, rpcErr)
+	require.Equal(t, `["0x0700000000000000000000000000000000000000000000000000000000000000"]`, string(result))
+
+	//Malformed params and requests the services turn down are both invalid params
+	invalid := []struct{ method, params string }{
+		{"chain_getLogs", `[{"topics":[["01"]]}]`},
+		{"chain_getLogs", `[{"topics":[["0x01"]]}]`},
+		{"chain_getLogs", `[{"fromBlock":5,"toBlock":4}]`},
+		{"account_findTransactions", fmt.Sprintf(`[%q, 2]`, address)},
+		{"account_findTransactions", fmt.Sprintf(`[%q, 5, 2]`, address)},
+	}
+	for _, c := range invalid {
+		_, rpcErr := call(c.method, c.params)
+		require.NotNil(t, rpcErr, c.params)
+		require.Equal(t, -32602, rpcErr.Code, c.params)
+	}
+
+	//So is a range holding more results than a node returns at once
+	sc.err = core.ErrTooManyResults
+	_, rpcErr = call("chain_getLogs", `[{"toBlock":9}]`)
+	require.Equal(t, -32602, rpcErr.Code)
+	_, rpcErr = call("account_findTransactions", fmt.Sprintf(`[%q, 0, 9]`, address))
+	require.Equal(t, -32602, rpcErr.Code)
+}
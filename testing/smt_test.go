@@ -0,0 +1,97 @@
+package core_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/structures"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestSparseMerkleTree(t *testing.T) {
+	keys := make([][32]byte, 50)
+	for i := range keys {
+		keys[i] = sha3.Sum256([]byte{byte(i)})
+	}
+	value := func(i int) [32]byte { return sha3.Sum256([]byte{byte(i), 1}) }
+
+	//The root only depends on the contents, not the order they were written in
+	a := structures.NewSparseMerkleTree(structures.NewMemNodeStore(), [32]byte{})
+	b := structures.NewSparseMerkleTree(core.NewMemBlockStore().Nodes(), [32]byte{})
+	for i := range keys {
+		require.Nil(t, a.Update(keys[i], value(i)))
+	}
+	for _, i := range rand.New(rand.NewSource(1)).Perm(len(keys)) {
+		require.Nil(t, b.Update(keys[i], [32]byte{9}))
+		require.Nil(t, b.Update(keys[i], value(i)))
+	}
+	require.Equal(t, a.Root(), b.Root())
+	for i := range keys {
+		got, err := b.Get(keys[i])
+		require.Nil(t, err)
+		require.Equal(t, value(i), got)
+	}
+
+	//Inclusion and non inclusion proofs
+	root := a.Root()
+	proof, err := a.ConstructProof(keys[7])
+	require.Nil(t, err)
+	require.True(t, structures.VerifySparseProof(root, keys[7], value(7), proof))
+	require.False(t, structures.VerifySparseProof(root, keys[7], value(8), proof))
+	require.False(t, structures.VerifySparseProof(root, keys[7], [32]byte{}, proof))
+	absent := sha3.Sum256([]byte("absent"))
+	proof, err = a.ConstructProof(absent)
+	require.Nil(t, err)
+	require.True(t, structures.VerifySparseProof(root, absent, [32]byte{}, proof))
+	require.False(t, structures.VerifySparseProof(root, absent, value(0), proof))
+
+	//Older roots stay readable, and deleting everything again leaves the empty tree
+	old := a.Copy()
+	for i := range keys {
+		require.Nil(t, a.Update(keys[i], [32]byte{}))
+	}
+	require.Equal(t, [32]byte{}, a.Root())
+	got, err := old.Get(keys[3])
+	require.Nil(t, err)
+	require.Equal(t, value(3), got)
+
+	//Used as a set through the merkleTree interface
+	set := structures.NewSparseMerkleTree(structures.NewMemNodeStore(), [32]byte{})
+	require.Nil(t, set.Add(keys[0]))
+	require.NotNil(t, set.Add(keys[0]))
+	proof, err = set.ConstructProof(keys[0])
+	require.Nil(t, err)
+	require.True(t, set.VerifyProof(keys[0], proof.Siblings))
+	require.Nil(t, set.Delete(keys[0]))
+	require.NotNil(t, set.Delete(keys[0]))
+	require.False(t, set.VerifyProof(keys[0], proof.Siblings))
+}
+
+func TestSparseRangeProof(t *testing.T) {
+	tree := structures.NewSparseMerkleTree(structures.NewMemNodeStore(), [32]byte{})
+	for i := 0; i < 20; i++ {
+		require.Nil(t, tree.Update(sha3.Sum256([]byte{byte(i)}), [32]byte{byte(i + 1)}))
+	}
+	r, err := tree.ConstructRangeProof([32]byte{0x40}, 6)
+	require.Nil(t, err)
+	require.Equal(t, 6, len(r.Keys))
+	require.False(t, r.Last)
+	root, err := r.Root()
+	require.Nil(t, err)
+	require.Equal(t, tree.Root(), root)
+
+	//Leaving out a leaf the proof doesn't cover
+	r.Keys, r.Values = r.Keys[1:], r.Values[1:]
+	root, err = r.Root()
+	require.True(t, err != nil || root != tree.Root())
+
+	//A range from past the last key proves the tree has nothing more
+	r, err = tree.ConstructRangeProof([32]byte{0xff, 0xff, 0xff}, 6)
+	require.Nil(t, err)
+	require.True(t, r.Last)
+	root, err = r.Root()
+	require.Nil(t, err)
+	require.Equal(t, tree.Root(), root)
+}
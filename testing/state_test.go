@@ -0,0 +1,101 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountRangeProof(t *testing.T) {
+	alloc := map[[core.AddressLength]byte]uint64{}
+	for i := 0; i < 37; i++ {
+		var address [core.AddressLength]byte
+		address[0], address[1] = byte(i*7), byte(i)
+		alloc[address] = uint64(i + 1)
+	}
+	state := core.NewState(alloc)
+	root := state.Root()
+
+	//Every way of cutting the state into ranges proves against the root and covers every account once
+	for _, max := range []int{1, 2, 5, 16, 37, 100} {
+		seen := map[[core.AddressLength]byte]bool{}
+		for origin, more := [32]byte{}, true; more; {
+			r, err := state.AccountRange(origin, max)
+			require.Nil(t, err)
+			require.Equal(t, uint64(37), r.Total)
+			require.Nil(t, r.Verify(root), "origin %x max %d", origin[:4], max)
+			for _, a := range r.Accounts {
+				require.False(t, seen[a.Address])
+				seen[a.Address] = true
+				require.Equal(t, alloc[a.Address], a.Balance)
+			}
+			origin, more = r.Next()
+		}
+		require.Equal(t, 37, len(seen))
+	}
+
+	r, err := state.AccountRange([32]byte{}, 8)
+	require.Nil(t, err)
+	tampered := *r
+	tampered.Accounts = append([]core.StateAccount{}, r.Accounts...)
+	tampered.Accounts[3].Balance++
+	require.NotNil(t, tampered.Verify(root))
+	//Leaving an account out of the middle of a range
+	tampered.Accounts = append(append([]core.StateAccount{}, r.Accounts[:3]...), r.Accounts[4:]...)
+	require.NotNil(t, tampered.Verify(root))
+	//Claiming the range runs to the end of the state
+	tampered = *r
+	tampered.Last = true
+	require.NotNil(t, tampered.Verify(root))
+	//Claiming the range starts after its first account
+	tampered = *r
+	tampered.Origin = core.AccountKey(r.Accounts[0].Address)
+	tampered.Origin[31]++
+	require.NotNil(t, tampered.Verify(root))
+
+	//The range survives the wire
+	decoded, err := core.NewAccountRangeFromPbMsg(r.ConvertToAccountRangePbMsg())
+	require.Nil(t, err)
+	require.Nil(t, decoded.Verify(root))
+
+	empty := core.NewState(nil)
+	r, err = empty.AccountRange([32]byte{}, 10)
+	require.Nil(t, err)
+	require.Nil(t, r.Verify(empty.Root()))
+	require.NotNil(t, r.Verify(root))
+}
+
+func TestAccountProof(t *testing.T) {
+	var alice, bob, carol [core.AddressLength]byte
+	alice[0], bob[0], carol[0] = 1, 2, 3
+	state := core.NewState(map[[core.AddressLength]byte]uint64{alice: 100})
+	b := core.NewBlock([32]byte{}, 1, []*core.Transaction{
+		core.NewTransaction(0, 40, core.TxGas, alice, bob),
+		core.NewTransaction(1, 0, core.TxGas, alice, carol),
+	})
+	b.Execute(state)
+	root := state.Root()
+	//The root only depends on the accounts, not how the state got there
+	reordered := core.NewState(map[[core.AddressLength]byte]uint64{carol: 0, bob: 40, alice: 60})
+	require.Equal(t, root, reordered.Root())
+
+	proof, err := state.AccountProof(bob)
+	require.Nil(t, err)
+	require.True(t, core.VerifyAccountProof(root, core.StateAccount{Address: bob, Balance: 40}, proof))
+	require.False(t, core.VerifyAccountProof(root, core.StateAccount{Address: bob, Balance: 41}, proof))
+	require.False(t, core.VerifyAbsenceProof(root, bob, proof))
+
+	//An account with nothing in it is still an account
+	proof, err = state.AccountProof(carol)
+	require.Nil(t, err)
+	require.True(t, core.VerifyAccountProof(root, core.StateAccount{Address: carol}, proof))
+	require.False(t, core.VerifyAbsenceProof(root, carol, proof))
+
+	var dave [core.AddressLength]byte
+	dave[0] = 4
+	proof, err = state.AccountProof(dave)
+	require.Nil(t, err)
+	require.True(t, core.VerifyAbsenceProof(root, dave, proof))
+	require.False(t, core.VerifyAccountProof(root, core.StateAccount{Address: dave}, proof))
+}
@@ -0,0 +1,100 @@
+package core_test
+
+import (
+	"github.com/liangalv/goChain/core"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStoreIndexes(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	store := core.NewMemBlockStore()
+	defer store.Close()
+
+	genesis := core.NewBlock([32]byte{}, 0, nil)
+	require.Nil(t, store.CommitBlock(genesis, genesis.Finalize(core.NewState(nil))))
+	trans := core.NewTransaction(0, 10, core.TxGas, alice, bob)
+	b1 := core.NewBlock(genesis.ID, 1, []*core.Transaction{trans})
+	require.Nil(t, store.CommitBlock(b1, b1.Finalize(core.NewState(nil))))
+
+	lookup, err := store.ReadTxLookup(trans.ID)
+	require.Nil(t, err)
+	require.Equal(t, &core.TxLookup{BlockHash: b1.ID, Index: 0}, lookup)
+	hash, err := store.ReadCanonicalHash(1)
+	require.Nil(t, err)
+	require.Equal(t, b1.ID, hash)
+	history, err := store.ReadAddressHistory(bob, 1)
+	require.Nil(t, err)
+	require.Equal(t, [][32]byte{trans.ID}, history)
+	_, err = store.ReadAddressHistory(bob, 0)
+	require.Equal(t, core.ErrTooManyResults, err)
+	read, err := store.ReadBlock(b1.ID)
+	require.Nil(t, err)
+	require.Equal(t, b1.ID, read.ID)
+	require.Equal(t, trans.ID, read.Transactions()[0].ID)
+}
+
+func TestStoreReorg(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	store := core.NewMemBlockStore()
+	defer store.Close()
+
+	genesis := core.NewBlock([32]byte{}, 0, nil)
+	require.Nil(t, store.CommitBlock(genesis, genesis.Finalize(core.NewState(nil))))
+	stale := core.NewTransaction(0, 10, core.TxGas, alice, bob)
+	old := core.NewBlock(genesis.ID, 1, []*core.Transaction{stale})
+	require.Nil(t, store.CommitBlock(old, old.Finalize(core.NewState(nil))))
+
+	fresh := core.NewTransaction(1, 20, core.TxGas, bob, alice)
+	side := core.NewBlock(genesis.ID, 1, []*core.Transaction{fresh})
+	require.Nil(t, store.WriteBlock(side, side.Finalize(core.NewState(nil))))
+	require.Nil(t, store.Reorg([]*core.Block{old}, []*core.Block{side}))
+
+	_, err := store.ReadTxLookup(stale.ID)
+	require.Equal(t, core.ErrNotFound, err)
+	lookup, err := store.ReadTxLookup(fresh.ID)
+	require.Nil(t, err)
+	require.Equal(t, side.ID, lookup.BlockHash)
+	hash, err := store.ReadCanonicalHash(1)
+	require.Nil(t, err)
+	require.Equal(t, side.ID, hash)
+	head, err := store.ReadHeadHash()
+	require.Nil(t, err)
+	require.Equal(t, side.ID, head)
+	history, err := store.ReadAddressHistory(alice, 10)
+	require.Nil(t, err)
+	require.Equal(t, [][32]byte{fresh.ID}, history)
+}
+
+func TestStoreSnapshot(t *testing.T) {
+	var alice, bob [core.AddressLength]byte
+	alice[0], bob[0] = 1, 2
+	store := core.NewMemBlockStore()
+	defer store.Close()
+
+	_, _, err := store.ReadSnapshot()
+	require.Equal(t, core.ErrNotFound, err)
+
+	genesis := core.NewBlock([32]byte{}, 0, nil)
+	require.Nil(t, store.CommitBlock(genesis, genesis.Finalize(core.NewState(nil))))
+	state := core.NewState(map[[core.AddressLength]byte]uint64{alice: 90, bob: 10})
+	pivot := core.NewBlock(genesis.ID, 1, []*core.Transaction{core.NewTransaction(0, 10, core.TxGas, alice, bob)})
+	pivot.Finalize(core.NewState(map[[core.AddressLength]byte]uint64{alice: 100}))
+	require.Nil(t, store.CommitSnapshot([]*core.Block{pivot}, state))
+
+	hash, read, err := store.ReadSnapshot()
+	require.Nil(t, err)
+	require.Equal(t, pivot.ID, hash)
+	require.Equal(t, state.Root(), read.Root())
+	require.Equal(t, pivot.StateRoot(), read.Root())
+	head, err := store.ReadHeadHash()
+	require.Nil(t, err)
+	require.Equal(t, pivot.ID, head)
+	//The header is stored without its body
+	header, err := store.ReadBlock(pivot.ID)
+	require.Nil(t, err)
+	require.Equal(t, pivot.ID, header.ID)
+	require.Empty(t, header.Transactions())
+}
@@ -0,0 +1,189 @@
+package core_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/core/types"
+	"github.com/liangalv/goChain/p2p"
+	"github.com/stretchr/testify/require"
+)
+
+// snapChain lets a devChain serve and adopt state for snap sync, states are served by replaying the chain from genesis
+type snapChain struct {
+	*devChain
+	snapMux  sync.Mutex
+	snapshot uint64
+}
+
+func (sc *snapChain) AccountRange(root [32]byte, origin [32]byte, max int) (*core.AccountRange, error) {
+	sc.mux.Lock()
+	defer sc.mux.Unlock()
+	state := core.NewState(nil)
+	for _, b := range sc.canon {
+		b.Execute(state)
+		if b.StateRoot() == root {
+			return state.AccountRange(origin, max)
+		}
+	}
+	return nil, core.ErrNotFound
+}
+
+func (sc *snapChain) InsertSnapshot(headers []*core.Block, state *core.State) error {
+	sc.mux.Lock()
+	defer sc.mux.Unlock()
+	for _, h := range headers {
+		sc.blocks[h.ID] = h
+	}
+	sc.canon = append(sc.canon[:1], headers...)
+	sc.state = state
+	sc.snapMux.Lock()
+	sc.snapshot = headers[len(headers)-1].Height()
+	sc.snapMux.Unlock()
+	return nil
+}
+
+func (sc *snapChain) SnapshotHeight() uint64 {
+	return sc.snapshotHeight()
+}
+
+func (sc *snapChain) snapshotHeight() uint64 {
+	sc.snapMux.Lock()
+	defer sc.snapMux.Unlock()
+	return sc.snapshot
+}
+
+func TestInitialBlockDownload(t *testing.T) {
+	var alice [core.AddressLength]byte
+	alice[0] = 1
+	source := newDevChain()
+	for i := 0; i < 450; i++ {
+		var trans []*core.Transaction
+		if i%3 == 0 {
+			trans = append(trans, core.NewTransaction(0, 0, core.TxGas, alice, [core.AddressLength]byte{byte(i)}))
+		}
+		source.produce(trans)
+	}
+	a := startNode(t, source, p2p.NewSyncer(source))
+
+	//Spans several header batches, bodies come from both a and b once b has caught up
+	fresh := newDevChain()
+	syncer := p2p.NewSyncer(fresh)
+	b := startNode(t, fresh, syncer)
+	_, err := b.Dial(a.Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return fresh.LastBlock().ID == source.LastBlock().ID })
+	waitFor(t, func() bool { return !syncer.Progress().Syncing })
+	progress := syncer.Progress()
+	require.Equal(t, uint64(450), progress.HighestHeight)
+	require.Equal(t, uint64(450), progress.CurrentHeight)
+
+	//A node that already has part of the chain, as after a restart mid sync, only downloads the rest
+	partial := newDevChain()
+	for h := uint64(1); h <= 200; h++ {
+		blk, err := source.GetBlockByHeight(h)
+		require.Nil(t, err)
+		require.Nil(t, partial.InsertBlock(blk))
+	}
+	resumed := p2p.NewSyncer(partial)
+	c := startNode(t, partial, resumed)
+	_, err = c.Dial(a.Addr())
+	require.Nil(t, err)
+	_, err = c.Dial(b.Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return partial.LastBlock().ID == source.LastBlock().ID })
+	waitFor(t, func() bool { return !resumed.Progress().Syncing })
+	require.Equal(t, uint64(200), resumed.Progress().StartingHeight)
+}
+
+// liarChain advertises far more total difficulty than the blocks it can serve
+type liarChain struct {
+	*devChain
+}
+
+func (lc *liarChain) TotalDifficulty() uint64 { return 1000 }
+
+func TestSyncSkipsPeerWithoutHeaders(t *testing.T) {
+	source := newDevChain()
+	for i := 0; i < 10; i++ {
+		source.produce(nil)
+	}
+	a := startNode(t, source, p2p.NewSyncer(source))
+	liar := &liarChain{devChain: newDevChain()}
+	l := startNode(t, liar, p2p.NewSyncer(liar))
+
+	//The liar is the best peer but serves nothing, so it is penalized and the sync carries on from a
+	fresh := newDevChain()
+	b := startNode(t, fresh, p2p.NewSyncer(fresh))
+	_, err := b.Dial(l.Addr())
+	require.Nil(t, err)
+	_, err = b.Dial(a.Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return fresh.LastBlock().ID == source.LastBlock().ID })
+	for _, p := range b.Peers() {
+		if p.ID() == l.NodeID() {
+			require.True(t, p.Score() < 0)
+			require.True(t, p.TotalDifficulty() < liar.TotalDifficulty())
+		}
+	}
+}
+
+func TestSnapSync(t *testing.T) {
+	var alice [core.AddressLength]byte
+	alice[0] = 1
+	source := &snapChain{devChain: newDevChain()}
+	for i := 0; i < 300; i++ {
+		var trans []*core.Transaction
+		for j := 0; j < 2; j++ {
+			var receiver [core.AddressLength]byte
+			receiver[0], receiver[1], receiver[2] = byte(i), byte(i>>8), byte(j)+2
+			trans = append(trans, core.NewTransaction(j, 0, core.TxGas, alice, receiver))
+		}
+		source.produce(trans)
+	}
+	a := startNode(t, source, p2p.NewSnapSyncer(source))
+
+	fresh := &snapChain{devChain: newDevChain()}
+	syncer := p2p.NewSnapSyncer(fresh)
+	b := startNode(t, fresh, syncer)
+	_, err := b.Dial(a.Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return fresh.LastBlock().ID == source.LastBlock().ID })
+	waitFor(t, func() bool { return !syncer.Progress().Syncing })
+
+	//The state was downloaded at the pivot in several ranges, and the blocks above it imported on top of it
+	require.Equal(t, uint64(300-64), fresh.SnapshotHeight())
+	progress := syncer.Progress()
+	//alice and two receivers per block up to the pivot
+	require.Equal(t, uint64(1+2*(300-64)), progress.TotalAccounts)
+	require.Equal(t, progress.TotalAccounts, progress.SyncedAccounts)
+	require.Equal(t, source.state.Root(), fresh.state.Root())
+}
+
+func TestAnnounceStartsSync(t *testing.T) {
+	//The Syncer's own check first runs 2s after it starts, anything sooner was set off by the announcement
+	start := time.Now()
+	dc := newDevChain()
+	a := startNode(t, dc, p2p.NewBlockGossip(dc, dc, dc.events), p2p.NewSyncer(dc))
+	b, rec := startRecorderNode(t, p2p.BlocksCap, p2p.SyncCap)
+	p, err := b.Dial(a.Addr())
+	require.Nil(t, err)
+	waitFor(t, func() bool { return a.PeerCount() == 1 })
+
+	announce := &types.BlockAnnounce{Hash: []byte{31: 1}, Height: 50, TotalDifficulty: 1 << 20}
+	require.Nil(t, p.Send(&types.PeerMsg{Payload: &types.PeerMsg_BlockAnnounce{BlockAnnounce: announce}}))
+	requested := func() bool {
+		for _, msg := range rec.received() {
+			if msg.GetHeadersRequest() != nil {
+				return true
+			}
+		}
+		return false
+	}
+	for !requested() {
+		require.True(t, time.Since(start) < 1500*time.Millisecond, "sync waited for the ticker")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
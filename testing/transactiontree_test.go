@@ -0,0 +1,186 @@
+package core_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/liangalv/goChain/structures"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestTransactionTreeIncremental(t *testing.T) {
+	for _, mode := range []structures.TreeMode{structures.LegacyTree, structures.SeparatedTree} {
+		testTransactionTreeIncremental(t, mode)
+	}
+}
+
+func testTransactionTreeIncremental(t *testing.T, mode structures.TreeMode) {
+	r := rand.New(rand.NewSource(1))
+	tt := structures.NewTransactionTree(mode)
+	//Leaves in tree order, a delete keeps the order of the rest
+	var leaves [][32]byte
+	for i := 0; i < 300; i++ {
+		if len(leaves) > 0 && r.Intn(3) == 0 {
+			ind := r.Intn(len(leaves))
+			require.Nil(t, tt.SafeDelete(leaves[ind]))
+			leaves = append(leaves[:ind], leaves[ind+1:]...)
+		} else {
+			leaf := sha3.Sum256([]byte(fmt.Sprint(i)))
+			require.Nil(t, tt.SafeAdd(leaf))
+			require.NotNil(t, tt.SafeAdd(leaf))
+			leaves = append(leaves, leaf)
+		}
+		if len(leaves) == 0 {
+			continue
+		}
+		//The patched tree matches one built from scratch, and its levels still prove leaves
+		fresh := structures.NewTransactionTree(mode)
+		for _, leaf := range leaves {
+			require.Nil(t, fresh.Add(leaf))
+		}
+		require.Nil(t, fresh.Construct())
+		require.Equal(t, fresh.Root(), tt.Root(), "mode %d step %d", mode, i)
+		proven := [][32]byte{leaves[0], leaves[len(leaves)/2], leaves[len(leaves)-1]}
+		if len(leaves) < 3 {
+			proven = leaves
+		}
+		proof, err := tt.ConstructMultiProof(proven)
+		require.Nil(t, err)
+		require.True(t, structures.VerifyMultiProof(mode, tt.Root(), proven, proof))
+	}
+	require.NotNil(t, tt.SafeDelete(sha3.Sum256([]byte("missing"))))
+}
+
+func TestTransactionTreeDeleteOrder(t *testing.T) {
+	a, b, c, d := sha3.Sum256([]byte("a")), sha3.Sum256([]byte("b")), sha3.Sum256([]byte("c")), sha3.Sum256([]byte("d"))
+	root := func(leaves ...[32]byte) [32]byte {
+		tt := structures.NewTransactionTree(structures.SeparatedTree)
+		for _, leaf := range leaves {
+			require.Nil(t, tt.Add(leaf))
+		}
+		require.Nil(t, tt.Construct())
+		return tt.Root()
+	}
+	build := func() *structures.TransactionTree {
+		tt := structures.NewTransactionTree(structures.SeparatedTree)
+		for _, leaf := range [][32]byte{a, b, c, d} {
+			require.Nil(t, tt.SafeAdd(leaf))
+		}
+		return tt
+	}
+
+	//Delete keeps the rest in order
+	tt := build()
+	require.Nil(t, tt.Delete(b))
+	require.Nil(t, tt.Construct())
+	require.Equal(t, root(a, c, d), tt.Root())
+	proof, err := tt.ConstructProof(d)
+	require.Nil(t, err)
+	ok, err := tt.VerifyProof(d, *proof)
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.NotNil(t, tt.Delete(b))
+
+	//So does SafeDelete, it returns the same root as Delete and Construct
+	tt = build()
+	require.Nil(t, tt.SafeDelete(b))
+	require.Equal(t, root(a, c, d), tt.Root())
+	proof, err = tt.ConstructProof(c)
+	require.Nil(t, err)
+	ok, err = tt.VerifyProof(c, *proof)
+	require.Nil(t, err)
+	require.True(t, ok)
+}
+
+func TestTransactionTreeModes(t *testing.T) {
+	root := func(mode structures.TreeMode, leaves ...[32]byte) [32]byte {
+		tt := structures.NewTransactionTree(mode)
+		for _, leaf := range leaves {
+			require.Nil(t, tt.Add(leaf))
+		}
+		require.Nil(t, tt.Construct())
+		return tt.Root()
+	}
+	pair := func(left, right [32]byte) [32]byte { return sha3.Sum256(append(left[:], right[:]...)) }
+	a, b, c, d := sha3.Sum256([]byte("a")), sha3.Sum256([]byte("b")), sha3.Sum256([]byte("c")), sha3.Sum256([]byte("d"))
+
+	//A legacy tree can't tell interior nodes passed off as leaves, the padding of an odd level included, from the real tree
+	require.Equal(t, root(structures.LegacyTree, a, b, c), root(structures.LegacyTree, pair(a, b), pair(c, c)))
+	require.Equal(t, root(structures.LegacyTree, a, b, c, d), root(structures.LegacyTree, pair(a, b), pair(c, d)))
+	require.NotEqual(t, root(structures.SeparatedTree, a, b, c), root(structures.SeparatedTree, pair(a, b), pair(c, c)))
+	require.NotEqual(t, root(structures.SeparatedTree, a, b, c, d), root(structures.SeparatedTree, pair(a, b), pair(c, d)))
+	require.NotEqual(t, root(structures.LegacyTree, a, b, c), root(structures.SeparatedTree, a, b, c))
+
+	//Single proofs of every leaf, including the odd ones out
+	for _, mode := range []structures.TreeMode{structures.LegacyTree, structures.SeparatedTree} {
+		tt := structures.NewTransactionTree(mode)
+		leaves := make([][32]byte, 11)
+		for i := range leaves {
+			leaves[i] = sha3.Sum256([]byte{byte(i)})
+			require.Nil(t, tt.Add(leaves[i]))
+		}
+		require.Nil(t, tt.Construct())
+		for i := range leaves {
+			proof, err := tt.ConstructProof(leaves[i])
+			require.Nil(t, err)
+			ok, err := tt.VerifyProof(leaves[i], *proof)
+			require.Nil(t, err)
+			require.True(t, ok, "mode %d leaf %d", mode, i)
+			ok, err = tt.VerifyProof(leaves[(i+1)%len(leaves)], *proof)
+			require.Nil(t, err)
+			require.False(t, ok)
+			//A verifier holding nothing but the root needs the leaf's position and the number of leaves
+			require.True(t, structures.VerifyTreeProof(mode, tt.Root(), leaves[i], i, len(leaves), *proof))
+			require.False(t, structures.VerifyTreeProof(mode, tt.Root(), leaves[i], (i+1)%len(leaves), len(leaves), *proof))
+			require.False(t, structures.VerifyTreeProof(mode, tt.Root(), leaves[i], i, 2*len(leaves), *proof))
+		}
+	}
+}
+
+func BenchmarkTransactionTreeSafeAdd(b *testing.B) {
+	for _, size := range []int{10000, 100000} {
+		tt := structures.NewTransactionTree(structures.SeparatedTree)
+		leaves := make([][32]byte, size)
+		for i := range leaves {
+			leaves[i] = sha3.Sum256([]byte(fmt.Sprint(i)))
+			require.Nil(b, tt.Add(leaves[i]))
+		}
+		require.Nil(b, tt.Construct())
+		b.Run(fmt.Sprintf("incremental/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				leaf := sha3.Sum256([]byte(fmt.Sprint("new", i)))
+				require.Nil(b, tt.SafeAdd(leaf))
+				require.Nil(b, tt.SafeDelete(leaf))
+			}
+		})
+		//Deletes keep the order, so every leaf after the deleted one moves. The leaf is put back at the end, which
+		//keeps the tree the same size, and leaves follows the tree's order so ind stays the position deleted from
+		for _, at := range []struct {
+			name string
+			ind  int
+		}{{"first", 0}, {"middle", size / 2}} {
+			b.Run(fmt.Sprintf("delete-%s/%d", at.name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					leaf := leaves[at.ind]
+					require.Nil(b, tt.SafeDelete(leaf))
+					b.StopTimer()
+					require.Nil(b, tt.SafeAdd(leaf))
+					leaves = append(append(leaves[:at.ind], leaves[at.ind+1:]...), leaf)
+					b.StartTimer()
+				}
+			})
+		}
+		//What SafeAdd and SafeDelete cost when every change rebuilds the whole tree
+		b.Run(fmt.Sprintf("rebuild/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				leaf := sha3.Sum256([]byte(fmt.Sprint("new", i)))
+				require.Nil(b, tt.Add(leaf))
+				require.Nil(b, tt.Construct())
+				require.Nil(b, tt.Delete(leaf))
+				require.Nil(b, tt.Construct())
+			}
+		})
+	}
+}
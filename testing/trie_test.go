@@ -0,0 +1,87 @@
+package core_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/liangalv/goChain/core"
+	"github.com/liangalv/goChain/structures"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatriciaTrie(t *testing.T) {
+	//Keys that are prefixes of each other, share long runs, and the empty key
+	keys := [][]byte{{}, []byte("do"), []byte("dog"), []byte("doge"), []byte("horse"), {0x00}, {0x00, 0x01}, {0x10}}
+	for i := 0; i < 40; i++ {
+		keys = append(keys, []byte(fmt.Sprintf("account-%d", i)))
+	}
+	value := func(i int) []byte { return []byte(fmt.Sprintf("value-%d", i)) }
+
+	//The root only depends on the contents, not the order they were written in
+	a := structures.NewPatriciaTrie(structures.NewMemNodeStore(), [32]byte{})
+	b := structures.NewPatriciaTrie(core.NewMemBlockStore().Nodes(), [32]byte{})
+	for i := range keys {
+		require.Nil(t, a.Insert(keys[i], value(i)))
+	}
+	for _, i := range rand.New(rand.NewSource(1)).Perm(len(keys)) {
+		require.Nil(t, b.Insert(keys[i], []byte("overwritten")))
+		require.Nil(t, b.Insert(keys[i], value(i)))
+	}
+	require.Equal(t, a.Root(), b.Root())
+	for i := range keys {
+		got, err := b.Get(keys[i])
+		require.Nil(t, err)
+		require.Equal(t, value(i), got)
+	}
+	got, err := a.Get([]byte("dogs"))
+	require.Nil(t, err)
+	require.Nil(t, got)
+
+	//Inclusion and non inclusion proofs
+	root := a.Root()
+	for i := range keys {
+		proof, err := a.ConstructProof(keys[i])
+		require.Nil(t, err)
+		got, err := structures.VerifyPatriciaProof(root, keys[i], proof)
+		require.Nil(t, err)
+		require.Equal(t, value(i), got)
+	}
+	for _, absent := range [][]byte{[]byte("d"), []byte("dogs"), []byte("horses"), {0x00, 0x02}, {0xff}} {
+		proof, err := a.ConstructProof(absent)
+		require.Nil(t, err)
+		got, err := structures.VerifyPatriciaProof(root, absent, proof)
+		require.Nil(t, err)
+		require.Nil(t, got)
+	}
+	proof, err := a.ConstructProof([]byte("doge"))
+	require.Nil(t, err)
+	proof[len(proof)-1] = append([]byte{}, proof[len(proof)-1]...)
+	proof[len(proof)-1][len(proof[len(proof)-1])-1]++
+	_, err = structures.VerifyPatriciaProof(root, []byte("doge"), proof)
+	require.NotNil(t, err)
+	_, err = structures.VerifyPatriciaProof(root, []byte("doge"), proof[:1])
+	require.NotNil(t, err)
+
+	//Deletes leave the trie as if the keys were never there, and older roots stay readable
+	old := a.Copy()
+	fresh := structures.NewPatriciaTrie(structures.NewMemNodeStore(), [32]byte{})
+	for i := range keys {
+		if i%3 == 0 {
+			require.Nil(t, a.Delete(keys[i]))
+		} else {
+			require.Nil(t, fresh.Insert(keys[i], value(i)))
+		}
+	}
+	require.Equal(t, fresh.Root(), a.Root())
+	require.NotNil(t, a.Delete(keys[0]))
+	got, err = old.Get(keys[0])
+	require.Nil(t, err)
+	require.Equal(t, value(0), got)
+	for i := range keys {
+		if i%3 != 0 {
+			require.Nil(t, a.Delete(keys[i]))
+		}
+	}
+	require.Equal(t, [32]byte{}, a.Root())
+}